@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"log"
+	"server/internal/config"
+	"server/internal/repository/db/mongodb"
+	"server/pkg/sign"
+)
+
+// One-off migration, which namespaces wallets stored before chains were supported,
+// keeps primary wallets among wallets and merges users created for the same wallet written in different case.
+// Server refuses to start while users stored by older version remain. Running it again changes nothing.
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+
+	db, err := mongodb.Connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// wallets stored before chains were supported are Ethereum ones
+	cfg := config.Get()
+	err = mongodb.NewUserRepo(db).NamespaceWallets(ctx, sign.FormatAccount(sign.NamespaceEIP155, cfg.EVMAccountReference, ""))
+	if err != nil {
+		return err
+	}
+
+	merged, err := mongodb.MergeDuplicateWallets(ctx, db)
+	if err != nil {
+		return err
+	}
+	log.Printf("merged %d duplicate users", merged)
+
+	return nil
+}
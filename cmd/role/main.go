@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository/db"
+	"server/internal/service"
+	"server/pkg/nickname"
+)
+
+// Assigns role to user of wallet, e.g. to bootstrap the first admin
+func main() {
+	wallet := flag.String("wallet", "", "CAIP-10 account of user, e.g. eip155:1:0x...")
+	role := flag.String("role", string(domain.RoleAdmin), "role to assign")
+	flag.Parse()
+
+	if err := run(*wallet, *role); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(wallet, roleName string) error {
+	ctx := context.Background()
+
+	role, err := domain.ParseRole(roleName)
+	if err != nil {
+		return err
+	}
+	account, err := domain.NormalizeAccount(wallet)
+	if err != nil {
+		return err
+	}
+
+	repos, err := db.Open(ctx)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.Get()
+	nicknameGenerator := nickname.NewGenerator(nickname.Rules{
+		MinLength: cfg.NicknameMinLength,
+		MaxLength: cfg.NicknameMaxLength,
+		Profanity: append(append([]string{}, nickname.Profanity...), cfg.NicknameProfanity...),
+		Allowed:   nickname.Allowed,
+	})
+	tokenService := service.NewTokenService(repos.RefreshToken, repos.Revocation, repos.Session, cfg.RefreshTokenTTL)
+	adminService := service.NewAdminService(repos.User, repos.RoleChange, tokenService, nicknameGenerator)
+
+	user, err := adminService.GetUserByWallet(ctx, account)
+	if err != nil {
+		return err
+	}
+	user, err = adminService.SetRole(ctx, domain.SystemPrincipal(), user.ID, role)
+	if err != nil {
+		return err
+	}
+	log.Printf("user %s is %s", user.ID, user.Role)
+
+	return nil
+}
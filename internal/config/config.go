@@ -3,23 +3,87 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log"
+	"net/url"
 	"os"
 	"regexp"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/pkg/keyenc"
+	"server/pkg/sign"
 	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
 )
 
 type Config struct {
-	LogLevel  string `envconfig:"LOG_LEVEL"`
-	MongoURL  string `envconfig:"MONGODB_URL"`
-	MongoDB   string `envconfig:"MONGODB_DATABASE"`
-	HTTPAddr  string `envconfig:"HTTP_ADDR"`
-	JWTSecret string `envconfig:"JWT_SECRET"`
+	LogLevel string `envconfig:"LOG_LEVEL"`
+	// Storage is storage backend: mongodb, postgres, sqlite or memory, which keeps nothing after restart
+	Storage string `envconfig:"STORAGE" default:"mongodb"`
+	// UserStorage moves users to another backend: postgres or sqlite, empty keeps them in Storage
+	UserStorage string `envconfig:"USER_STORAGE"`
+	MongoURL    string `envconfig:"MONGODB_URL"`
+	MongoDB     string `envconfig:"MONGODB_DATABASE"`
+	PostgresURL string `envconfig:"POSTGRES_URL"`
+	// SQLitePath is database file, created when missing
+	SQLitePath string `envconfig:"SQLITE_PATH" default:"gameserver.db"`
+	HTTPAddr   string `envconfig:"HTTP_ADDR"`
+
+	JWTAlgorithm   string        `envconfig:"JWT_ALGORITHM" default:"EdDSA"`
+	JWTKeyRotation time.Duration `envconfig:"JWT_KEY_ROTATION" default:"168h"`
+	// JWTKeyEncryptionKey is base64 AES-256 key signing keys are encrypted with in storage,
+	// required unless storage is memory. Keys encrypted with another key can't be read.
+	JWTKeyEncryptionKey string `envconfig:"JWT_KEY_ENCRYPTION_KEY" json:"-"`
+
+	AccessTokenTTL  time.Duration `envconfig:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `envconfig:"REFRESH_TOKEN_TTL" default:"720h"`
+
+	NonceTTL time.Duration `envconfig:"NONCE_TTL" default:"5m"`
+
+	// SiweDomain and SiweURI are domain and origin of game client, sign-in messages must have them.
+	// Both are required, server doesn't start without them.
+	SiweDomain  string `envconfig:"SIWE_DOMAIN"`
+	SiweURI     string `envconfig:"SIWE_URI"`
+	SiweChainID string `envconfig:"SIWE_CHAIN_ID" default:"1"`
+	// EVMAccountReference is CAIP-2 chain reference EVM accounts are stored under, whatever chain
+	// they sign in on, since address is the same account on every EVM chain. Changing it makes every
+	// EVM user a new account, so it must stay the same once there are users.
+	EVMAccountReference string `envconfig:"EVM_ACCOUNT_REFERENCE" default:"1"`
+
+	SolanaChainID string `envconfig:"SOLANA_CHAIN_ID" default:"mainnet"`
+
+	// SignMode is how EVM login messages are hashed: personal for personal_sign or raw
+	SignMode string `envconfig:"SIGN_MODE" default:"personal"`
+
+	// EIP712Name and EIP712Contract enable EIP-712 typed-data login, setting one of them requires the other
+	EIP712Name     string `envconfig:"EIP712_NAME"`
+	EIP712Version  string `envconfig:"EIP712_VERSION" default:"1"`
+	EIP712ChainID  int64  `envconfig:"EIP712_CHAIN_ID" default:"1"`
+	EIP712Contract string `envconfig:"EIP712_CONTRACT"`
+
+	// EthRPCURL enables EIP-1271 smart-contract wallet signatures
+	EthRPCURL string `envconfig:"ETH_RPC_URL"`
+
+	NicknameMinLength int           `envconfig:"NICKNAME_MIN_LENGTH" default:"3"`
+	NicknameMaxLength int           `envconfig:"NICKNAME_MAX_LENGTH" default:"20"`
+	NicknameCooldown  time.Duration `envconfig:"NICKNAME_COOLDOWN" default:"720h"`
+	// NicknameProfanity extends built-in list of words nicknames must not contain
+	NicknameProfanity []string `envconfig:"NICKNAME_PROFANITY"`
+
+	BioMaxLength   int `envconfig:"BIO_MAX_LENGTH" default:"160"`
+	SearchPageSize int `envconfig:"SEARCH_PAGE_SIZE" default:"20"`
+
+	DeletionGracePeriod time.Duration `envconfig:"DELETION_GRACE_PERIOD" default:"720h"`
+	PurgeInterval       time.Duration `envconfig:"PURGE_INTERVAL" default:"1h"`
 }
 
+var configErrorPrefix = "[config]"
+
 var (
 	config Config
 	once   sync.Once
@@ -27,6 +91,9 @@ var (
 
 const projectDirName = "gameserver"
 
+// evmChainReference is CAIP-2 reference of eip155 namespace, decimal chain id
+var evmChainReference = regexp.MustCompile(`^[0-9]{1,32}$`)
+
 // Get reads config from environment. Once.
 func Get() *Config {
 	once.Do(func() {
@@ -35,7 +102,8 @@ func Get() *Config {
 		rootPath := projectName.Find([]byte(currentWorkDirectory))
 
 		err := godotenv.Load(string(rootPath) + `/.env`) // load .env file
-		if err != nil {
+		// environment alone is enough, e.g. in CI
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
 			log.Fatal(err)
 		}
 		err = envconfig.Process("", &config)
@@ -50,3 +118,52 @@ func Get() *Config {
 	})
 	return &config
 }
+
+// Validate checks settings server can't work without, so misconfigured server fails on start
+// rather than refusing every login
+func (c *Config) Validate() error {
+	if c.SiweDomain == "" {
+		return errors.Wrapf(domain.ErrConfig, "%s: SIWE_DOMAIN is required", configErrorPrefix)
+	}
+	uri, err := url.Parse(c.SiweURI)
+	if err != nil || uri.Scheme == "" || uri.Host == "" {
+		return errors.Wrapf(domain.ErrConfig, "%s: SIWE_URI %q must be absolute url, e.g. https://%s", configErrorPrefix, c.SiweURI, c.SiweDomain)
+	}
+	if !evmChainReference.MatchString(c.EVMAccountReference) {
+		return errors.Wrapf(domain.ErrConfig, "%s: EVM_ACCOUNT_REFERENCE %q must be EVM chain id", configErrorPrefix, c.EVMAccountReference)
+	}
+	switch sign.Mode(c.SignMode) {
+	case sign.ModePersonal, sign.ModeRaw:
+	default:
+		return errors.Wrapf(domain.ErrConfig, "%s: SIGN_MODE %q must be %s or %s", configErrorPrefix, c.SignMode, sign.ModePersonal, sign.ModeRaw)
+	}
+	if c.typedDataEnabled() {
+		if c.EIP712Name == "" || c.EIP712Version == "" {
+			return errors.Wrapf(domain.ErrConfig, "%s: EIP712_NAME and EIP712_VERSION are required for typed-data login", configErrorPrefix)
+		}
+		if !common.IsHexAddress(c.EIP712Contract) || common.HexToAddress(c.EIP712Contract) == (common.Address{}) {
+			return errors.Wrapf(domain.ErrConfig, "%s: EIP712_CONTRACT %q must be contract address for typed-data login", configErrorPrefix, c.EIP712Contract)
+		}
+	}
+	if c.JWTKeyRotation <= domain.KeyPublishLead {
+		return errors.Wrapf(domain.ErrConfig, "%s: JWT_KEY_ROTATION %s must be longer than %s new key is published before signing", configErrorPrefix, c.JWTKeyRotation, domain.KeyPublishLead)
+	}
+	if c.JWTKeyEncryptionKey != "" || c.Storage != repository.StorageMemory {
+		_, err = keyenc.ParseKey(c.JWTKeyEncryptionKey)
+		if err != nil {
+			return errors.Wrapf(domain.ErrConfig, "%s: JWT_KEY_ENCRYPTION_KEY must be base64 of %d random bytes to encrypt signing keys in storage", configErrorPrefix, keyenc.KeySize)
+		}
+	}
+	if c.EthRPCURL != "" {
+		rpcURL, err := url.Parse(c.EthRPCURL)
+		if err != nil || rpcURL.Scheme == "" || rpcURL.Host == "" {
+			return errors.Wrapf(domain.ErrConfig, "%s: ETH_RPC_URL %q must be absolute url for contract signing", configErrorPrefix, c.EthRPCURL)
+		}
+	}
+	return nil
+}
+
+// typedDataEnabled checks EIP-712 typed-data login is configured, login with typed data is refused otherwise
+func (c *Config) typedDataEnabled() bool {
+	return c.EIP712Name != "" || c.EIP712Contract != ""
+}
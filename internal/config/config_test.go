@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/base64"
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(*Config)
+		valid  bool
+	}{
+		{name: "valid", modify: func(c *Config) {}, valid: true},
+		{name: "no domain", modify: func(c *Config) { c.SiweDomain = "" }},
+		{name: "no uri", modify: func(c *Config) { c.SiweURI = "" }},
+		{name: "relative uri", modify: func(c *Config) { c.SiweURI = "game.example.com" }},
+		{name: "no evm account reference", modify: func(c *Config) { c.EVMAccountReference = "" }},
+		{name: "evm account reference is not chain id", modify: func(c *Config) { c.EVMAccountReference = "mainnet" }},
+		{name: "raw sign mode", modify: func(c *Config) { c.SignMode = "raw" }, valid: true},
+		{name: "unknown sign mode", modify: func(c *Config) { c.SignMode = "eip712" }},
+		{name: "no sign mode", modify: func(c *Config) { c.SignMode = "" }},
+		{name: "typed data", modify: func(c *Config) {
+			c.EIP712Name = "Game"
+			c.EIP712Contract = "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+		}, valid: true},
+		{name: "typed data without name", modify: func(c *Config) {
+			c.EIP712Contract = "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+		}},
+		{name: "typed data without contract", modify: func(c *Config) { c.EIP712Name = "Game" }},
+		{name: "typed data with zero contract", modify: func(c *Config) {
+			c.EIP712Name = "Game"
+			c.EIP712Contract = "0x0000000000000000000000000000000000000000"
+		}},
+		{name: "contract signing", modify: func(c *Config) { c.EthRPCURL = "https://rpc.example.com" }, valid: true},
+		{name: "contract signing with relative rpc url", modify: func(c *Config) { c.EthRPCURL = "rpc.example.com" }},
+		{name: "no key encryption key", modify: func(c *Config) { c.JWTKeyEncryptionKey = "" }},
+		{name: "short key encryption key", modify: func(c *Config) { c.JWTKeyEncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 16)) }},
+		{name: "no key encryption key in memory", modify: func(c *Config) {
+			c.Storage = "memory"
+			c.JWTKeyEncryptionKey = ""
+		}, valid: true},
+		{name: "key rotation as long as publish lead", modify: func(c *Config) { c.JWTKeyRotation = domain.KeyPublishLead }},
+		{name: "no key rotation", modify: func(c *Config) { c.JWTKeyRotation = 0 }},
+		{name: "short key rotation", modify: func(c *Config) { c.JWTKeyRotation = domain.KeyPublishLead + time.Minute }, valid: true},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		cfg := &Config{
+			SiweDomain:          "game.example.com",
+			SiweURI:             "https://game.example.com",
+			EVMAccountReference: "1",
+			SignMode:            "personal",
+			EIP712Version:       "1",
+			JWTKeyRotation:      168 * time.Hour,
+			JWTKeyEncryptionKey: base64.StdEncoding.EncodeToString(make([]byte, 32)),
+		}
+		test.modify(cfg)
+		err := cfg.Validate()
+		if test.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, domain.ErrConfig)
+		}
+	}
+}
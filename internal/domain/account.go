@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// UserExport is everything stored about user, player downloads it as personal data archive
+type UserExport struct {
+	User     *User
+	Sessions []*Session
+	// RefreshTokens are issued to sessions of user, including rotated and revoked ones
+	RefreshTokens []*RefreshToken
+	RoleChanges   []*RoleChange
+	// Sanctions are issued to user and to its wallets, authors are StaffActor
+	Sanctions []*Sanction
+	// APIKeys are keys user created as admin
+	APIKeys    []*APIKey
+	ExportedAt time.Time
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"server/pkg/sign"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+)
+
+// Address is wallet address in canonical form of its chain:
+// EIP-55 checksummed hex for Ethereum and base58 for Solana
+type Address string
+
+// NewAddress validates wallet address of CAIP-2 namespace, Ethereum when empty, and returns its canonical form.
+// Mixed case Ethereum address must have valid checksum, lower and upper case ones are checksummed.
+func NewAddress(namespace, address string) (Address, error) {
+	switch namespace {
+	case "", sign.NamespaceEIP155:
+		hex, ok := strings.CutPrefix(address, "0x")
+		if !ok || !common.IsHexAddress(address) {
+			return "", errors.Wrapf(ErrAddress, "ethereum address %q invalid", address)
+		}
+		checksummed := common.HexToAddress(address).Hex()
+		if hex != strings.ToLower(hex) && hex != strings.ToUpper(hex) && address != checksummed {
+			return "", errors.Wrapf(ErrAddress, "ethereum address %q checksum invalid", address)
+		}
+		return Address(checksummed), nil
+	case sign.NamespaceSolana:
+		publicKey, err := base58.Decode(address)
+		if err != nil || len(publicKey) != 32 {
+			return "", errors.Wrapf(ErrAddress, "solana address %q invalid", address)
+		}
+		return Address(base58.Encode(publicKey)), nil
+	default:
+		return "", errors.Wrapf(ErrAddress, "chain %q not supported", namespace)
+	}
+}
+
+// NormalizeAccount returns CAIP-10 account with address in canonical form
+func NormalizeAccount(account string) (string, error) {
+	parts := strings.SplitN(account, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return "", errors.Wrapf(ErrAddress, "account %q invalid", account)
+	}
+	address, err := NewAddress(parts[0], parts[2])
+	if err != nil {
+		return "", err
+	}
+	return sign.FormatAccount(parts[0], parts[1], string(address)), nil
+}
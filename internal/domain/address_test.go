@@ -0,0 +1,49 @@
+package domain_test
+
+import (
+	"server/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAddress(t *testing.T) {
+	testCases := []struct {
+		name      string
+		namespace string
+		address   string
+		expected  domain.Address
+	}{
+		{name: "checksummed", namespace: "eip155", address: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935", expected: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"},
+		{name: "default namespace", address: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935", expected: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"},
+		{name: "lower case", namespace: "eip155", address: "0xef209bee800ef5c7d20a67f46e007a970eaf9935", expected: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"},
+		{name: "upper case", namespace: "eip155", address: "0xEF209BEE800EF5C7D20A67F46E007A970EAF9935", expected: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"},
+		{name: "wrong checksum", namespace: "eip155", address: "0xEf209Bee800Ef5c7d20A67F46E007a970EAf9935"},
+		{name: "no prefix", namespace: "eip155", address: "ef209bee800ef5c7d20a67f46e007a970eaf9935"},
+		{name: "short", namespace: "eip155", address: "0xef209bee800ef5c7d20a67f46e007a970eaf99"},
+		{name: "solana", namespace: "solana", address: "5e6wK3rAqTgYg2TSBbXJTTvpvgjb6ZD3aTuDiuktW1eq", expected: "5e6wK3rAqTgYg2TSBbXJTTvpvgjb6ZD3aTuDiuktW1eq"},
+		{name: "solana invalid", namespace: "solana", address: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"},
+		{name: "unknown chain", namespace: "cosmos", address: "cosmos1"},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		address, err := domain.NewAddress(test.namespace, test.address)
+		if test.expected == "" {
+			assert.ErrorIs(t, err, domain.ErrAddress)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, address)
+		}
+	}
+}
+
+func TestNormalizeAccount(t *testing.T) {
+	account, err := domain.NormalizeAccount("eip155:1:0xef209bee800ef5c7d20a67f46e007a970eaf9935")
+	assert.NoError(t, err)
+	assert.Equal(t, "eip155:1:0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935", account)
+
+	_, err = domain.NormalizeAccount("0xef209bee800ef5c7d20a67f46e007a970eaf9935")
+	assert.ErrorIs(t, err, domain.ErrAddress)
+}
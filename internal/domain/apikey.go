@@ -0,0 +1,86 @@
+package domain
+
+import "time"
+
+// APIKey authenticates game servers, bots and other services calling API without wallet
+type APIKey struct {
+	ID   string
+	Name string
+	// Hash is SHA-256 of key, key itself is shown only once on creation
+	Hash string
+	// Prefix is beginning of key shown in key list so it can be recognized
+	Prefix string
+	Scopes []Permission
+	// CreatedBy is id of admin who created key
+	CreatedBy  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+}
+
+// APIKeyReq is API key admin creates
+type APIKeyReq struct {
+	Name   string
+	Scopes []Permission
+}
+
+// PrincipalKind tells what authenticated request
+type PrincipalKind string
+
+const (
+	PrincipalUser   PrincipalKind = "user"
+	PrincipalAPIKey PrincipalKind = "api_key"
+	PrincipalSystem PrincipalKind = "system"
+)
+
+// Principal is authenticated caller of API, either player with access token or service with API key
+type Principal struct {
+	Kind PrincipalKind
+	// ID is user id or API key id
+	ID string
+	// Role is role of user, empty for API key
+	Role Role
+	// Scopes are permissions of API key, empty for user
+	Scopes []Permission
+}
+
+// SystemPrincipal is principal of server tools run by operators, it may do anything
+func SystemPrincipal() *Principal {
+	return &Principal{Kind: PrincipalSystem, ID: SystemActor}
+}
+
+// Can checks principal has permission
+func (p *Principal) Can(permission Permission) bool {
+	if p.Kind == PrincipalSystem {
+		return true
+	}
+	if p.Kind == PrincipalAPIKey {
+		for _, scope := range p.Scopes {
+			if scope == permission {
+				return true
+			}
+		}
+		return false
+	}
+	return p.Role.Can(permission)
+}
+
+// Outranks checks principal may manage account of role: any account but staff one,
+// staff one only when principal is staff of higher role. API keys never manage staff, server tools manage anyone.
+func (p *Principal) Outranks(role Role) bool {
+	if !role.IsStaff() || p.Kind == PrincipalSystem {
+		return true
+	}
+	if p.Kind == PrincipalAPIKey {
+		return false
+	}
+	return staffRank[p.Role] > staffRank[role]
+}
+
+// Actor is recorded as author of changes principal makes
+func (p *Principal) Actor() string {
+	if p.Kind == PrincipalAPIKey {
+		return string(PrincipalAPIKey) + ":" + p.ID
+	}
+	return p.ID
+}
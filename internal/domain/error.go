@@ -3,9 +3,26 @@ package domain
 import "github.com/pkg/errors"
 
 var (
-	ErrNotFound    = errors.New("not found")
-	ErrConversion  = errors.New("conversion error")
-	ErrNoDocuments = errors.New("no documents")
-	ErrConfig      = errors.New("config error")
-	ErrSignature   = errors.New("signature error")
+	ErrNotFound      = errors.New("not found")
+	ErrConversion    = errors.New("conversion error")
+	ErrNoDocuments   = errors.New("no documents")
+	ErrConfig        = errors.New("config error")
+	ErrSignature     = errors.New("signature error")
+	ErrNonce         = errors.New("nonce error")
+	ErrToken         = errors.New("token error")
+	ErrWallet        = errors.New("wallet error")
+	ErrAddress       = errors.New("address error")
+	ErrNickname      = errors.New("nickname error")
+	ErrNicknameTaken = errors.New("nickname taken")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrProfile       = errors.New("profile error")
+	ErrCursor        = errors.New("cursor error")
+	ErrRole          = errors.New("role error")
+	ErrSanction      = errors.New("sanction error")
+	ErrBanned        = errors.New("banned")
+	ErrAPIKey        = errors.New("api key error")
+	ErrGuest         = errors.New("guest error")
+	ErrForbidden     = errors.New("forbidden")
+	// ErrDeleted is returned for account which purge started, its deletion can't be cancelled
+	ErrDeleted = errors.New("account deleted")
 )
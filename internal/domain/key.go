@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"crypto"
+	"time"
+)
+
+// KeyPublishLead is how long new signing key is published before it signs tokens, so other server instances
+// reload it and clients refresh cached JWKS in time. JWKS must be cached for less than lead minus reload interval,
+// key rotation period must be longer than lead.
+const KeyPublishLead = 5 * time.Minute
+
+// SigningKey is asymmetric key access tokens are signed with, ID is JWT kid
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
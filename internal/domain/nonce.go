@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"time"
+)
+
+type Nonce struct {
+	Value     string
+	Wallet    Address
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// nftPattern is CAIP-19 asset id of non-fungible token, e.g. eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769
+var nftPattern = regexp.MustCompile(`^[-a-z0-9]{3,8}:[-_a-zA-Z0-9]{1,32}/[-a-z0-9]{3,8}:[-.%a-zA-Z0-9]{1,128}/[-.%a-zA-Z0-9]{1,78}$`)
+
+// countryPattern is ISO 3166-1 alpha-2 country code
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Avatar is picture player shows in profile, either image URL or NFT player owns
+type Avatar struct {
+	URL string
+	// NFT is CAIP-19 asset id of token
+	NFT string
+}
+
+// Validate checks avatar has at most one source and it's well-formed, empty avatar is valid
+func (a Avatar) Validate() error {
+	if a.URL != "" && a.NFT != "" {
+		return errors.Wrap(ErrProfile, "avatar must be either url or nft")
+	}
+	if a.URL != "" {
+		u, err := url.Parse(a.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return errors.Wrapf(ErrProfile, "avatar url %q must be https url", a.URL)
+		}
+	}
+	if a.NFT != "" && !nftPattern.MatchString(a.NFT) {
+		return errors.Wrapf(ErrProfile, "avatar nft %q must be CAIP-19 asset id", a.NFT)
+	}
+	return nil
+}
+
+// NormalizeCountry returns upper case ISO 3166-1 alpha-2 country code, empty country is left empty
+func NormalizeCountry(country string) (string, error) {
+	if country == "" {
+		return "", nil
+	}
+	country = strings.ToUpper(country)
+	if !countryPattern.MatchString(country) {
+		return "", errors.Wrapf(ErrProfile, "country %q must be ISO 3166-1 alpha-2 code", country)
+	}
+	return country, nil
+}
+
+// Privacy are settings of what other players see in profile, zero value shows profile except wallets
+type Privacy struct {
+	// Private profile shows nickname and avatar only
+	Private     bool
+	HideCountry bool
+	// ShowWallet shows primary wallet, linked wallets are never shown
+	ShowWallet bool
+}
+
+// Profile is public view of user other players see
+type Profile struct {
+	ID        string
+	Nickname  string
+	Avatar    Avatar
+	Bio       string
+	Country   string
+	Wallet    string
+	CreatedAt time.Time
+}
+
+// Profile returns public view of user with fields hidden by privacy settings left empty
+func (u *User) Profile() *Profile {
+	profile := &Profile{
+		ID:       u.ID,
+		Nickname: u.Nickname,
+		Avatar:   u.Avatar,
+	}
+	if u.Privacy.Private {
+		return profile
+	}
+
+	profile.Bio = u.Bio
+	profile.CreatedAt = u.CreatedAt
+	if !u.Privacy.HideCountry {
+		profile.Country = u.Country
+	}
+	if u.Privacy.ShowWallet {
+		profile.Wallet = u.Wallet
+	}
+	return profile
+}
+
+// UserSearchReq finds players by nickname prefix or wallet
+type UserSearchReq struct {
+	Query string
+	// Cursor is NextCursor of previous page, first page is returned when empty
+	Cursor string
+}
+
+// ProfilePage is page of profiles, NextCursor is empty on the last page
+type ProfilePage struct {
+	Profiles   []*Profile
+	NextCursor string
+}
@@ -0,0 +1,92 @@
+package domain_test
+
+import (
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_Profile(t *testing.T) {
+	createdAt := time.Now()
+	user := func(privacy domain.Privacy) *domain.User {
+		return &domain.User{
+			ID:        "user",
+			Nickname:  "BraveOtter42",
+			Wallet:    "eip155:1:0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935",
+			Wallets:   []string{"eip155:1:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d"},
+			CreatedAt: createdAt,
+			Avatar:    domain.Avatar{URL: "https://example.com/avatar.png"},
+			Bio:       "gg",
+			Country:   "DE",
+			Privacy:   privacy,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		privacy  domain.Privacy
+		expected *domain.Profile
+	}{
+		{
+			name:    "default",
+			privacy: domain.Privacy{},
+			expected: &domain.Profile{
+				ID: "user", Nickname: "BraveOtter42", Avatar: domain.Avatar{URL: "https://example.com/avatar.png"},
+				Bio: "gg", Country: "DE", CreatedAt: createdAt,
+			},
+		},
+		{
+			name:    "hidden country, shown wallet",
+			privacy: domain.Privacy{HideCountry: true, ShowWallet: true},
+			expected: &domain.Profile{
+				ID: "user", Nickname: "BraveOtter42", Avatar: domain.Avatar{URL: "https://example.com/avatar.png"},
+				Bio: "gg", Wallet: "eip155:1:0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935", CreatedAt: createdAt,
+			},
+		},
+		{
+			name:    "private",
+			privacy: domain.Privacy{Private: true, ShowWallet: true},
+			expected: &domain.Profile{
+				ID: "user", Nickname: "BraveOtter42", Avatar: domain.Avatar{URL: "https://example.com/avatar.png"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		assert.Equal(t, test.expected, user(test.privacy).Profile())
+	}
+}
+
+func TestAvatar_Validate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		avatar domain.Avatar
+		valid  bool
+	}{
+		{name: "empty", avatar: domain.Avatar{}, valid: true},
+		{name: "url", avatar: domain.Avatar{URL: "https://example.com/avatar.png"}, valid: true},
+		{name: "nft", avatar: domain.Avatar{NFT: "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769"}, valid: true},
+		{name: "http url", avatar: domain.Avatar{URL: "http://example.com/avatar.png"}},
+		{name: "relative url", avatar: domain.Avatar{URL: "/avatar.png"}},
+		{name: "nft without token id", avatar: domain.Avatar{NFT: "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d"}},
+		{name: "url and nft", avatar: domain.Avatar{
+			URL: "https://example.com/avatar.png",
+			NFT: "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769",
+		}},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		err := test.avatar.Validate()
+		if test.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, domain.ErrProfile)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Role defines what user is allowed to do
+type Role string
+
+const (
+	RolePlayer    Role = "player"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+	// RoleService is role of game servers and other backend services acting on behalf of players
+	RoleService Role = "service"
+	// RoleGuest is role of device-bound account without wallet, guest becomes player by signing in with wallet
+	RoleGuest Role = "guest"
+)
+
+// Permission is action on API guarded by role
+type Permission string
+
+const (
+	// PermissionProfileWrite allows changing own profile and wallets
+	PermissionProfileWrite Permission = "profile:write"
+	// PermissionUsersRead allows looking up any user with private fields
+	PermissionUsersRead Permission = "users:read"
+	// PermissionUsersManage allows account actions like logout and profile reset
+	PermissionUsersManage Permission = "users:manage"
+	// PermissionRolesManage allows assigning roles
+	PermissionRolesManage Permission = "roles:manage"
+	// PermissionSanctionsManage allows issuing and lifting sanctions
+	PermissionSanctionsManage Permission = "sanctions:manage"
+	// PermissionAPIKeysManage allows creating and revoking API keys
+	PermissionAPIKeysManage Permission = "api_keys:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleGuest:     {},
+	RolePlayer:    {PermissionProfileWrite},
+	RoleService:   {PermissionProfileWrite, PermissionUsersRead},
+	RoleModerator: {PermissionProfileWrite, PermissionUsersRead, PermissionUsersManage, PermissionSanctionsManage},
+	RoleAdmin: {
+		PermissionProfileWrite, PermissionUsersRead, PermissionUsersManage, PermissionSanctionsManage, PermissionRolesManage,
+		PermissionAPIKeysManage,
+	},
+}
+
+// staffRank orders staff roles, other roles aren't staff
+var staffRank = map[Role]int{
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// apiKeyPermissions are permissions API key can be scoped to, staff management is left to people
+var apiKeyPermissions = []Permission{PermissionUsersRead, PermissionUsersManage, PermissionSanctionsManage}
+
+// SystemActor is recorded as author of changes made by server tools rather than by user
+const SystemActor = "system"
+
+// DeletedActor replaces author of changes made by user whose account was deleted
+const DeletedActor = "deleted"
+
+// StaffActor replaces author of changes made by staff in data exported to player
+const StaffActor = "staff"
+
+// ParseRole validates role name
+func ParseRole(role string) (Role, error) {
+	if _, ok := rolePermissions[Role(role)]; !ok {
+		return "", errors.Wrapf(ErrRole, "role %q unknown", role)
+	}
+	return Role(role), nil
+}
+
+// ParseAPIKeyScope validates permission API key is scoped to
+func ParseAPIKeyScope(scope string) (Permission, error) {
+	for _, permission := range apiKeyPermissions {
+		if permission == Permission(scope) {
+			return permission, nil
+		}
+	}
+	return "", errors.Wrapf(ErrAPIKey, "scope %q not allowed for api key", scope)
+}
+
+// Can checks role has permission, empty role is player one
+func (r Role) Can(permission Permission) bool {
+	if r == "" {
+		r = RolePlayer
+	}
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff checks role moderates other users
+func (r Role) IsStaff() bool {
+	return staffRank[r] > 0
+}
+
+// RoleChange is audit record of role assignment
+type RoleChange struct {
+	ID           string
+	UserID       string
+	Role         Role
+	PreviousRole Role
+	// ChangedBy is id of user who assigned role or SystemActor
+	ChangedBy string
+	CreatedAt time.Time
+}
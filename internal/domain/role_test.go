@@ -0,0 +1,75 @@
+package domain_test
+
+import (
+	"server/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole_Can(t *testing.T) {
+	assert.False(t, domain.Role("").Can(domain.PermissionUsersRead))
+	assert.False(t, domain.RolePlayer.Can(domain.PermissionUsersRead))
+	assert.True(t, domain.RoleService.Can(domain.PermissionUsersRead))
+	assert.False(t, domain.RoleService.Can(domain.PermissionUsersManage))
+	assert.True(t, domain.RoleModerator.Can(domain.PermissionUsersManage))
+	assert.False(t, domain.RoleModerator.Can(domain.PermissionRolesManage))
+	assert.True(t, domain.RoleAdmin.Can(domain.PermissionRolesManage))
+	assert.False(t, domain.Role("root").Can(domain.PermissionUsersRead))
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := domain.ParseRole("moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleModerator, role)
+
+	_, err = domain.ParseRole("root")
+	assert.ErrorIs(t, err, domain.ErrRole)
+}
+
+func TestParseAPIKeyScope(t *testing.T) {
+	scope, err := domain.ParseAPIKeyScope("users:read")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PermissionUsersRead, scope)
+
+	_, err = domain.ParseAPIKeyScope("roles:manage")
+	assert.ErrorIs(t, err, domain.ErrAPIKey)
+}
+
+func TestPrincipal_Can(t *testing.T) {
+	apiKey := &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "key", Role: domain.RoleAdmin, Scopes: []domain.Permission{domain.PermissionUsersRead}}
+	assert.True(t, apiKey.Can(domain.PermissionUsersRead))
+	// role is ignored for API keys
+	assert.False(t, apiKey.Can(domain.PermissionUsersManage))
+	assert.Equal(t, "api_key:key", apiKey.Actor())
+
+	user := &domain.Principal{Kind: domain.PrincipalUser, ID: "user", Role: domain.RoleModerator}
+	assert.True(t, user.Can(domain.PermissionUsersManage))
+	assert.Equal(t, "user", user.Actor())
+}
+
+func TestRole_Guest(t *testing.T) {
+	assert.False(t, domain.RoleGuest.Can(domain.PermissionProfileWrite))
+	assert.True(t, domain.RolePlayer.Can(domain.PermissionProfileWrite))
+	// tokens issued before roles were introduced belong to players
+	assert.True(t, domain.Role("").Can(domain.PermissionProfileWrite))
+}
+
+func TestPrincipal_Outranks(t *testing.T) {
+	admin := &domain.Principal{Kind: domain.PrincipalUser, ID: "admin", Role: domain.RoleAdmin}
+	assert.True(t, admin.Outranks(domain.RolePlayer))
+	assert.True(t, admin.Outranks(domain.RoleModerator))
+	assert.False(t, admin.Outranks(domain.RoleAdmin))
+
+	moderator := &domain.Principal{Kind: domain.PrincipalUser, ID: "moderator", Role: domain.RoleModerator}
+	assert.True(t, moderator.Outranks(domain.RoleGuest))
+	assert.True(t, moderator.Outranks(domain.RoleService))
+	assert.False(t, moderator.Outranks(domain.RoleModerator))
+	assert.False(t, moderator.Outranks(domain.RoleAdmin))
+
+	apiKey := &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "key", Scopes: []domain.Permission{domain.PermissionSanctionsManage}}
+	assert.True(t, apiKey.Outranks(domain.RolePlayer))
+	assert.False(t, apiKey.Outranks(domain.RoleModerator))
+
+	assert.True(t, domain.SystemPrincipal().Outranks(domain.RoleAdmin))
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// SanctionType is kind of moderation measure
+type SanctionType string
+
+const (
+	// SanctionBan blocks login permanently
+	SanctionBan SanctionType = "ban"
+	// SanctionSuspension blocks login until it expires
+	SanctionSuspension SanctionType = "suspension"
+	// SanctionMute blocks chat until it expires, chat servers check it
+	SanctionMute SanctionType = "mute"
+)
+
+// Sanction is moderation measure taken against user
+type Sanction struct {
+	ID     string
+	UserID string
+	Type   SanctionType
+	Reason string
+	// IssuedBy is id of moderator who issued sanction
+	IssuedBy  string
+	CreatedAt time.Time
+	// ExpiresAt is zero for permanent ban
+	ExpiresAt time.Time
+	// LiftedBy is id of moderator who lifted sanction before it expired
+	LiftedBy string
+	LiftedAt time.Time
+}
+
+// Active checks sanction is neither lifted nor expired
+func (s *Sanction) Active(now time.Time) bool {
+	return s.LiftedAt.IsZero() && (s.ExpiresAt.IsZero() || now.Before(s.ExpiresAt))
+}
+
+// WalletSubject is user id of sanctions kept for wallet of deleted account,
+// so wallet can't sign up again until they expire or are lifted
+func WalletSubject(wallet string) string {
+	return "wallet:" + wallet
+}
+
+// BlocksLogin checks sanction keeps user from logging in and using issued tokens
+func (s *Sanction) BlocksLogin() bool {
+	return s.Type == SanctionBan || s.Type == SanctionSuspension
+}
+
+// SanctionReq is sanction moderator issues, Duration is zero for permanent ban
+type SanctionReq struct {
+	Type     SanctionType
+	Reason   string
+	Duration time.Duration
+}
+
+// SanctionError is returned when active sanction blocks user, it matches ErrBanned
+type SanctionError struct {
+	Sanction *Sanction
+}
+
+func (e *SanctionError) Error() string {
+	if e.Sanction.ExpiresAt.IsZero() {
+		return fmt.Sprintf("account banned: %s", e.Sanction.Reason)
+	}
+	return fmt.Sprintf("account suspended until %s: %s", e.Sanction.ExpiresAt.Format(time.RFC3339), e.Sanction.Reason)
+}
+
+func (e *SanctionError) Unwrap() error {
+	return ErrBanned
+}
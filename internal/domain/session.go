@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+)
+
+// Session is device login, ID matches refresh token family
+type Session struct {
+	ID         string
+	UserID     string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+}
+
+// Device describes client tokens are issued to
+type Device struct {
+	UserAgent string
+	IP        string
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+)
+
+// RefreshToken is persisted refresh token, only hash of token value is stored.
+// Tokens issued by one login and its rotations share Family.
+type RefreshToken struct {
+	Hash      string
+	Family    string
+	UserID    string
+	Wallet    string
+	Used      bool
+	Revoked   bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// AccessToken holds claims of issued access token needed to revoke it
+type AccessToken struct {
+	ID     string
+	UserID string
+	// SessionID is session token was issued to, token is revoked once session is deleted
+	SessionID string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
@@ -5,14 +5,75 @@ import (
 )
 
 type User struct {
-	ID        string
-	Nickname  string
-	Wallet    string
+	ID       string
+	Nickname string
+	// Wallet is CAIP-10 account of primary wallet user signed up with
+	Wallet string
+	// Wallets are CAIP-10 accounts of additional wallets linked to user, any of them logs in to the same user
+	Wallets   []string
 	CreatedAt time.Time
+	Role      Role
+	// NicknameChangedAt is time player renamed last, zero for generated nickname
+	NicknameChangedAt time.Time
+
+	Avatar  Avatar
+	Bio     string
+	Country string
+	Privacy Privacy
+
+	// DeleteRequestedAt is time player asked to delete account, zero unless deletion is pending
+	DeleteRequestedAt time.Time
+	// PurgeStartedAt is time purge of account started, deletion can't be cancelled since
+	PurgeStartedAt time.Time
+
+	// Device is hash of device id guest is bound to, empty for users with wallet
+	Device string
+}
+
+// IsGuest checks user has no wallet yet
+func (u *User) IsGuest() bool {
+	return u.Role == RoleGuest
+}
+
+// HasWallet checks wallet is primary or linked wallet of user
+func (u *User) HasWallet(wallet string) bool {
+	if u.Wallet == wallet {
+		return true
+	}
+	for _, linked := range u.Wallets {
+		if linked == wallet {
+			return true
+		}
+	}
+	return false
+}
+
+// AllWallets returns primary wallet of user followed by linked ones
+func (u *User) AllWallets() []string {
+	wallets := make([]string, 0, len(u.Wallets)+1)
+	if u.Wallet != "" {
+		wallets = append(wallets, u.Wallet)
+	}
+	return append(wallets, u.Wallets...)
 }
 
 type UserAuthReq struct {
-	Wallet  string
+	// Chain is CAIP-2 namespace of wallet, Ethereum (eip155) when empty
+	Chain   string
+	Wallet  Address
 	Message string
 	Sign    string
+	// TypedData is EIP-712 JSON payload, signed instead of Message when set
+	TypedData []byte
+	// GuestID is guest upgraded to user of wallet, progress of guest is kept
+	GuestID string
+}
+
+// UserUpdateReq holds user fields player changes, nil fields are left as is
+type UserUpdateReq struct {
+	Nickname *string
+	Avatar   *Avatar
+	Bio      *string
+	Country  *string
+	Privacy  *Privacy
 }
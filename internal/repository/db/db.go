@@ -0,0 +1,119 @@
+// Package db opens storage backend selected in config
+package db
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/internal/repository/db/memory"
+	"server/internal/repository/db/mongodb"
+	"server/internal/repository/db/postgres"
+	"server/internal/repository/db/sqlite"
+	"server/pkg/keyenc"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	dbErrorPrefix = "[repository.db]"
+)
+
+// Open connects to storage backends selected in config and prepares them for use
+func Open(ctx context.Context) (*repository.Repositories, error) {
+	repos, err := openStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := config.Get()
+	switch cfg.UserStorage {
+	case "", cfg.Storage:
+	case repository.StoragePostgres:
+		db, err := postgres.Connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		repos.User = postgres.NewUserRepo(db)
+	case repository.StorageSQLite:
+		db, err := sqlite.Connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		repos.User = sqlite.NewUserRepo(db)
+	default:
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: user storage %q unknown", dbErrorPrefix, cfg.UserStorage)
+	}
+
+	return repos, nil
+}
+
+func openStorage(ctx context.Context) (*repository.Repositories, error) {
+	cfg := config.Get()
+	switch cfg.Storage {
+	case repository.StorageMongoDB:
+		return openMongoDB(ctx)
+	case repository.StorageMemory:
+		return memory.NewRepositories(), nil
+	case repository.StorageSQLite:
+		cipher, err := keyCipher()
+		if err != nil {
+			return nil, err
+		}
+		db, err := sqlite.Connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return sqlite.NewRepositories(db, cipher), nil
+	case repository.StoragePostgres:
+		cipher, err := keyCipher()
+		if err != nil {
+			return nil, err
+		}
+		db, err := postgres.Connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewRepositories(db, cipher), nil
+	default:
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: storage %q unknown", dbErrorPrefix, cfg.Storage)
+	}
+}
+
+// keyCipher returns cipher signing keys are encrypted with in storage
+func keyCipher() (*keyenc.Cipher, error) {
+	kek, err := keyenc.ParseKey(config.Get().JWTKeyEncryptionKey)
+	if err != nil {
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: %s", dbErrorPrefix, err)
+	}
+	return keyenc.New(kek)
+}
+
+func openMongoDB(ctx context.Context) (*repository.Repositories, error) {
+	cipher, err := keyCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := mongodb.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Bootstrap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.Repositories{
+		User:         mongodb.NewUserRepo(db),
+		Nonce:        mongodb.NewNonceRepo(db),
+		RefreshToken: mongodb.NewRefreshTokenRepo(db),
+		Revocation:   mongodb.NewRevocationRepo(db),
+		SigningKey:   mongodb.NewSigningKeyRepo(db, cipher),
+		Session:      mongodb.NewSessionRepo(db),
+		RoleChange:   mongodb.NewRoleChangeRepo(db),
+		Sanction:     mongodb.NewSanctionRepo(db),
+		APIKey:       mongodb.NewAPIKeyRepo(db),
+	}, nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	apiKeyErrorPrefix = "[repository.db.memory.apikey]"
+)
+
+var _ repository.APIKeyRepository = (*APIKeyMemoryRepo)(nil)
+
+type APIKeyMemoryRepo struct {
+	mu   sync.RWMutex
+	keys map[string]*domain.APIKey
+}
+
+func NewAPIKeyRepo() *APIKeyMemoryRepo {
+	return &APIKeyMemoryRepo{keys: map[string]*domain.APIKey{}}
+}
+
+func cloneAPIKey(key *domain.APIKey) *domain.APIKey {
+	clone := *key
+	clone.Scopes = slices.Clone(key.Scopes)
+	return &clone
+}
+
+func (repo *APIKeyMemoryRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, existing := range repo.keys {
+		if existing.ID == key.ID || existing.Hash == key.Hash {
+			return errors.Errorf("%s: create: api key exists", apiKeyErrorPrefix)
+		}
+	}
+	stored := cloneAPIKey(key)
+	stored.LastUsedAt = time.Time{}
+	stored.RevokedAt = time.Time{}
+	repo.keys[key.ID] = stored
+	return nil
+}
+
+func (repo *APIKeyMemoryRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, key := range repo.keys {
+		if key.Hash == hash {
+			return cloneAPIKey(key), nil
+		}
+	}
+	return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", apiKeyErrorPrefix)
+}
+
+// GetAll returns all keys including revoked ones, newest first
+func (repo *APIKeyMemoryRepo) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	keys := make([]*domain.APIKey, 0, len(repo.keys))
+	for _, key := range repo.keys {
+		keys = append(keys, cloneAPIKey(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Revoke disables key, revoked key can't be revoked again
+func (repo *APIKeyMemoryRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, ok := repo.keys[id]
+	if !ok || !key.RevokedAt.IsZero() {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: revoke", apiKeyErrorPrefix)
+	}
+	key.RevokedAt = revokedAt
+	return nil
+}
+
+// Touch records when key was last used
+func (repo *APIKeyMemoryRepo) Touch(ctx context.Context, id string, lastUsedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, ok := repo.keys[id]
+	if !ok {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: touch", apiKeyErrorPrefix)
+	}
+	key.LastUsedAt = lastUsedAt
+	return nil
+}
+
+// AnonymizeActor replaces admin who created keys with anonymous one
+func (repo *APIKeyMemoryRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, key := range repo.keys {
+		if key.CreatedBy == userID {
+			key.CreatedBy = anonymous
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	signingKeyErrorPrefix = "[repository.db.memory.key]"
+)
+
+var _ repository.SigningKeyRepository = (*SigningKeyMemoryRepo)(nil)
+
+type SigningKeyMemoryRepo struct {
+	mu   sync.RWMutex
+	keys []*domain.SigningKey
+}
+
+func NewSigningKeyRepo() *SigningKeyMemoryRepo {
+	return &SigningKeyMemoryRepo{}
+}
+
+func (repo *SigningKeyMemoryRepo) Create(ctx context.Context, key *domain.SigningKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, existing := range repo.keys {
+		if existing.ID == key.ID {
+			return errors.Errorf("%s: create: key %s exists", signingKeyErrorPrefix, key.ID)
+		}
+	}
+	stored := *key
+	repo.keys = append(repo.keys, &stored)
+	return nil
+}
+
+// GetActive returns keys not expired at the given time, newest first
+func (repo *SigningKeyMemoryRepo) GetActive(ctx context.Context, now time.Time) ([]*domain.SigningKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	keys := []*domain.SigningKey{}
+	for _, key := range repo.keys {
+		if key.ExpiresAt.After(now) {
+			found := *key
+			keys = append(keys, &found)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
@@ -0,0 +1,22 @@
+// Package memory is storage backend keeping data in process memory, for tests and local development
+// without database. Repositories are safe for concurrent use and hand out copies of stored values.
+package memory
+
+import (
+	"server/internal/repository"
+)
+
+// NewRepositories returns empty in-memory storages
+func NewRepositories() *repository.Repositories {
+	return &repository.Repositories{
+		User:         NewUserRepo(),
+		Nonce:        NewNonceRepo(),
+		RefreshToken: NewRefreshTokenRepo(),
+		Revocation:   NewRevocationRepo(),
+		SigningKey:   NewSigningKeyRepo(),
+		Session:      NewSessionRepo(),
+		RoleChange:   NewRoleChangeRepo(),
+		Sanction:     NewSanctionRepo(),
+		APIKey:       NewAPIKeyRepo(),
+	}
+}
@@ -0,0 +1,10 @@
+package memory
+
+import (
+	"server/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestRepositories_Contract(t *testing.T) {
+	repositorytest.Repositories(t, NewRepositories())
+}
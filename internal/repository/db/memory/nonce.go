@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	nonceErrorPrefix = "[repository.db.memory.nonce]"
+)
+
+var _ repository.NonceRepository = (*NonceMemoryRepo)(nil)
+
+type NonceMemoryRepo struct {
+	mu     sync.Mutex
+	nonces map[string]*nonceEntry
+}
+
+type nonceEntry struct {
+	nonce    domain.Nonce
+	consumed bool
+}
+
+func NewNonceRepo() *NonceMemoryRepo {
+	return &NonceMemoryRepo{nonces: map[string]*nonceEntry{}}
+}
+
+// Create stores nonce, expired nonces are dropped meanwhile as nothing else removes them
+func (repo *NonceMemoryRepo) Create(ctx context.Context, nonce *domain.Nonce) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	for value, entry := range repo.nonces {
+		if !entry.nonce.ExpiresAt.After(now) {
+			delete(repo.nonces, value)
+		}
+	}
+
+	if _, ok := repo.nonces[nonce.Value]; ok {
+		return errors.Errorf("%s: create: nonce exists", nonceErrorPrefix)
+	}
+	repo.nonces[nonce.Value] = &nonceEntry{nonce: *nonce}
+	return nil
+}
+
+// Consume marks nonce issued for wallet as used, a nonce can be consumed only once and only before it expires
+func (repo *NonceMemoryRepo) Consume(ctx context.Context, wallet domain.Address, value string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	entry, ok := repo.nonces[value]
+	if !ok || entry.consumed || entry.nonce.Wallet != wallet || !entry.nonce.ExpiresAt.After(time.Now()) {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: consume", nonceErrorPrefix)
+	}
+	entry.consumed = true
+	return nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	revocationErrorPrefix = "[repository.db.memory.revocation]"
+)
+
+var _ repository.RevocationRepository = (*RevocationMemoryRepo)(nil)
+
+type RevocationMemoryRepo struct {
+	mu            sync.RWMutex
+	revokedTokens map[string]*domain.AccessToken
+	revokedUsers  map[string]time.Time
+}
+
+func NewRevocationRepo() *RevocationMemoryRepo {
+	return &RevocationMemoryRepo{
+		revokedTokens: map[string]*domain.AccessToken{},
+		revokedUsers:  map[string]time.Time{},
+	}
+}
+
+// RevokeAccessToken stores token id until token expires
+func (repo *RevocationMemoryRepo) RevokeAccessToken(ctx context.Context, token *domain.AccessToken) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	for id, revoked := range repo.revokedTokens {
+		if !revoked.ExpiresAt.After(now) {
+			delete(repo.revokedTokens, id)
+		}
+	}
+
+	stored := *token
+	repo.revokedTokens[token.ID] = &stored
+	return nil
+}
+
+func (repo *RevocationMemoryRepo) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	_, ok := repo.revokedTokens[id]
+	return ok, nil
+}
+
+// RevokeUser revokes all user access tokens issued before revokedAt
+func (repo *RevocationMemoryRepo) RevokeUser(ctx context.Context, userID string, revokedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.revokedUsers[userID] = revokedAt
+	return nil
+}
+
+func (repo *RevocationMemoryRepo) GetUserRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	revokedAt, ok := repo.revokedUsers[userID]
+	if !ok {
+		return time.Time{}, errors.Wrapf(domain.ErrNoDocuments, "%s: get user revoked at", revocationErrorPrefix)
+	}
+	return revokedAt, nil
+}
+
+// DeleteByUser removes revoked access tokens and revocation of all tokens of user
+func (repo *RevocationMemoryRepo) DeleteByUser(ctx context.Context, userID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, token := range repo.revokedTokens {
+		if token.UserID == userID {
+			delete(repo.revokedTokens, id)
+		}
+	}
+	delete(repo.revokedUsers, userID)
+	return nil
+}
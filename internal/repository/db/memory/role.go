@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	roleChangeErrorPrefix = "[repository.db.memory.role]"
+)
+
+var _ repository.RoleChangeRepository = (*RoleChangeMemoryRepo)(nil)
+
+type RoleChangeMemoryRepo struct {
+	mu      sync.RWMutex
+	changes []*domain.RoleChange
+}
+
+func NewRoleChangeRepo() *RoleChangeMemoryRepo {
+	return &RoleChangeMemoryRepo{}
+}
+
+func (repo *RoleChangeMemoryRepo) Create(ctx context.Context, change *domain.RoleChange) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, existing := range repo.changes {
+		if existing.ID == change.ID {
+			return errors.Errorf("%s: create: role change %s exists", roleChangeErrorPrefix, change.ID)
+		}
+	}
+	stored := *change
+	repo.changes = append(repo.changes, &stored)
+	return nil
+}
+
+// GetByUser returns role changes of user, newest first
+func (repo *RoleChangeMemoryRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RoleChange, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	changes := []*domain.RoleChange{}
+	for _, change := range repo.changes {
+		if change.UserID == userID {
+			found := *change
+			changes = append(changes, &found)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].CreatedAt.After(changes[j].CreatedAt)
+	})
+	return changes, nil
+}
+
+func (repo *RoleChangeMemoryRepo) DeleteByUser(ctx context.Context, userID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	changes := repo.changes[:0]
+	for _, change := range repo.changes {
+		if change.UserID != userID {
+			changes = append(changes, change)
+		}
+	}
+	repo.changes = changes
+	return nil
+}
+
+// AnonymizeActor replaces author of role changes made by user with anonymous one
+func (repo *RoleChangeMemoryRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, change := range repo.changes {
+		if change.ChangedBy == userID {
+			change.ChangedBy = anonymous
+		}
+	}
+	return nil
+}
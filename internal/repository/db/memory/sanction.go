@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	sanctionErrorPrefix = "[repository.db.memory.sanction]"
+)
+
+var _ repository.SanctionRepository = (*SanctionMemoryRepo)(nil)
+
+type SanctionMemoryRepo struct {
+	mu        sync.RWMutex
+	sanctions []*domain.Sanction
+}
+
+func NewSanctionRepo() *SanctionMemoryRepo {
+	return &SanctionMemoryRepo{}
+}
+
+func (repo *SanctionMemoryRepo) Create(ctx context.Context, sanction *domain.Sanction) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, existing := range repo.sanctions {
+		if existing.ID == sanction.ID {
+			return errors.Errorf("%s: create: sanction %s exists", sanctionErrorPrefix, sanction.ID)
+		}
+	}
+	stored := *sanction
+	stored.LiftedBy = ""
+	stored.LiftedAt = time.Time{}
+	repo.sanctions = append(repo.sanctions, &stored)
+	return nil
+}
+
+func (repo *SanctionMemoryRepo) GetById(ctx context.Context, id string) (*domain.Sanction, error) {
+	sanctions := repo.find(func(sanction *domain.Sanction) bool {
+		return sanction.ID == id
+	})
+	if len(sanctions) == 0 {
+		return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by id", sanctionErrorPrefix)
+	}
+	return sanctions[0], nil
+}
+
+// GetByUser returns all sanctions of user, newest first
+func (repo *SanctionMemoryRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Sanction, error) {
+	return repo.find(func(sanction *domain.Sanction) bool {
+		return sanction.UserID == userID
+	}), nil
+}
+
+// GetActive returns sanctions of user neither lifted nor expired at now, newest first
+func (repo *SanctionMemoryRepo) GetActive(ctx context.Context, userID string, now time.Time) ([]*domain.Sanction, error) {
+	return repo.find(func(sanction *domain.Sanction) bool {
+		return sanction.UserID == userID && sanction.Active(now)
+	}), nil
+}
+
+// Lift ends sanction before it expires, lifted sanction can't be lifted again
+func (repo *SanctionMemoryRepo) Lift(ctx context.Context, id, liftedBy string, liftedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, sanction := range repo.sanctions {
+		if sanction.ID == id && sanction.LiftedAt.IsZero() {
+			sanction.LiftedBy = liftedBy
+			sanction.LiftedAt = liftedAt
+			return nil
+		}
+	}
+	return errors.Wrapf(domain.ErrNoDocuments, "%s: lift", sanctionErrorPrefix)
+}
+
+func (repo *SanctionMemoryRepo) DeleteByUser(ctx context.Context, userID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sanctions := repo.sanctions[:0]
+	for _, sanction := range repo.sanctions {
+		if sanction.UserID != userID {
+			sanctions = append(sanctions, sanction)
+		}
+	}
+	repo.sanctions = sanctions
+	return nil
+}
+
+// AnonymizeActor replaces moderator who issued or lifted sanctions with anonymous one
+func (repo *SanctionMemoryRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, sanction := range repo.sanctions {
+		if sanction.IssuedBy == userID {
+			sanction.IssuedBy = anonymous
+		}
+		if sanction.LiftedBy == userID {
+			sanction.LiftedBy = anonymous
+		}
+	}
+	return nil
+}
+
+func (repo *SanctionMemoryRepo) find(match func(*domain.Sanction) bool) []*domain.Sanction {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	sanctions := []*domain.Sanction{}
+	for _, sanction := range repo.sanctions {
+		if match(sanction) {
+			found := *sanction
+			sanctions = append(sanctions, &found)
+		}
+	}
+	sort.SliceStable(sanctions, func(i, j int) bool {
+		return sanctions[i].CreatedAt.After(sanctions[j].CreatedAt)
+	})
+	return sanctions
+}
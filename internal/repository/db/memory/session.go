@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	sessionErrorPrefix = "[repository.db.memory.session]"
+)
+
+var _ repository.SessionRepository = (*SessionMemoryRepo)(nil)
+
+type SessionMemoryRepo struct {
+	mu       sync.RWMutex
+	sessions map[string]*domain.Session
+}
+
+func NewSessionRepo() *SessionMemoryRepo {
+	return &SessionMemoryRepo{sessions: map[string]*domain.Session{}}
+}
+
+func (repo *SessionMemoryRepo) Create(ctx context.Context, session *domain.Session) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.sessions[session.ID]; ok {
+		return errors.Errorf("%s: create: session %s exists", sessionErrorPrefix, session.ID)
+	}
+	stored := *session
+	repo.sessions[session.ID] = &stored
+	return nil
+}
+
+// Touch updates session device and last seen time
+func (repo *SessionMemoryRepo) Touch(ctx context.Context, id string, device *domain.Device, lastSeenAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	session, ok := repo.sessions[id]
+	if !ok {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: touch", sessionErrorPrefix)
+	}
+	session.UserAgent = device.UserAgent
+	session.IP = device.IP
+	session.LastSeenAt = lastSeenAt
+	return nil
+}
+
+// GetByUser returns user sessions, recently seen first
+func (repo *SessionMemoryRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	sessions := []*domain.Session{}
+	for _, session := range repo.sessions {
+		if session.UserID == userID {
+			found := *session
+			sessions = append(sessions, &found)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (repo *SessionMemoryRepo) Exists(ctx context.Context, userID, id string) (bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	session, ok := repo.sessions[id]
+	return ok && session.UserID == userID, nil
+}
+
+// Delete removes session only if it belongs to user
+func (repo *SessionMemoryRepo) Delete(ctx context.Context, userID, id string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	session, ok := repo.sessions[id]
+	if !ok || session.UserID != userID {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: delete", sessionErrorPrefix)
+	}
+	delete(repo.sessions, id)
+	return nil
+}
+
+func (repo *SessionMemoryRepo) DeleteByUser(ctx context.Context, userID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, session := range repo.sessions {
+		if session.UserID == userID {
+			delete(repo.sessions, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	refreshTokenErrorPrefix = "[repository.db.memory.token]"
+)
+
+var _ repository.RefreshTokenRepository = (*RefreshTokenMemoryRepo)(nil)
+
+type RefreshTokenMemoryRepo struct {
+	mu     sync.RWMutex
+	tokens map[string]*domain.RefreshToken
+}
+
+func NewRefreshTokenRepo() *RefreshTokenMemoryRepo {
+	return &RefreshTokenMemoryRepo{tokens: map[string]*domain.RefreshToken{}}
+}
+
+func (repo *RefreshTokenMemoryRepo) Create(ctx context.Context, token *domain.RefreshToken) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.tokens[token.Hash]; ok {
+		return errors.Errorf("%s: create: token exists", refreshTokenErrorPrefix)
+	}
+	stored := *token
+	repo.tokens[token.Hash] = &stored
+	return nil
+}
+
+func (repo *RefreshTokenMemoryRepo) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	token, ok := repo.tokens[hash]
+	if !ok {
+		return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", refreshTokenErrorPrefix)
+	}
+	found := *token
+	return &found, nil
+}
+
+// GetByUser returns refresh tokens of user, recently created first
+func (repo *RefreshTokenMemoryRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RefreshToken, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	tokens := []*domain.RefreshToken{}
+	for _, token := range repo.tokens {
+		if token.UserID == userID {
+			found := *token
+			tokens = append(tokens, &found)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// MarkUsed flags token as rotated, only one caller can succeed for a token
+func (repo *RefreshTokenMemoryRepo) MarkUsed(ctx context.Context, hash string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	token, ok := repo.tokens[hash]
+	if !ok || token.Used {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: mark used", refreshTokenErrorPrefix)
+	}
+	token.Used = true
+	return nil
+}
+
+func (repo *RefreshTokenMemoryRepo) RevokeFamily(ctx context.Context, family string) error {
+	repo.revoke(func(token *domain.RefreshToken) bool { return token.Family == family })
+	return nil
+}
+
+func (repo *RefreshTokenMemoryRepo) RevokeUser(ctx context.Context, userID string) error {
+	repo.revoke(func(token *domain.RefreshToken) bool { return token.UserID == userID })
+	return nil
+}
+
+func (repo *RefreshTokenMemoryRepo) DeleteByUser(ctx context.Context, userID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for hash, token := range repo.tokens {
+		if token.UserID == userID {
+			delete(repo.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (repo *RefreshTokenMemoryRepo) revoke(match func(*domain.RefreshToken) bool) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, token := range repo.tokens {
+		if match(token) {
+			token.Revoked = true
+		}
+	}
+}
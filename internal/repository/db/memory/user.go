@@ -0,0 +1,328 @@
+package memory
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	userErrorPrefix = "[repository.db.memory.user]"
+)
+
+var _ repository.UserRepository = (*UserMemoryRepo)(nil)
+
+type UserMemoryRepo struct {
+	mu    sync.RWMutex
+	users map[string]*domain.User
+}
+
+func NewUserRepo() *UserMemoryRepo {
+	return &UserMemoryRepo{users: map[string]*domain.User{}}
+}
+
+// nicknameKey compares nicknames ignoring case like nickname index of MongoDB
+func nicknameKey(nickname string) string {
+	return strings.ToLower(nickname)
+}
+
+func cloneUser(user *domain.User) *domain.User {
+	clone := *user
+	clone.Wallets = slices.Clone(user.Wallets)
+	return &clone
+}
+
+func (repo *UserMemoryRepo) GetById(ctx context.Context, id string) (*domain.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by id", userErrorPrefix)
+	}
+	return cloneUser(user), nil
+}
+
+// GetByWallet returns user by primary or linked wallet
+func (repo *UserMemoryRepo) GetByWallet(ctx context.Context, wallet string) (*domain.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, user := range repo.users {
+		if user.HasWallet(wallet) {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by wallet", userErrorPrefix)
+}
+
+// GetByDevice returns guest bound to device
+func (repo *UserMemoryRepo) GetByDevice(ctx context.Context, device string) (*domain.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, user := range repo.users {
+		if user.Device != "" && user.Device == device {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by device", userErrorPrefix)
+}
+
+// Search returns users with nickname starting with prefix ignoring case, ordered by nickname.
+// Page starts after nickname after, from the first user when empty.
+func (repo *UserMemoryRepo) Search(ctx context.Context, prefix, after string, limit int) ([]*domain.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	prefix = nicknameKey(prefix)
+	after = nicknameKey(after)
+	users := []*domain.User{}
+	for _, user := range repo.users {
+		nickname := nicknameKey(user.Nickname)
+		if user.Nickname == "" || !user.DeleteRequestedAt.IsZero() ||
+			!strings.HasPrefix(nickname, prefix) || after != "" && nickname <= after {
+			continue
+		}
+		users = append(users, cloneUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return nicknameKey(users[i].Nickname) < nicknameKey(users[j].Nickname)
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (repo *UserMemoryRepo) Create(ctx context.Context, user *domain.User) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.users[user.ID]; ok {
+		return errors.Wrapf(domain.ErrAlreadyExists, "%s: create: user %s", userErrorPrefix, user.ID)
+	}
+	if repo.nicknameTaken(user.ID, user.Nickname) {
+		return errors.Wrapf(domain.ErrNicknameTaken, "%s: create", userErrorPrefix)
+	}
+	for _, existing := range repo.users {
+		if user.Device != "" && existing.Device == user.Device {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: create: device is bound to user %s", userErrorPrefix, existing.ID)
+		}
+	}
+	for _, wallet := range append([]string{user.Wallet}, user.Wallets...) {
+		if owner := repo.walletOwner(user.ID, wallet); owner != "" {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: create: wallet belongs to user %s", userErrorPrefix, owner)
+		}
+	}
+
+	// profile is filled by updates, new user has none
+	repo.users[user.ID] = &domain.User{
+		ID:        user.ID,
+		Nickname:  user.Nickname,
+		Wallet:    user.Wallet,
+		Wallets:   slices.Clone(user.Wallets),
+		CreatedAt: user.CreatedAt,
+		Role:      user.Role,
+		Device:    user.Device,
+	}
+	return nil
+}
+
+// Update saves profile fields of user, wallets are changed by dedicated methods
+func (repo *UserMemoryRepo) Update(ctx context.Context, user *domain.User) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.users[user.ID]
+	if !ok {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: update", userErrorPrefix)
+	}
+	if repo.nicknameTaken(user.ID, user.Nickname) {
+		return errors.Wrapf(domain.ErrNicknameTaken, "%s: update", userErrorPrefix)
+	}
+
+	stored.Nickname = user.Nickname
+	stored.NicknameChangedAt = user.NicknameChangedAt
+	stored.Avatar = user.Avatar
+	stored.Bio = user.Bio
+	stored.Country = user.Country
+	stored.Privacy = user.Privacy
+	return nil
+}
+
+// Delete removes user who asked to delete account before time
+func (repo *UserMemoryRepo) Delete(ctx context.Context, id string, before time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok || user.DeleteRequestedAt.IsZero() || user.DeleteRequestedAt.After(before) {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: delete", userErrorPrefix)
+	}
+	delete(repo.users, id)
+	return nil
+}
+
+// ScheduleDeletion marks user as pending deletion, zero requestedAt cancels deletion unless purge started
+func (repo *UserMemoryRepo) ScheduleDeletion(ctx context.Context, id string, requestedAt time.Time) error {
+	return repo.update(id, "schedule deletion", func(user *domain.User) bool {
+		if !user.PurgeStartedAt.IsZero() {
+			return false
+		}
+		user.DeleteRequestedAt = requestedAt
+		return true
+	})
+}
+
+// ClaimPurge marks purge of user started if it asked to delete account before time
+func (repo *UserMemoryRepo) ClaimPurge(ctx context.Context, id string, before time.Time) error {
+	return repo.update(id, "claim purge", func(user *domain.User) bool {
+		if user.DeleteRequestedAt.IsZero() || user.DeleteRequestedAt.After(before) {
+			return false
+		}
+		if user.PurgeStartedAt.IsZero() {
+			user.PurgeStartedAt = time.Now()
+		}
+		return true
+	})
+}
+
+// GetDeletionDue returns up to limit users who asked to delete account before time
+func (repo *UserMemoryRepo) GetDeletionDue(ctx context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	users := []*domain.User{}
+	for _, user := range repo.users {
+		if !user.DeleteRequestedAt.IsZero() && !user.DeleteRequestedAt.After(before) {
+			users = append(users, cloneUser(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].DeleteRequestedAt.Before(users[j].DeleteRequestedAt)
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// SetRole assigns role to user, nothing is changed unless user still has previous role
+func (repo *UserMemoryRepo) SetRole(ctx context.Context, id string, role, previous domain.Role) error {
+	return repo.update(id, "set role", func(user *domain.User) bool {
+		if user.Role != previous {
+			return false
+		}
+		user.Role = role
+		return true
+	})
+}
+
+// AddWallet links additional wallet to user
+func (repo *UserMemoryRepo) AddWallet(ctx context.Context, id, wallet string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: add wallet", userErrorPrefix)
+	}
+	if owner := repo.walletOwner(id, wallet); owner != "" {
+		return errors.Wrapf(domain.ErrAlreadyExists, "%s: add wallet: wallet belongs to user %s", userErrorPrefix, owner)
+	}
+	if !slices.Contains(user.Wallets, wallet) {
+		user.Wallets = append(user.Wallets, wallet)
+	}
+	return nil
+}
+
+// RemoveWallet unlinks additional wallet from user
+func (repo *UserMemoryRepo) RemoveWallet(ctx context.Context, id, wallet string) error {
+	return repo.update(id, "remove wallet", func(user *domain.User) bool {
+		index := slices.Index(user.Wallets, wallet)
+		if index < 0 {
+			return false
+		}
+		user.Wallets = slices.Delete(user.Wallets, index, index+1)
+		return true
+	})
+}
+
+// ReplacePrimaryWallet unlinks primary wallet and promotes linked one in its place,
+// nothing is changed unless both wallets still belong to user
+func (repo *UserMemoryRepo) ReplacePrimaryWallet(ctx context.Context, id, primary, wallet string) error {
+	return repo.update(id, "replace primary wallet", func(user *domain.User) bool {
+		index := slices.Index(user.Wallets, wallet)
+		if user.Wallet != primary || index < 0 {
+			return false
+		}
+		user.Wallet = wallet
+		user.Wallets = slices.Delete(user.Wallets, index, index+1)
+		return true
+	})
+}
+
+// UpgradeGuest gives guest primary wallet and player role and unbinds it from device,
+// nothing is changed unless user is still guest
+func (repo *UserMemoryRepo) UpgradeGuest(ctx context.Context, id, wallet string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok || !user.IsGuest() {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: upgrade guest", userErrorPrefix)
+	}
+	if owner := repo.walletOwner(id, wallet); owner != "" {
+		return errors.Wrapf(domain.ErrAlreadyExists, "%s: upgrade guest: wallet belongs to user %s", userErrorPrefix, owner)
+	}
+	user.Wallet = wallet
+	user.Role = domain.RolePlayer
+	user.Device = ""
+	return nil
+}
+
+// update changes stored user under lock, change reports whether user matched its conditions
+func (repo *UserMemoryRepo) update(id, operation string, change func(*domain.User) bool) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[id]
+	if !ok || !change(user) {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: %s", userErrorPrefix, operation)
+	}
+	return nil
+}
+
+// walletOwner returns id of another user having wallet as primary or linked one, empty wallet has no owner
+func (repo *UserMemoryRepo) walletOwner(id, wallet string) string {
+	if wallet == "" {
+		return ""
+	}
+	for _, user := range repo.users {
+		if user.ID != id && user.HasWallet(wallet) {
+			return user.ID
+		}
+	}
+	return ""
+}
+
+// nicknameTaken checks another user has nickname ignoring case, empty nickname is never taken
+func (repo *UserMemoryRepo) nicknameTaken(id, nickname string) bool {
+	if nickname == "" {
+		return false
+	}
+	for _, user := range repo.users {
+		if user.ID != id && nicknameKey(user.Nickname) == nicknameKey(nickname) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// Package migration reads versioned SQL migrations SQL storage backends apply on connect.
+// Migration file name starts with its version, e.g. 0001_create_users.sql, and migrations are
+// applied in order of version. Applied migration must never be changed, schema is changed by adding a new one.
+package migration
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	migrationErrorPrefix = "[repository.db.migration]"
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Load reads migrations of dir ordered by version
+func Load(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: load", migrationErrorPrefix)
+	}
+
+	res := make([]*Migration, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		version, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+		if err != nil {
+			return nil, errors.Errorf("%s: migration %s must start with version", migrationErrorPrefix, name)
+		}
+		sql, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: load", migrationErrorPrefix)
+		}
+		res = append(res, &Migration{version, name, string(sql)})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version < res[j].Version
+	})
+	for i := 1; i < len(res); i++ {
+		if res[i].Version == res[i-1].Version {
+			return nil, errors.Errorf("%s: migrations %s and %s have the same version",
+				migrationErrorPrefix, res[i-1].Name, res[i].Name)
+		}
+	}
+	return res, nil
+}
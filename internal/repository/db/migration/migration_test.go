@@ -0,0 +1,59 @@
+package migration_test
+
+import (
+	"server/internal/repository/db/migration"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(sql string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(sql)}
+	}
+
+	testCases := []struct {
+		name     string
+		fsys     fstest.MapFS
+		expected []*migration.Migration
+		valid    bool
+	}{
+		{
+			name: "ordered by version",
+			fsys: fstest.MapFS{
+				"migrations/0010_add_bio.sql":      file("ALTER TABLE users ADD bio text"),
+				"migrations/0002_create_users.sql": file("CREATE TABLE users (id text)"),
+			},
+			expected: []*migration.Migration{
+				{Version: 2, Name: "0002_create_users.sql", SQL: "CREATE TABLE users (id text)"},
+				{Version: 10, Name: "0010_add_bio.sql", SQL: "ALTER TABLE users ADD bio text"},
+			},
+			valid: true,
+		},
+		{
+			name: "without version",
+			fsys: fstest.MapFS{"migrations/create_users.sql": file("CREATE TABLE users (id text)")},
+		},
+		{
+			name: "same version",
+			fsys: fstest.MapFS{
+				"migrations/0001_create_users.sql": file("CREATE TABLE users (id text)"),
+				"migrations/1_create_keys.sql":     file("CREATE TABLE keys (id text)"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		migrations, err := migration.Load(test.fsys, "migrations")
+		if test.valid {
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, migrations)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	apiKeyTable       = "api_key"
+	apiKeyErrorPrefix = "[repository.db.mongodb.apikey]"
+)
+
+var _ repository.APIKeyRepository = (*APIKeyMongoRepo)(nil)
+
+// apiKeyHashIndex makes key lookup by hash fast and unique
+const apiKeyHashIndex = "hash_unique"
+
+type APIKeyMongoRepo struct {
+	db *DB
+}
+
+type apiKeyDB struct {
+	ID         string    `bson:"_id"`
+	Name       string    `bson:"name"`
+	Hash       string    `bson:"hash"`
+	Prefix     string    `bson:"prefix"`
+	Scopes     []string  `bson:"scopes"`
+	CreatedBy  string    `bson:"createdBy"`
+	CreatedAt  time.Time `bson:"createdAt"`
+	LastUsedAt time.Time `bson:"lastUsedAt,omitempty"`
+	RevokedAt  time.Time `bson:"revokedAt,omitempty"`
+}
+
+func (k *apiKeyDB) domain() *domain.APIKey {
+	scopes := make([]domain.Permission, 0, len(k.Scopes))
+	for _, scope := range k.Scopes {
+		scopes = append(scopes, domain.Permission(scope))
+	}
+	return &domain.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Hash:       k.Hash,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+func NewAPIKeyRepo(db *DB) *APIKeyMongoRepo {
+	return &APIKeyMongoRepo{db}
+}
+
+func (repo *APIKeyMongoRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	apiKeyDb := &apiKeyDB{
+		ID:        key.ID,
+		Name:      key.Name,
+		Hash:      key.Hash,
+		Prefix:    key.Prefix,
+		Scopes:    scopes,
+		CreatedBy: key.CreatedBy,
+		CreatedAt: key.CreatedAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(apiKeyTable).
+		InsertOne(ctx, apiKeyDb)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *APIKeyMongoRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	apiKeyDb := new(apiKeyDB)
+	cfg := config.Get()
+	err := repo.db.Client.Database(cfg.MongoDB).Collection(apiKeyTable).
+		FindOne(ctx, bson.D{{Key: "hash", Value: hash}}).Decode(apiKeyDb)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", apiKeyErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", apiKeyErrorPrefix)
+	}
+	return apiKeyDb.domain(), nil
+}
+
+// GetAll returns all keys including revoked ones, newest first
+func (repo *APIKeyMongoRepo) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(apiKeyTable).
+		Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", apiKeyErrorPrefix)
+	}
+
+	apiKeysDb := []*apiKeyDB{}
+	err = cursor.All(ctx, &apiKeysDb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", apiKeyErrorPrefix)
+	}
+
+	keys := make([]*domain.APIKey, 0, len(apiKeysDb))
+	for _, apiKeyDb := range apiKeysDb {
+		keys = append(keys, apiKeyDb.domain())
+	}
+	return keys, nil
+}
+
+// Revoke disables key, revoked key can't be revoked again
+func (repo *APIKeyMongoRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(apiKeyTable).
+		UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: id}, {Key: "revokedAt", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "revokedAt", Value: revokedAt}}}},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke", apiKeyErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: revoke", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// Touch records when key was last used
+func (repo *APIKeyMongoRepo) Touch(ctx context.Context, id string, lastUsedAt time.Time) error {
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(apiKeyTable).
+		UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: id}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "lastUsedAt", Value: lastUsedAt}}}},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: touch", apiKeyErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: touch", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces admin who created keys with anonymous one
+func (repo *APIKeyMongoRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(apiKeyTable).
+		UpdateMany(ctx,
+			bson.D{{Key: "createdBy", Value: userID}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "createdBy", Value: anonymous}}}},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", apiKeyErrorPrefix)
+	}
+	return nil
+}
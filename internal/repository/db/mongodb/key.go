@@ -0,0 +1,101 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/pkg/keyenc"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	signingKeyTable       = "signing_key"
+	signingKeyErrorPrefix = "[repository.db.mongodb.key]"
+)
+
+var _ repository.SigningKeyRepository = (*SigningKeyMongoRepo)(nil)
+
+// SigningKeyMongoRepo keeps private keys encrypted with key-encryption key
+type SigningKeyMongoRepo struct {
+	db     *DB
+	cipher *keyenc.Cipher
+}
+
+type signingKeyDB struct {
+	ID                  string    `bson:"_id"`
+	Algorithm           string    `bson:"algorithm"`
+	EncryptedPrivateKey []byte    `bson:"encryptedPrivateKey"`
+	CreatedAt           time.Time `bson:"createdAt"`
+	ExpiresAt           time.Time `bson:"expiresAt"`
+}
+
+// plainSigningKeys matches keys older versions stored in plain, Bootstrap removes them
+var plainSigningKeys = bson.D{{Key: "privateKey", Value: bson.D{{Key: "$exists", Value: true}}}}
+
+func NewSigningKeyRepo(db *DB, cipher *keyenc.Cipher) *SigningKeyMongoRepo {
+	return &SigningKeyMongoRepo{db, cipher}
+}
+
+func (repo *SigningKeyMongoRepo) Create(ctx context.Context, key *domain.SigningKey) error {
+	privateKey, err := repo.cipher.Encrypt(key.ID, key.PrivateKey)
+	if err != nil {
+		return errors.Wrapf(domain.ErrConversion, "%s: encrypt private key", signingKeyErrorPrefix)
+	}
+	keyDb := &signingKeyDB{
+		ID:                  key.ID,
+		Algorithm:           key.Algorithm,
+		EncryptedPrivateKey: privateKey,
+		CreatedAt:           key.CreatedAt,
+		ExpiresAt:           key.ExpiresAt,
+	}
+	cfg := config.Get()
+	_, err = repo.db.Client.Database(cfg.MongoDB).Collection(signingKeyTable).
+		InsertOne(ctx, keyDb)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", signingKeyErrorPrefix)
+	}
+	return nil
+}
+
+// GetActive returns keys not expired at the given time, newest first
+func (repo *SigningKeyMongoRepo) GetActive(ctx context.Context, now time.Time) ([]*domain.SigningKey, error) {
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(signingKeyTable).
+		Find(ctx,
+			bson.D{
+				{Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: now}}},
+				{Key: "encryptedPrivateKey", Value: bson.D{{Key: "$exists", Value: true}}},
+			},
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+		)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", signingKeyErrorPrefix)
+	}
+
+	keysDb := []*signingKeyDB{}
+	err = cursor.All(ctx, &keysDb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", signingKeyErrorPrefix)
+	}
+
+	keys := make([]*domain.SigningKey, 0, len(keysDb))
+	for _, keyDb := range keysDb {
+		signer, err := repo.cipher.Decrypt(keyDb.ID, keyDb.EncryptedPrivateKey)
+		if err != nil {
+			return nil, errors.Wrapf(domain.ErrConversion, "%s: decrypt private key %s", signingKeyErrorPrefix, keyDb.ID)
+		}
+		keys = append(keys, &domain.SigningKey{
+			ID:         keyDb.ID,
+			Algorithm:  keyDb.Algorithm,
+			PrivateKey: signer,
+			CreatedAt:  keyDb.CreatedAt,
+			ExpiresAt:  keyDb.ExpiresAt,
+		})
+	}
+	return keys, nil
+}
@@ -0,0 +1,208 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"slices"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	migrationErrorPrefix = "[repository.db.mongodb.migration]"
+)
+
+// MergeDuplicateWallets normalizes stored wallets to canonical form, keeps primary wallet among wallets
+// and merges users, which wallets turned out to be the same, into the oldest of them.
+// Sanctions, role changes and API keys of merged users pass to users they're merged into,
+// their tokens are revoked and sessions removed. Returns number of merged users.
+func MergeDuplicateWallets(ctx context.Context, db *DB) (int, error) {
+	cfg := config.Get()
+	database := db.Client.Database(cfg.MongoDB)
+
+	cursor, err := database.Collection(userTable).Find(ctx, bson.D{},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: find users", migrationErrorPrefix)
+	}
+	users := []*userDB{}
+	err = cursor.All(ctx, &users)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: find users", migrationErrorPrefix)
+	}
+
+	merge := planWalletMerge(users)
+
+	err = applyWalletMerge(ctx, &walletMergeMongoStore{database}, merge)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(merge.merged), nil
+}
+
+// walletMergeStore saves merge of users
+type walletMergeStore interface {
+	// updateWallets saves normalized and merged wallets of user
+	updateWallets(ctx context.Context, user *userDB) error
+	// moveRecords passes sanctions, role changes and API keys of duplicate user to survivor
+	moveRecords(ctx context.Context, id, survivorID string) error
+	// deleteMerged removes duplicate user, its tokens are revoked and sessions removed
+	deleteMerged(ctx context.Context, id string) error
+}
+
+// applyWalletMerge saves wallets of surviving users and moves records of duplicates
+// before duplicates are deleted, so failed merge loses nothing and running it again finishes it
+func applyWalletMerge(ctx context.Context, store walletMergeStore, merge *walletMerge) error {
+	for _, user := range merge.updated {
+		err := store.updateWallets(ctx, user)
+		if err != nil {
+			return errors.Wrapf(err, "%s: update user %s", migrationErrorPrefix, user.ID)
+		}
+	}
+
+	for id, survivorID := range merge.merged {
+		err := store.moveRecords(ctx, id, survivorID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: move records of user %s", migrationErrorPrefix, id)
+		}
+		err = store.deleteMerged(ctx, id)
+		if err != nil {
+			return errors.Wrapf(err, "%s: delete user %s", migrationErrorPrefix, id)
+		}
+	}
+
+	return nil
+}
+
+type walletMergeMongoStore struct {
+	database *mongo.Database
+}
+
+func (store *walletMergeMongoStore) updateWallets(ctx context.Context, user *userDB) error {
+	_, err := store.database.Collection(userTable).UpdateByID(ctx, user.ID, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "wallet", Value: user.Wallet},
+		{Key: "wallets", Value: user.Wallets},
+	}}})
+	return err
+}
+
+func (store *walletMergeMongoStore) moveRecords(ctx context.Context, id, survivorID string) error {
+	// ban of duplicate user bans its owner, so it must outlive the duplicate
+	moves := []struct {
+		table string
+		field string
+	}{
+		{sanctionTable, "userId"},
+		{roleChangeTable, "userId"},
+		{apiKeyTable, "createdBy"},
+	}
+	for _, move := range moves {
+		_, err := store.database.Collection(move.table).UpdateMany(ctx,
+			bson.D{{Key: move.field, Value: id}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: move.field, Value: survivorID}}}},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "move %s", move.table)
+		}
+	}
+	return nil
+}
+
+func (store *walletMergeMongoStore) deleteMerged(ctx context.Context, id string) error {
+	// tokens are revoked first, so user is deleted only once nothing lets its owner in
+	_, err := store.database.Collection(refreshTokenTable).UpdateMany(ctx,
+		bson.D{{Key: "userId", Value: id}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revoked", Value: true}}}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "revoke tokens")
+	}
+	_, err = store.database.Collection(sessionTable).DeleteMany(ctx, bson.D{{Key: "userId", Value: id}})
+	if err != nil {
+		return errors.Wrap(err, "delete sessions")
+	}
+	_, err = store.database.Collection(userTable).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	return err
+}
+
+type walletMerge struct {
+	// updated are users which wallets were normalized, rewritten to keep primary among them
+	// or got wallets of merged users
+	updated []*userDB
+	// merged maps id of duplicate user to id of user it's merged into
+	merged map[string]string
+}
+
+// planWalletMerge normalizes wallets of users sorted from the oldest and finds duplicates,
+// wallets which can't be normalized are kept as is
+func planWalletMerge(users []*userDB) *walletMerge {
+	res := &walletMerge{merged: map[string]string{}}
+
+	owners := map[string]*userDB{}
+	changed := map[string]bool{}
+	survivors := []*userDB{}
+
+	for _, user := range users {
+		accounts := []string{}
+		for _, wallet := range append([]string{user.Wallet}, user.Wallets...) {
+			if wallet == "" {
+				continue
+			}
+			account, err := domain.NormalizeAccount(wallet)
+			if err != nil {
+				account = wallet
+			}
+			if !slices.Contains(accounts, account) {
+				accounts = append(accounts, account)
+			}
+		}
+		if len(accounts) == 0 {
+			continue
+		}
+
+		var owner *userDB
+		for _, account := range accounts {
+			if owners[account] != nil {
+				owner = owners[account]
+				break
+			}
+		}
+
+		if owner == nil {
+			// primary wallet is kept among wallets, so unique index covers it too
+			if accounts[0] != user.Wallet || !slices.Equal(accounts, user.Wallets) {
+				changed[user.ID] = true
+			}
+			user.Wallet = accounts[0]
+			user.Wallets = accounts
+			for _, account := range accounts {
+				owners[account] = user
+			}
+			survivors = append(survivors, user)
+			continue
+		}
+
+		res.merged[user.ID] = owner.ID
+		for _, account := range accounts {
+			if owners[account] == nil {
+				owner.Wallets = append(owner.Wallets, account)
+				owners[account] = owner
+				changed[owner.ID] = true
+			}
+		}
+	}
+
+	for _, user := range survivors {
+		if changed[user.ID] {
+			res.updated = append(res.updated, user)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,178 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"server/internal/config"
+	"server/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanWalletMerge(t *testing.T) {
+	now := time.Now()
+
+	users := []*userDB{
+		{ID: "oldest", Wallet: "eip155:1:0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935", CreatedAt: now},
+		{ID: "lower", Wallet: "eip155:1:0xef209bee800ef5c7d20a67f46e007a970eaf9935", Wallets: []string{
+			"eip155:1:0x28e582ba14cd679fb08e47dc50b565c715ce0979",
+		}, CreatedAt: now.Add(time.Minute)},
+		{ID: "linked", Wallet: "eip155:1:0x28E582BA14CD679FB08E47DC50B565C715CE0979", CreatedAt: now.Add(2 * time.Minute)},
+		{ID: "normalized", Wallet: "eip155:1:0xef1c8b8c7f478c0be246735c06ae80bea3675d75", CreatedAt: now.Add(3 * time.Minute)},
+		{ID: "legacy", Wallet: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:5e6wK3rAqTgYg2TSBbXJTTvpvgjb6ZD3aTuDiuktW1eq", CreatedAt: now.Add(4 * time.Minute)},
+		{ID: "canonical", Wallet: "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV", Wallets: []string{
+			"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV",
+		}, CreatedAt: now.Add(5 * time.Minute)},
+	}
+
+	merge := planWalletMerge(users)
+
+	assert.Equal(t, map[string]string{"lower": "oldest", "linked": "oldest"}, merge.merged)
+
+	updated := map[string]*userDB{}
+	for _, user := range merge.updated {
+		updated[user.ID] = user
+	}
+	assert.Len(t, updated, 3)
+
+	assert.Equal(t, "eip155:1:0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935", updated["oldest"].Wallet)
+	assert.Equal(t, []string{
+		"eip155:1:0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935",
+		"eip155:1:0x28e582BA14CD679FB08E47dC50b565c715Ce0979",
+	}, updated["oldest"].Wallets)
+	assert.Equal(t, "eip155:1:0xeF1c8b8c7f478c0BE246735c06aE80BEA3675D75", updated["normalized"].Wallet)
+	assert.Equal(t, []string{"eip155:1:0xeF1c8b8c7f478c0BE246735c06aE80BEA3675D75"}, updated["normalized"].Wallets)
+	// primary wallet is kept among wallets
+	assert.Equal(t, []string{updated["legacy"].Wallet}, updated["legacy"].Wallets)
+}
+
+type walletMergeStoreStub struct {
+	updateErr error
+	moveErr   error
+	updated   []string
+	moved     []string
+	deleted   []string
+}
+
+func (store *walletMergeStoreStub) updateWallets(ctx context.Context, user *userDB) error {
+	if store.updateErr != nil {
+		return store.updateErr
+	}
+	store.updated = append(store.updated, user.ID)
+	return nil
+}
+
+func (store *walletMergeStoreStub) moveRecords(ctx context.Context, id, survivorID string) error {
+	if store.moveErr != nil {
+		return store.moveErr
+	}
+	store.moved = append(store.moved, id+"->"+survivorID)
+	return nil
+}
+
+func (store *walletMergeStoreStub) deleteMerged(ctx context.Context, id string) error {
+	store.deleted = append(store.deleted, id)
+	return nil
+}
+
+func TestApplyWalletMerge(t *testing.T) {
+	merge := &walletMerge{
+		updated: []*userDB{{ID: "oldest"}},
+		merged:  map[string]string{"lower": "oldest"},
+	}
+
+	testCases := []struct {
+		name      string
+		updateErr error
+		moveErr   error
+		updated   []string
+		moved     []string
+		deleted   []string
+	}{
+		{
+			name:    "merged",
+			updated: []string{"oldest"},
+			moved:   []string{"lower->oldest"},
+			deleted: []string{"lower"},
+		},
+		{
+			// wallets of duplicate are still stored, so running merge again finishes it
+			name:      "update fails",
+			updateErr: errors.New("update failed"),
+		},
+		{
+			// duplicate keeps its sanctions until they're moved, so it isn't deleted
+			name:    "move fails",
+			moveErr: errors.New("move failed"),
+			updated: []string{"oldest"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		store := &walletMergeStoreStub{updateErr: test.updateErr, moveErr: test.moveErr}
+		err := applyWalletMerge(context.Background(), store, merge)
+		switch {
+		case test.updateErr != nil:
+			assert.ErrorIs(t, err, test.updateErr)
+		case test.moveErr != nil:
+			assert.ErrorIs(t, err, test.moveErr)
+		default:
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, test.updated, store.updated)
+		assert.Equal(t, test.moved, store.moved)
+		assert.Equal(t, test.deleted, store.deleted)
+	}
+}
+
+func TestMergeDuplicateWallets_KeepsSanctions(t *testing.T) {
+	if config.Get().MongoURL == "" {
+		t.Skip("MONGODB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := Connect(ctx)
+	require.NoError(t, err)
+	defer db.Disconnect(ctx)
+	require.NoError(t, db.Bootstrap(ctx))
+
+	// the same address written in different case
+	address := make([]byte, 20)
+	_, err = rand.Read(address)
+	require.NoError(t, err)
+	lower := "eip155:1:0x" + hex.EncodeToString(address)
+	upper := "eip155:1:0x" + strings.ToUpper(hex.EncodeToString(address))
+
+	now := time.Now()
+	oldest := &userDB{ID: shortuuid.New(), Wallet: lower, CreatedAt: now, Role: string(domain.RolePlayer)}
+	duplicate := &userDB{ID: shortuuid.New(), Wallet: upper, CreatedAt: now.Add(time.Minute), Role: string(domain.RolePlayer)}
+	users := db.Client.Database(config.Get().MongoDB).Collection(userTable)
+	_, err = users.InsertMany(ctx, []any{oldest, duplicate})
+	require.NoError(t, err)
+
+	sanctionRepo := NewSanctionRepo(db)
+	ban := &domain.Sanction{
+		ID: shortuuid.New(), UserID: duplicate.ID, Type: domain.SanctionBan, Reason: "cheating",
+		IssuedBy: shortuuid.New(), CreatedAt: now,
+	}
+	require.NoError(t, sanctionRepo.Create(ctx, ban))
+
+	_, err = MergeDuplicateWallets(ctx, db)
+	require.NoError(t, err)
+
+	_, err = NewUserRepo(db).GetById(ctx, duplicate.ID)
+	assert.ErrorIs(t, err, domain.ErrNoDocuments)
+	active, err := sanctionRepo.GetActive(ctx, oldest.ID, time.Now())
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, ban.ID, active[0].ID)
+}
@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"server/internal/config"
 	"server/internal/domain"
+	"strings"
 )
 
 var (
@@ -51,3 +52,17 @@ func (db *DB) Ping(ctx context.Context) error {
 	}
 	return nil
 }
+
+// isDuplicateKey checks err is caused by unique index violation
+func isDuplicateKey(err error, index string) bool {
+	var writeException mongo.WriteException
+	if !errors.As(err, &writeException) {
+		return false
+	}
+	for _, writeError := range writeException.WriteErrors {
+		if writeError.Code == 11000 && strings.Contains(writeError.Message, index) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/repository"
+	"server/internal/repository/repositorytest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositories_Contract(t *testing.T) {
+	if config.Get().MongoURL == "" {
+		t.Skip("MONGODB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := Connect(ctx)
+	require.NoError(t, err)
+	defer db.Disconnect(ctx)
+
+	// the second run finds everything in place
+	require.NoError(t, db.Bootstrap(ctx))
+	require.NoError(t, db.Bootstrap(ctx))
+
+	repositorytest.Repositories(t, &repository.Repositories{
+		User:         NewUserRepo(db),
+		Nonce:        NewNonceRepo(db),
+		RefreshToken: NewRefreshTokenRepo(db),
+		Revocation:   NewRevocationRepo(db),
+		SigningKey:   NewSigningKeyRepo(db, repositorytest.KeyCipher(t)),
+		Session:      NewSessionRepo(db),
+		RoleChange:   NewRoleChangeRepo(db),
+		Sanction:     NewSanctionRepo(db),
+		APIKey:       NewAPIKeyRepo(db),
+	})
+}
@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	nonceTable       = "nonce"
+	nonceErrorPrefix = "[repository.db.mongodb.nonce]"
+)
+
+var _ repository.NonceRepository = (*NonceMongoRepo)(nil)
+
+type NonceMongoRepo struct {
+	db *DB
+}
+
+type nonceDB struct {
+	Value     string    `bson:"_id"`
+	Wallet    string    `bson:"wallet"`
+	Consumed  bool      `bson:"consumed"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	CreatedAt time.Time `bson:"createdAt,omitempty"`
+}
+
+func NewNonceRepo(db *DB) *NonceMongoRepo {
+	return &NonceMongoRepo{db}
+}
+
+func (repo *NonceMongoRepo) Create(ctx context.Context, nonce *domain.Nonce) error {
+	nonceDb := &nonceDB{
+		Value:     nonce.Value,
+		Wallet:    string(nonce.Wallet),
+		Consumed:  false,
+		ExpiresAt: nonce.ExpiresAt,
+		CreatedAt: nonce.CreatedAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(nonceTable).
+		InsertOne(ctx, nonceDb)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", nonceErrorPrefix)
+	}
+	return nil
+}
+
+// Consume marks nonce issued for wallet as used. Filter and update are applied
+// in a single operation, so a nonce can be consumed only once and only before it expires.
+func (repo *NonceMongoRepo) Consume(ctx context.Context, wallet domain.Address, value string) error {
+	filter := bson.D{
+		{Key: "_id", Value: value},
+		{Key: "wallet", Value: string(wallet)},
+		{Key: "consumed", Value: false},
+		{Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: time.Now()}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "consumed", Value: true}}}}
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(nonceTable).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrapf(err, "%s: consume", nonceErrorPrefix)
+	}
+	if result.ModifiedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: consume", nonceErrorPrefix)
+	}
+	return nil
+}
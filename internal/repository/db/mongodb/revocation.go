@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	revokedTokenTable     = "revoked_token"
+	revokedUserTable      = "revoked_user"
+	revocationErrorPrefix = "[repository.db.mongodb.revocation]"
+)
+
+var _ repository.RevocationRepository = (*RevocationMongoRepo)(nil)
+
+type RevocationMongoRepo struct {
+	db *DB
+}
+
+type revokedTokenDB struct {
+	ID        string    `bson:"_id"`
+	UserID    string    `bson:"userId"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+type revokedUserDB struct {
+	UserID    string    `bson:"_id"`
+	RevokedAt time.Time `bson:"revokedAt"`
+}
+
+func NewRevocationRepo(db *DB) *RevocationMongoRepo {
+	return &RevocationMongoRepo{db}
+}
+
+// RevokeAccessToken stores token id until token expires
+func (repo *RevocationMongoRepo) RevokeAccessToken(ctx context.Context, token *domain.AccessToken) error {
+	tokenDb := &revokedTokenDB{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(revokedTokenTable).
+		ReplaceOne(ctx, bson.D{{Key: "_id", Value: token.ID}}, tokenDb, options.Replace().SetUpsert(true))
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke access token", revocationErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RevocationMongoRepo) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	cfg := config.Get()
+	count, err := repo.db.Client.Database(cfg.MongoDB).Collection(revokedTokenTable).
+		CountDocuments(ctx, bson.D{{Key: "_id", Value: id}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: is access token revoked", revocationErrorPrefix)
+	}
+	return count > 0, nil
+}
+
+// RevokeUser revokes all user access tokens issued before revokedAt
+func (repo *RevocationMongoRepo) RevokeUser(ctx context.Context, userID string, revokedAt time.Time) error {
+	userDb := &revokedUserDB{
+		UserID:    userID,
+		RevokedAt: revokedAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(revokedUserTable).
+		ReplaceOne(ctx, bson.D{{Key: "_id", Value: userID}}, userDb, options.Replace().SetUpsert(true))
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke user", revocationErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RevocationMongoRepo) GetUserRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	userDb := &revokedUserDB{}
+	cfg := config.Get()
+	err := repo.db.Client.Database(cfg.MongoDB).Collection(revokedUserTable).
+		FindOne(ctx, bson.D{{Key: "_id", Value: userID}}).Decode(&userDb)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return time.Time{}, errors.Wrapf(domain.ErrNoDocuments, "%s: get user revoked at", revocationErrorPrefix)
+		}
+		return time.Time{}, errors.Wrapf(err, "%s: get user revoked at", revocationErrorPrefix)
+	}
+	return userDb.RevokedAt, nil
+}
+
+// DeleteByUser removes revoked access tokens and revocation of all tokens of user
+func (repo *RevocationMongoRepo) DeleteByUser(ctx context.Context, userID string) error {
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(revokedTokenTable).
+		DeleteMany(ctx, bson.D{{Key: "userId", Value: userID}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", revocationErrorPrefix)
+	}
+	_, err = repo.db.Client.Database(cfg.MongoDB).Collection(revokedUserTable).
+		DeleteOne(ctx, bson.D{{Key: "_id", Value: userID}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", revocationErrorPrefix)
+	}
+	return nil
+}
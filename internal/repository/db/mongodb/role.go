@@ -0,0 +1,111 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	roleChangeTable       = "role_change"
+	roleChangeErrorPrefix = "[repository.db.mongodb.role]"
+)
+
+var _ repository.RoleChangeRepository = (*RoleChangeMongoRepo)(nil)
+
+type RoleChangeMongoRepo struct {
+	db *DB
+}
+
+type roleChangeDB struct {
+	ID           string    `bson:"_id"`
+	UserID       string    `bson:"userId"`
+	Role         string    `bson:"role"`
+	PreviousRole string    `bson:"previousRole"`
+	ChangedBy    string    `bson:"changedBy"`
+	CreatedAt    time.Time `bson:"createdAt"`
+}
+
+func NewRoleChangeRepo(db *DB) *RoleChangeMongoRepo {
+	return &RoleChangeMongoRepo{db}
+}
+
+func (repo *RoleChangeMongoRepo) Create(ctx context.Context, change *domain.RoleChange) error {
+	roleChangeDb := &roleChangeDB{
+		ID:           change.ID,
+		UserID:       change.UserID,
+		Role:         string(change.Role),
+		PreviousRole: string(change.PreviousRole),
+		ChangedBy:    change.ChangedBy,
+		CreatedAt:    change.CreatedAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(roleChangeTable).
+		InsertOne(ctx, roleChangeDb)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", roleChangeErrorPrefix)
+	}
+	return nil
+}
+
+// GetByUser returns role changes of user, newest first
+func (repo *RoleChangeMongoRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RoleChange, error) {
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(roleChangeTable).
+		Find(ctx,
+			bson.D{{Key: "userId", Value: userID}},
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+		)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", roleChangeErrorPrefix)
+	}
+
+	roleChangesDb := []*roleChangeDB{}
+	err = cursor.All(ctx, &roleChangesDb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", roleChangeErrorPrefix)
+	}
+
+	changes := make([]*domain.RoleChange, 0, len(roleChangesDb))
+	for _, roleChangeDb := range roleChangesDb {
+		changes = append(changes, &domain.RoleChange{
+			ID:           roleChangeDb.ID,
+			UserID:       roleChangeDb.UserID,
+			Role:         domain.Role(roleChangeDb.Role),
+			PreviousRole: domain.Role(roleChangeDb.PreviousRole),
+			ChangedBy:    roleChangeDb.ChangedBy,
+			CreatedAt:    roleChangeDb.CreatedAt,
+		})
+	}
+	return changes, nil
+}
+
+func (repo *RoleChangeMongoRepo) DeleteByUser(ctx context.Context, userID string) error {
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(roleChangeTable).
+		DeleteMany(ctx, bson.D{{Key: "userId", Value: userID}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", roleChangeErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces author of role changes made by user with anonymous one
+func (repo *RoleChangeMongoRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(roleChangeTable).
+		UpdateMany(ctx,
+			bson.D{{Key: "changedBy", Value: userID}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "changedBy", Value: anonymous}}}},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", roleChangeErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	sanctionTable       = "sanction"
+	sanctionErrorPrefix = "[repository.db.mongodb.sanction]"
+)
+
+var _ repository.SanctionRepository = (*SanctionMongoRepo)(nil)
+
+type SanctionMongoRepo struct {
+	db *DB
+}
+
+type sanctionDB struct {
+	ID        string    `bson:"_id"`
+	UserID    string    `bson:"userId"`
+	Type      string    `bson:"type"`
+	Reason    string    `bson:"reason"`
+	IssuedBy  string    `bson:"issuedBy"`
+	CreatedAt time.Time `bson:"createdAt"`
+	ExpiresAt time.Time `bson:"expiresAt,omitempty"`
+	LiftedBy  string    `bson:"liftedBy,omitempty"`
+	LiftedAt  time.Time `bson:"liftedAt,omitempty"`
+}
+
+func NewSanctionRepo(db *DB) *SanctionMongoRepo {
+	return &SanctionMongoRepo{db}
+}
+
+func (repo *SanctionMongoRepo) Create(ctx context.Context, sanction *domain.Sanction) error {
+	sanctionDb := &sanctionDB{
+		ID:        sanction.ID,
+		UserID:    sanction.UserID,
+		Type:      string(sanction.Type),
+		Reason:    sanction.Reason,
+		IssuedBy:  sanction.IssuedBy,
+		CreatedAt: sanction.CreatedAt,
+		ExpiresAt: sanction.ExpiresAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(sanctionTable).
+		InsertOne(ctx, sanctionDb)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionMongoRepo) GetById(ctx context.Context, id string) (*domain.Sanction, error) {
+	sanctions, err := repo.find(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id", sanctionErrorPrefix)
+	}
+	if len(sanctions) == 0 {
+		return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by id", sanctionErrorPrefix)
+	}
+	return sanctions[0], nil
+}
+
+// GetByUser returns all sanctions of user, newest first
+func (repo *SanctionMongoRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Sanction, error) {
+	sanctions, err := repo.find(ctx, bson.D{{Key: "userId", Value: userID}})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sanctionErrorPrefix)
+	}
+	return sanctions, nil
+}
+
+// GetActive returns sanctions of user neither lifted nor expired at now, newest first
+func (repo *SanctionMongoRepo) GetActive(ctx context.Context, userID string, now time.Time) ([]*domain.Sanction, error) {
+	sanctions, err := repo.find(ctx, bson.D{
+		{Key: "userId", Value: userID},
+		{Key: "liftedAt", Value: bson.D{{Key: "$exists", Value: false}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: now}}}},
+		}},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", sanctionErrorPrefix)
+	}
+	return sanctions, nil
+}
+
+// Lift ends sanction before it expires, lifted sanction can't be lifted again
+func (repo *SanctionMongoRepo) Lift(ctx context.Context, id, liftedBy string, liftedAt time.Time) error {
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(sanctionTable).
+		UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: id}, {Key: "liftedAt", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "liftedBy", Value: liftedBy},
+				{Key: "liftedAt", Value: liftedAt},
+			}}},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: lift", sanctionErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: lift", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionMongoRepo) DeleteByUser(ctx context.Context, userID string) error {
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(sanctionTable).
+		DeleteMany(ctx, bson.D{{Key: "userId", Value: userID}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces moderator who issued or lifted sanctions with anonymous one
+func (repo *SanctionMongoRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	cfg := config.Get()
+	collection := repo.db.Client.Database(cfg.MongoDB).Collection(sanctionTable)
+	_, err := collection.UpdateMany(ctx,
+		bson.D{{Key: "issuedBy", Value: userID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "issuedBy", Value: anonymous}}}},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", sanctionErrorPrefix)
+	}
+	_, err = collection.UpdateMany(ctx,
+		bson.D{{Key: "liftedBy", Value: userID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "liftedBy", Value: anonymous}}}},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionMongoRepo) find(ctx context.Context, filter bson.D) ([]*domain.Sanction, error) {
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(sanctionTable).
+		Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	sanctionsDb := []*sanctionDB{}
+	err = cursor.All(ctx, &sanctionsDb)
+	if err != nil {
+		return nil, err
+	}
+
+	sanctions := make([]*domain.Sanction, 0, len(sanctionsDb))
+	for _, sanctionDb := range sanctionsDb {
+		sanctions = append(sanctions, &domain.Sanction{
+			ID:        sanctionDb.ID,
+			UserID:    sanctionDb.UserID,
+			Type:      domain.SanctionType(sanctionDb.Type),
+			Reason:    sanctionDb.Reason,
+			IssuedBy:  sanctionDb.IssuedBy,
+			CreatedAt: sanctionDb.CreatedAt,
+			ExpiresAt: sanctionDb.ExpiresAt,
+			LiftedBy:  sanctionDb.LiftedBy,
+			LiftedAt:  sanctionDb.LiftedAt,
+		})
+	}
+	return sanctions, nil
+}
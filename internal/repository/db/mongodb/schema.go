@@ -0,0 +1,193 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	schemaErrorPrefix = "[repository.db.mongodb.schema]"
+)
+
+const (
+	// namespaceExists is returned when collection is created by another instance meanwhile
+	namespaceExists = 48
+	// indexOptionsConflict is returned when index with the same name exists with other options
+	indexOptionsConflict = 85
+)
+
+// collection is collection storage expects with indexes it must have
+type collection struct {
+	name    string
+	indexes []mongo.IndexModel
+}
+
+// uniqueIndex makes field unique among documents having it
+func uniqueIndex(name, field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: field, Value: 1}},
+		Options: options.Index().
+			SetName(name).
+			SetUnique(true).
+			SetPartialFilterExpression(bson.D{{Key: field, Value: bson.D{{Key: "$type", Value: "string"}}}}),
+	}
+}
+
+// ttlIndex removes document once ttl passes after time in field
+func ttlIndex(name, field string, ttl time.Duration) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: options.Index().SetName(name).SetExpireAfterSeconds(int32(ttl.Seconds())),
+	}
+}
+
+// index speeds up queries by field
+func index(name, field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: options.Index().SetName(name),
+	}
+}
+
+// schema returns collections and indexes of storage
+func schema(cfg *config.Config) []collection {
+	nickname := uniqueIndex(userNicknameIndex, "nickname")
+	// nickname prefix search uses the index as well, so search queries must have the same collation
+	nickname.Options.SetCollation(userNicknameCollation)
+
+	return []collection{
+		{userTable, []mongo.IndexModel{
+			nickname,
+			uniqueIndex(userDeviceIndex, "device"),
+			// concurrent logins with new wallet can't create two users,
+			// primary wallet is kept among wallets, so it can't be linked wallet of another user either
+			uniqueIndex(userWalletIndex, "wallet"),
+			uniqueIndex(userWalletsIndex, "wallets"),
+		}},
+		{nonceTable, []mongo.IndexModel{
+			ttlIndex("expires_at_ttl", "expiresAt", 0),
+		}},
+		{refreshTokenTable, []mongo.IndexModel{
+			index("family", "family"),
+			index("user_id", "userId"),
+			ttlIndex("expires_at_ttl", "expiresAt", 0),
+		}},
+		{revokedTokenTable, []mongo.IndexModel{
+			index("user_id", "userId"),
+			ttlIndex("expires_at_ttl", "expiresAt", 0),
+		}},
+		{revokedUserTable, nil},
+		{signingKeyTable, nil},
+		{sessionTable, []mongo.IndexModel{
+			index("user_id", "userId"),
+			// session is refreshed with its refresh token, so it's dead once the last token issued expires
+			ttlIndex("last_seen_at_ttl", "lastSeenAt", cfg.RefreshTokenTTL),
+		}},
+		{roleChangeTable, []mongo.IndexModel{
+			index("user_id", "userId"),
+		}},
+		{sanctionTable, []mongo.IndexModel{
+			index("user_id", "userId"),
+		}},
+		{apiKeyTable, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "hash", Value: 1}},
+				Options: options.Index().SetName(apiKeyHashIndex).SetUnique(true),
+			},
+		}},
+	}
+}
+
+// legacyUsers matches users stored by older versions, which wallets aren't namespaced
+// or which primary wallet isn't kept among wallets
+var legacyUsers = bson.D{{Key: "$or", Value: bson.A{
+	bson.D{{Key: "wallet", Value: bson.D{{Key: "$regex", Value: "^0x"}}}},
+	bson.D{{Key: "wallets", Value: bson.D{{Key: "$regex", Value: "^0x"}}}},
+	bson.D{
+		{Key: "wallet", Value: bson.D{{Key: "$type", Value: "string"}}},
+		{Key: "$expr", Value: bson.D{{Key: "$not", Value: bson.A{
+			bson.D{{Key: "$in", Value: bson.A{"$wallet", bson.D{{Key: "$ifNull", Value: bson.A{"$wallets", bson.A{}}}}}}},
+		}}}},
+	},
+}}}
+
+// Bootstrap creates missing collections and indexes, running it again changes nothing.
+// Expiration of TTL index follows config, other changed indexes have to be dropped by hand.
+// Refuses to start while users stored by older versions remain, since unique wallet indexes
+// don't hold for them, cmd/migrate rewrites them and merges users sharing wallet.
+// Signing keys older versions stored in plain are removed, a new encrypted key is created on start
+// and tokens they signed stop being accepted and are refreshed.
+func (db *DB) Bootstrap(ctx context.Context) error {
+	cfg := config.Get()
+	database := db.Client.Database(cfg.MongoDB)
+
+	legacy, err := database.Collection(userTable).CountDocuments(ctx, legacyUsers, options.Count().SetLimit(1))
+	if err != nil {
+		return errors.Wrapf(err, "%s: find legacy users", schemaErrorPrefix)
+	}
+	if legacy > 0 {
+		return errors.Wrapf(domain.ErrConfig, "%s: users stored by older version, run cmd/migrate", schemaErrorPrefix)
+	}
+
+	_, err = database.Collection(signingKeyTable).DeleteMany(ctx, plainSigningKeys)
+	if err != nil {
+		return errors.Wrapf(err, "%s: remove plain signing keys", schemaErrorPrefix)
+	}
+
+	existing, err := database.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return errors.Wrapf(err, "%s: list collections", schemaErrorPrefix)
+	}
+
+	for _, c := range schema(cfg) {
+		if !slices.Contains(existing, c.name) {
+			err = database.CreateCollection(ctx, c.name)
+			var commandErr mongo.CommandError
+			if err != nil && !(errors.As(err, &commandErr) && commandErr.Code == namespaceExists) {
+				return errors.Wrapf(err, "%s: create collection %s", schemaErrorPrefix, c.name)
+			}
+		}
+		for _, model := range c.indexes {
+			err = createIndex(ctx, database.Collection(c.name), model)
+			if isWalletIndex(c.name, *model.Options.Name) && mongo.IsDuplicateKeyError(err) {
+				return errors.Wrapf(err, "%s: create index %s.%s: users share wallet, merge them with cmd/migrate",
+					schemaErrorPrefix, c.name, *model.Options.Name)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "%s: create index %s.%s", schemaErrorPrefix, c.name, *model.Options.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isWalletIndex reports unique index of user wallets
+func isWalletIndex(collection, index string) bool {
+	return collection == userTable && (index == userWalletIndex || index == userWalletsIndex)
+}
+
+// createIndex creates index unless it exists, expiration of existing TTL index is updated in place
+func createIndex(ctx context.Context, collection *mongo.Collection, model mongo.IndexModel) error {
+	_, err := collection.Indexes().CreateOne(ctx, model)
+	var commandErr mongo.CommandError
+	if model.Options.ExpireAfterSeconds == nil || !errors.As(err, &commandErr) || commandErr.Code != indexOptionsConflict {
+		return err
+	}
+
+	return collection.Database().RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection.Name()},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: *model.Options.Name},
+			{Key: "expireAfterSeconds", Value: *model.Options.ExpireAfterSeconds},
+		}},
+	}).Err()
+}
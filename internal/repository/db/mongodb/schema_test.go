@@ -0,0 +1,43 @@
+package mongodb
+
+import (
+	"server/internal/config"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSchema(t *testing.T) {
+	cfg := &config.Config{RefreshTokenTTL: 720 * time.Hour}
+
+	collections := map[string]bool{}
+	for _, c := range schema(cfg) {
+		assert.False(t, collections[c.name], "collection %s declared twice", c.name)
+		collections[c.name] = true
+
+		names := map[string]bool{}
+		for _, model := range c.indexes {
+			// existing index is found by name, so name must be stable
+			if assert.NotNil(t, model.Options.Name, "index of %s has no name", c.name) {
+				assert.False(t, names[*model.Options.Name], "index %s.%s declared twice", c.name, *model.Options.Name)
+				names[*model.Options.Name] = true
+			}
+		}
+	}
+
+	assert.True(t, collections[userTable])
+	assert.True(t, collections[nonceTable])
+	assert.True(t, collections[sessionTable])
+
+	// sessions live as long as refresh tokens
+	i := slices.IndexFunc(schema(cfg), func(c collection) bool { return c.name == sessionTable })
+	require.GreaterOrEqual(t, i, 0)
+	session := schema(cfg)[i]
+	j := slices.IndexFunc(session.indexes, func(model mongo.IndexModel) bool { return *model.Options.Name == "last_seen_at_ttl" })
+	require.GreaterOrEqual(t, j, 0)
+	assert.Equal(t, int32(cfg.RefreshTokenTTL.Seconds()), *session.indexes[j].Options.ExpireAfterSeconds)
+}
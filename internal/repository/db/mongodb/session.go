@@ -0,0 +1,140 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	sessionTable       = "session"
+	sessionErrorPrefix = "[repository.db.mongodb.session]"
+)
+
+var _ repository.SessionRepository = (*SessionMongoRepo)(nil)
+
+type SessionMongoRepo struct {
+	db *DB
+}
+
+type sessionDB struct {
+	ID         string    `bson:"_id"`
+	UserID     string    `bson:"userId"`
+	UserAgent  string    `bson:"userAgent"`
+	IP         string    `bson:"ip"`
+	CreatedAt  time.Time `bson:"createdAt"`
+	LastSeenAt time.Time `bson:"lastSeenAt"`
+}
+
+func NewSessionRepo(db *DB) *SessionMongoRepo {
+	return &SessionMongoRepo{db}
+}
+
+func (repo *SessionMongoRepo) Create(ctx context.Context, session *domain.Session) error {
+	sessionDb := &sessionDB{
+		ID:         session.ID,
+		UserID:     session.UserID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(sessionTable).
+		InsertOne(ctx, sessionDb)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", sessionErrorPrefix)
+	}
+	return nil
+}
+
+// Touch updates session device and last seen time
+func (repo *SessionMongoRepo) Touch(ctx context.Context, id string, device *domain.Device, lastSeenAt time.Time) error {
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "userAgent", Value: device.UserAgent},
+		{Key: "ip", Value: device.IP},
+		{Key: "lastSeenAt", Value: lastSeenAt},
+	}}}
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(sessionTable).
+		UpdateByID(ctx, id, update)
+	if err != nil {
+		return errors.Wrapf(err, "%s: touch", sessionErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: touch", sessionErrorPrefix)
+	}
+	return nil
+}
+
+// GetByUser returns user sessions, recently seen first
+func (repo *SessionMongoRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(sessionTable).
+		Find(ctx,
+			bson.D{{Key: "userId", Value: userID}},
+			options.Find().SetSort(bson.D{{Key: "lastSeenAt", Value: -1}}),
+		)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sessionErrorPrefix)
+	}
+
+	sessionsDb := []*sessionDB{}
+	err = cursor.All(ctx, &sessionsDb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sessionErrorPrefix)
+	}
+
+	sessions := make([]*domain.Session, 0, len(sessionsDb))
+	for _, sessionDb := range sessionsDb {
+		sessions = append(sessions, &domain.Session{
+			ID:         sessionDb.ID,
+			UserID:     sessionDb.UserID,
+			UserAgent:  sessionDb.UserAgent,
+			IP:         sessionDb.IP,
+			CreatedAt:  sessionDb.CreatedAt,
+			LastSeenAt: sessionDb.LastSeenAt,
+		})
+	}
+	return sessions, nil
+}
+
+// Delete removes session only if it belongs to user
+func (repo *SessionMongoRepo) Exists(ctx context.Context, userID, id string) (bool, error) {
+	cfg := config.Get()
+	count, err := repo.db.Client.Database(cfg.MongoDB).Collection(sessionTable).
+		CountDocuments(ctx, bson.D{{Key: "_id", Value: id}, {Key: "userId", Value: userID}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: exists", sessionErrorPrefix)
+	}
+	return count > 0, nil
+}
+
+func (repo *SessionMongoRepo) Delete(ctx context.Context, userID, id string) error {
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(sessionTable).
+		DeleteOne(ctx, bson.D{{Key: "_id", Value: id}, {Key: "userId", Value: userID}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete", sessionErrorPrefix)
+	}
+	if result.DeletedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: delete", sessionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SessionMongoRepo) DeleteByUser(ctx context.Context, userID string) error {
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(sessionTable).
+		DeleteMany(ctx, bson.D{{Key: "userId", Value: userID}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", sessionErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package mongodb
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	refreshTokenTable       = "refresh_token"
+	refreshTokenErrorPrefix = "[repository.db.mongodb.token]"
+)
+
+var _ repository.RefreshTokenRepository = (*RefreshTokenMongoRepo)(nil)
+
+type RefreshTokenMongoRepo struct {
+	db *DB
+}
+
+type refreshTokenDB struct {
+	Hash      string    `bson:"_id"`
+	Family    string    `bson:"family"`
+	UserID    string    `bson:"userId"`
+	Wallet    string    `bson:"wallet"`
+	Used      bool      `bson:"used"`
+	Revoked   bool      `bson:"revoked"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	CreatedAt time.Time `bson:"createdAt,omitempty"`
+}
+
+func NewRefreshTokenRepo(db *DB) *RefreshTokenMongoRepo {
+	return &RefreshTokenMongoRepo{db}
+}
+
+func (repo *RefreshTokenMongoRepo) Create(ctx context.Context, token *domain.RefreshToken) error {
+	tokenDb := &refreshTokenDB{
+		Hash:      token.Hash,
+		Family:    token.Family,
+		UserID:    token.UserID,
+		Wallet:    token.Wallet,
+		Used:      token.Used,
+		Revoked:   token.Revoked,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(refreshTokenTable).
+		InsertOne(ctx, tokenDb)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenMongoRepo) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	tokenDb := &refreshTokenDB{}
+	cfg := config.Get()
+	err := repo.db.Client.Database(cfg.MongoDB).Collection(refreshTokenTable).
+		FindOne(ctx, bson.D{{Key: "_id", Value: hash}}).Decode(&tokenDb)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", refreshTokenErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", refreshTokenErrorPrefix)
+	}
+	return &domain.RefreshToken{
+		Hash:      tokenDb.Hash,
+		Family:    tokenDb.Family,
+		UserID:    tokenDb.UserID,
+		Wallet:    tokenDb.Wallet,
+		Used:      tokenDb.Used,
+		Revoked:   tokenDb.Revoked,
+		ExpiresAt: tokenDb.ExpiresAt,
+		CreatedAt: tokenDb.CreatedAt,
+	}, nil
+}
+
+// GetByUser returns refresh tokens of user, recently created first
+func (repo *RefreshTokenMongoRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RefreshToken, error) {
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(refreshTokenTable).
+		Find(ctx,
+			bson.D{{Key: "userId", Value: userID}},
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+		)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", refreshTokenErrorPrefix)
+	}
+
+	tokensDb := []*refreshTokenDB{}
+	err = cursor.All(ctx, &tokensDb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", refreshTokenErrorPrefix)
+	}
+
+	tokens := make([]*domain.RefreshToken, 0, len(tokensDb))
+	for _, tokenDb := range tokensDb {
+		tokens = append(tokens, &domain.RefreshToken{
+			Hash:      tokenDb.Hash,
+			Family:    tokenDb.Family,
+			UserID:    tokenDb.UserID,
+			Wallet:    tokenDb.Wallet,
+			Used:      tokenDb.Used,
+			Revoked:   tokenDb.Revoked,
+			ExpiresAt: tokenDb.ExpiresAt,
+			CreatedAt: tokenDb.CreatedAt,
+		})
+	}
+	return tokens, nil
+}
+
+// MarkUsed flags token as rotated, only one caller can succeed for a token
+func (repo *RefreshTokenMongoRepo) MarkUsed(ctx context.Context, hash string) error {
+	filter := bson.D{
+		{Key: "_id", Value: hash},
+		{Key: "used", Value: false},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "used", Value: true}}}}
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(refreshTokenTable).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrapf(err, "%s: mark used", refreshTokenErrorPrefix)
+	}
+	if result.ModifiedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: mark used", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenMongoRepo) RevokeFamily(ctx context.Context, family string) error {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "revoked", Value: true}}}}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(refreshTokenTable).
+		UpdateMany(ctx, bson.D{{Key: "family", Value: family}}, update)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke family", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenMongoRepo) RevokeUser(ctx context.Context, userID string) error {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "revoked", Value: true}}}}
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(refreshTokenTable).
+		UpdateMany(ctx, bson.D{{Key: "userId", Value: userID}}, update)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke user", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenMongoRepo) DeleteByUser(ctx context.Context, userID string) error {
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(refreshTokenTable).
+		DeleteMany(ctx, bson.D{{Key: "userId", Value: userID}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", refreshTokenErrorPrefix)
+	}
+	return nil
+}
@@ -2,15 +2,15 @@ package mongodb
 
 import (
 	"context"
-	"fmt"
 	"server/internal/config"
 	"server/internal/domain"
+	"server/internal/repository"
 	"time"
 
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var (
@@ -18,15 +18,99 @@ var (
 	userErrorPrefix = "[repository.db.mongodb.user]"
 )
 
+var _ repository.UserRepository = (*UserMongoRepo)(nil)
+
 type UserMongoRepo struct {
 	db *DB
 }
 
 type userDB struct {
-	ID        string    `bson:"_id,omitempty"`
-	Nickname  string    `bson:"nickname,omitempty"`
-	Wallet    string    `bson:"wallet,omitempty"`
+	ID       string `bson:"_id,omitempty"`
+	Nickname string `bson:"nickname,omitempty"`
+	Wallet   string `bson:"wallet,omitempty"`
+	// Wallets are all wallets of user, primary one first followed by linked ones,
+	// so one unique index keeps wallet from belonging to two users either way
+	Wallets   []string  `bson:"wallets,omitempty"`
 	CreatedAt time.Time `bson:"createdAt,omitempty"`
+	Role      string    `bson:"role,omitempty"`
+
+	NicknameChangedAt time.Time `bson:"nicknameChangedAt,omitempty"`
+
+	Avatar  avatarDB  `bson:"avatar,omitempty"`
+	Bio     string    `bson:"bio,omitempty"`
+	Country string    `bson:"country,omitempty"`
+	Privacy privacyDB `bson:"privacy,omitempty"`
+
+	DeleteRequestedAt time.Time `bson:"deleteRequestedAt,omitempty"`
+	PurgeStartedAt    time.Time `bson:"purgeStartedAt,omitempty"`
+
+	Device string `bson:"device,omitempty"`
+}
+
+type avatarDB struct {
+	URL string `bson:"url,omitempty"`
+	NFT string `bson:"nft,omitempty"`
+}
+
+type privacyDB struct {
+	Private     bool `bson:"private,omitempty"`
+	HideCountry bool `bson:"hideCountry,omitempty"`
+	ShowWallet  bool `bson:"showWallet,omitempty"`
+}
+
+// userNicknameIndex makes nicknames unique ignoring case, users without nickname are not indexed.
+// Nickname prefix search uses it as well, so search queries must have the same collation.
+const userNicknameIndex = "nickname_unique"
+
+// userDeviceIndex binds guest to one device, users with wallet have no device and are not indexed
+const userDeviceIndex = "device_unique"
+
+// userWalletIndex makes primary wallets unique, userWalletsIndex makes wallets unique whether they're
+// primary or linked, since primary wallet is kept among wallets too. Guests are not indexed.
+const (
+	userWalletIndex  = "wallet_unique"
+	userWalletsIndex = "wallets_unique"
+)
+
+// userNicknameCollation compares nicknames ignoring case
+var userNicknameCollation = &options.Collation{Locale: "en", Strength: 2}
+
+func (u *userDB) domain() *domain.User {
+	// users created before roles were introduced are players
+	role := domain.RolePlayer
+	if u.Role != "" {
+		role = domain.Role(u.Role)
+	}
+	var linked []string
+	for _, wallet := range u.Wallets {
+		if wallet != u.Wallet {
+			linked = append(linked, wallet)
+		}
+	}
+	return &domain.User{
+		ID:        u.ID,
+		Nickname:  u.Nickname,
+		Wallet:    u.Wallet,
+		Wallets:   linked,
+		CreatedAt: u.CreatedAt,
+		Role:      role,
+
+		NicknameChangedAt: u.NicknameChangedAt,
+
+		Avatar:  domain.Avatar{URL: u.Avatar.URL, NFT: u.Avatar.NFT},
+		Bio:     u.Bio,
+		Country: u.Country,
+		Privacy: domain.Privacy{
+			Private:     u.Privacy.Private,
+			HideCountry: u.Privacy.HideCountry,
+			ShowWallet:  u.Privacy.ShowWallet,
+		},
+
+		DeleteRequestedAt: u.DeleteRequestedAt,
+		PurgeStartedAt:    u.PurgeStartedAt,
+
+		Device: u.Device,
+	}
 }
 
 func NewUserRepo(db *DB) *UserMongoRepo {
@@ -34,86 +118,161 @@ func NewUserRepo(db *DB) *UserMongoRepo {
 }
 
 func (repo *UserMongoRepo) GetById(ctx context.Context, id string) (*domain.User, error) {
-	objId, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return nil, errors.Wrapf(domain.ErrConversion, "%s: user id from hex", userErrorPrefix)
-	}
 	userDb := &userDB{}
 	cfg := config.Get()
-	err = repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
-		FindOne(ctx, bson.D{{Key: "_id", Value: objId}}).Decode(&userDb)
+	err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&userDb)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by id", userErrorPrefix)
 		}
 		return nil, errors.Wrapf(err, "%s: get by id", userErrorPrefix)
 	}
-	return &domain.User{
-		ID:        userDb.ID,
-		Nickname:  userDb.Nickname,
-		Wallet:    userDb.Wallet,
-		CreatedAt: userDb.CreatedAt,
-	}, nil
+	return userDb.domain(), nil
 }
 
+// GetByWallet returns user by primary or linked wallet
 func (repo *UserMongoRepo) GetByWallet(ctx context.Context, wallet string) (*domain.User, error) {
 	userDb := &userDB{}
 	cfg := config.Get()
 	err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
-		FindOne(ctx, bson.D{{Key: "wallet", Value: wallet}}).Decode(&userDb)
+		FindOne(ctx, bson.D{{Key: "wallets", Value: wallet}}).Decode(&userDb)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by wallet", userErrorPrefix)
 		}
 		return nil, errors.Wrapf(err, "%s: get by wallet", userErrorPrefix)
 	}
-	return &domain.User{
-		ID:        userDb.ID,
-		Nickname:  userDb.Nickname,
-		Wallet:    userDb.Wallet,
-		CreatedAt: userDb.CreatedAt,
-	}, nil
+	return userDb.domain(), nil
+}
+
+// GetByDevice returns guest bound to device
+func (repo *UserMongoRepo) GetByDevice(ctx context.Context, device string) (*domain.User, error) {
+	userDb := &userDB{}
+	cfg := config.Get()
+	err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		FindOne(ctx, bson.D{{Key: "device", Value: device}}).Decode(&userDb)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by device", userErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by device", userErrorPrefix)
+	}
+	return userDb.domain(), nil
+}
+
+// Search returns users with nickname starting with prefix ignoring case, ordered by nickname.
+// Page starts after nickname after, from the first user when empty.
+func (repo *UserMongoRepo) Search(ctx context.Context, prefix, after string, limit int) ([]*domain.User, error) {
+	nickname := bson.D{{Key: "$type", Value: "string"}}
+	if after != "" {
+		nickname = append(nickname, bson.E{Key: "$gt", Value: after})
+	}
+	if prefix != "" {
+		// U+FFFF collates after any character, so range covers all nicknames with prefix
+		if after == "" {
+			nickname = append(nickname, bson.E{Key: "$gte", Value: prefix})
+		}
+		nickname = append(nickname, bson.E{Key: "$lt", Value: prefix + "\uffff"})
+	}
+
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		Find(ctx,
+			bson.D{
+				{Key: "nickname", Value: nickname},
+				// users pending deletion are hidden
+				{Key: "deleteRequestedAt", Value: bson.D{{Key: "$exists", Value: false}}},
+			},
+			options.Find().
+				SetSort(bson.D{{Key: "nickname", Value: 1}}).
+				SetCollation(userNicknameCollation).
+				SetLimit(int64(limit)),
+		)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: search", userErrorPrefix)
+	}
+
+	usersDb := []*userDB{}
+	err = cursor.All(ctx, &usersDb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: search", userErrorPrefix)
+	}
+
+	users := make([]*domain.User, 0, len(usersDb))
+	for _, userDb := range usersDb {
+		users = append(users, userDb.domain())
+	}
+	return users, nil
 }
 
 func (repo *UserMongoRepo) Create(ctx context.Context, user *domain.User) error {
+	wallets := user.Wallets
+	if user.Wallet != "" {
+		wallets = append([]string{user.Wallet}, wallets...)
+	}
 	userDb := &userDB{
 		ID:        user.ID,
 		Nickname:  user.Nickname,
 		Wallet:    user.Wallet,
+		Wallets:   wallets,
 		CreatedAt: user.CreatedAt,
+		Role:      string(user.Role),
+		Device:    user.Device,
 	}
 	cfg := config.Get()
-	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
 		InsertOne(ctx, userDb)
-	fmt.Println(result)
 	if err != nil {
+		if isDuplicateKey(err, userNicknameIndex) {
+			return errors.Wrapf(domain.ErrNicknameTaken, "%s: create", userErrorPrefix)
+		}
+		// user with the same id, wallet or device exists
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: create", userErrorPrefix)
+		}
 		return errors.Wrapf(err, "%s: create", userErrorPrefix)
 	}
 	return nil
 }
 
+// Update saves profile fields of user, wallets are changed by dedicated methods
 func (repo *UserMongoRepo) Update(ctx context.Context, user *domain.User) error {
-	userDb := &userDB{
-		Nickname:  user.Nickname,
-		Wallet:    user.Wallet,
-		CreatedAt: user.CreatedAt,
-	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "nickname", Value: user.Nickname},
+		{Key: "nicknameChangedAt", Value: user.NicknameChangedAt},
+		{Key: "avatar", Value: avatarDB{URL: user.Avatar.URL, NFT: user.Avatar.NFT}},
+		{Key: "bio", Value: user.Bio},
+		{Key: "country", Value: user.Country},
+		{Key: "privacy", Value: privacyDB{
+			Private:     user.Privacy.Private,
+			HideCountry: user.Privacy.HideCountry,
+			ShowWallet:  user.Privacy.ShowWallet,
+		}},
+	}}}
 	cfg := config.Get()
 	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
-		UpdateByID(ctx, user.ID, userDb)
+		UpdateByID(ctx, user.ID, update)
 	if err != nil {
+		if isDuplicateKey(err, userNicknameIndex) {
+			return errors.Wrapf(domain.ErrNicknameTaken, "%s: update", userErrorPrefix)
+		}
 		return errors.Wrapf(err, "%s: update", userErrorPrefix)
 	}
-	if result.ModifiedCount == 0 {
+	if result.MatchedCount == 0 {
 		return errors.Wrapf(domain.ErrNoDocuments, "%s: update", userErrorPrefix)
 	}
 	return nil
 }
 
-func (repo *UserMongoRepo) Delete(ctx context.Context, id string) error {
+// Delete removes user who asked to delete account before time
+func (repo *UserMongoRepo) Delete(ctx context.Context, id string, before time.Time) error {
 	cfg := config.Get()
 	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
-		DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+		DeleteOne(ctx, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "deleteRequestedAt", Value: bson.D{{Key: "$lte", Value: before}}},
+		})
 	if err != nil {
 		return errors.Wrapf(err, "%s: delete", userErrorPrefix)
 	}
@@ -122,3 +281,216 @@ func (repo *UserMongoRepo) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// ScheduleDeletion marks user as pending deletion, zero requestedAt cancels deletion unless purge started
+func (repo *UserMongoRepo) ScheduleDeletion(ctx context.Context, id string, requestedAt time.Time) error {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "deleteRequestedAt", Value: requestedAt}}}}
+	if requestedAt.IsZero() {
+		update = bson.D{{Key: "$unset", Value: bson.D{{Key: "deleteRequestedAt", Value: ""}}}}
+	}
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateOne(ctx, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "purgeStartedAt", Value: bson.D{{Key: "$exists", Value: false}}},
+		}, update)
+	if err != nil {
+		return errors.Wrapf(err, "%s: schedule deletion", userErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: schedule deletion", userErrorPrefix)
+	}
+	return nil
+}
+
+// ClaimPurge marks purge of user started if it asked to delete account before time
+func (repo *UserMongoRepo) ClaimPurge(ctx context.Context, id string, before time.Time) error {
+	cfg := config.Get()
+	// $min keeps time of the first claim
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateOne(ctx, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "deleteRequestedAt", Value: bson.D{{Key: "$lte", Value: before}}},
+		}, bson.D{{Key: "$min", Value: bson.D{{Key: "purgeStartedAt", Value: time.Now()}}}})
+	if err != nil {
+		return errors.Wrapf(err, "%s: claim purge", userErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: claim purge", userErrorPrefix)
+	}
+	return nil
+}
+
+// GetDeletionDue returns up to limit users who asked to delete account before time
+func (repo *UserMongoRepo) GetDeletionDue(ctx context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	cfg := config.Get()
+	cursor, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		Find(ctx,
+			bson.D{{Key: "deleteRequestedAt", Value: bson.D{{Key: "$lte", Value: before}}}},
+			options.Find().SetSort(bson.D{{Key: "deleteRequestedAt", Value: 1}}).SetLimit(int64(limit)),
+		)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get deletion due", userErrorPrefix)
+	}
+
+	usersDb := []*userDB{}
+	err = cursor.All(ctx, &usersDb)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get deletion due", userErrorPrefix)
+	}
+
+	users := make([]*domain.User, 0, len(usersDb))
+	for _, userDb := range usersDb {
+		users = append(users, userDb.domain())
+	}
+	return users, nil
+}
+
+// SetRole assigns role to user, nothing is changed unless user still has previous role
+func (repo *UserMongoRepo) SetRole(ctx context.Context, id string, role, previous domain.Role) error {
+	previousRole := bson.A{string(previous)}
+	if previous == domain.RolePlayer {
+		// users created before roles were introduced have no role field
+		previousRole = append(previousRole, nil)
+	}
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: id}, {Key: "role", Value: bson.D{{Key: "$in", Value: previousRole}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "role", Value: string(role)}}}},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: set role", userErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: set role", userErrorPrefix)
+	}
+	return nil
+}
+
+// UpgradeGuest gives guest primary wallet and player role and unbinds it from device,
+// nothing is changed unless user is still guest
+func (repo *UserMongoRepo) UpgradeGuest(ctx context.Context, id, wallet string) error {
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: id}, {Key: "role", Value: string(domain.RoleGuest)}},
+			bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "wallet", Value: wallet},
+					{Key: "role", Value: string(domain.RolePlayer)},
+				}},
+				{Key: "$addToSet", Value: bson.D{{Key: "wallets", Value: wallet}}},
+				{Key: "$unset", Value: bson.D{{Key: "device", Value: ""}}},
+			},
+		)
+	if err != nil {
+		// wallet belongs to another user
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: upgrade guest", userErrorPrefix)
+		}
+		return errors.Wrapf(err, "%s: upgrade guest", userErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: upgrade guest", userErrorPrefix)
+	}
+	return nil
+}
+
+// AddWallet links additional wallet to user, wallet being primary or linked wallet of another user violates unique index
+func (repo *UserMongoRepo) AddWallet(ctx context.Context, id, wallet string) error {
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateByID(ctx, id, bson.D{{Key: "$addToSet", Value: bson.D{{Key: "wallets", Value: wallet}}}})
+	if err != nil {
+		// wallet belongs to another user
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: add wallet", userErrorPrefix)
+		}
+		return errors.Wrapf(err, "%s: add wallet", userErrorPrefix)
+	}
+	if result.MatchedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: add wallet", userErrorPrefix)
+	}
+	return nil
+}
+
+// RemoveWallet unlinks additional wallet from user
+func (repo *UserMongoRepo) RemoveWallet(ctx context.Context, id, wallet string) error {
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateOne(ctx,
+			// primary wallet is among wallets too, but it's not linked one
+			bson.D{{Key: "_id", Value: id}, {Key: "wallets", Value: wallet}, {Key: "wallet", Value: bson.D{{Key: "$ne", Value: wallet}}}},
+			bson.D{{Key: "$pull", Value: bson.D{{Key: "wallets", Value: wallet}}}},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: remove wallet", userErrorPrefix)
+	}
+	if result.ModifiedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: remove wallet", userErrorPrefix)
+	}
+	return nil
+}
+
+// ReplacePrimaryWallet unlinks primary wallet and promotes linked one in its place,
+// nothing is changed unless both wallets still belong to user
+func (repo *UserMongoRepo) ReplacePrimaryWallet(ctx context.Context, id, primary, wallet string) error {
+	// primary wallet is among wallets too, but it's not linked one
+	if primary == wallet {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: replace primary wallet", userErrorPrefix)
+	}
+	cfg := config.Get()
+	result, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: id}, {Key: "wallet", Value: primary}, {Key: "wallets", Value: wallet}},
+			bson.D{
+				{Key: "$set", Value: bson.D{{Key: "wallet", Value: wallet}}},
+				{Key: "$pull", Value: bson.D{{Key: "wallets", Value: primary}}},
+			},
+		)
+	if err != nil {
+		return errors.Wrapf(err, "%s: replace primary wallet", userErrorPrefix)
+	}
+	if result.ModifiedCount == 0 {
+		return errors.Wrapf(domain.ErrNoDocuments, "%s: replace primary wallet", userErrorPrefix)
+	}
+	return nil
+}
+
+// NamespaceWallets prefixes wallets stored as plain Ethereum addresses with chain namespace,
+// running it again changes nothing
+func (repo *UserMongoRepo) NamespaceWallets(ctx context.Context, prefix string) error {
+	isPlain := func(wallet string) bson.D {
+		return bson.D{{Key: "$regexMatch", Value: bson.D{{Key: "input", Value: wallet}, {Key: "regex", Value: "^0x"}}}}
+	}
+	namespace := func(wallet string) bson.D {
+		return bson.D{{Key: "$cond", Value: bson.A{
+			isPlain(wallet),
+			bson.D{{Key: "$concat", Value: bson.A{prefix, wallet}}},
+			wallet,
+		}}}
+	}
+
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "wallet", Value: bson.D{{Key: "$regex", Value: "^0x"}}}},
+		bson.D{{Key: "wallets", Value: bson.D{{Key: "$regex", Value: "^0x"}}}},
+	}}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "wallet", Value: namespace("$wallet")},
+			{Key: "wallets", Value: bson.D{{Key: "$map", Value: bson.D{
+				{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$wallets", bson.A{}}}}},
+				{Key: "in", Value: namespace("$$this")},
+			}}}},
+		}}},
+	}
+
+	cfg := config.Get()
+	_, err := repo.db.Client.Database(cfg.MongoDB).Collection(userTable).
+		UpdateMany(ctx, filter, update)
+	if err != nil {
+		return errors.Wrapf(err, "%s: namespace wallets", userErrorPrefix)
+	}
+	return nil
+}
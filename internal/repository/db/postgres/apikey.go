@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	apiKeyErrorPrefix = "[repository.db.postgres.apikey]"
+)
+
+var _ repository.APIKeyRepository = (*APIKeyPostgresRepo)(nil)
+
+type APIKeyPostgresRepo struct {
+	db *DB
+}
+
+// apiKeyColumns are selected in order scanAPIKey reads them
+const apiKeyColumns = `id, name, hash, prefix, scopes, created_by, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row pgx.CollectableRow) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var scopes []string
+	var lastUsedAt, revokedAt *time.Time
+	err := row.Scan(&key.ID, &key.Name, &key.Hash, &key.Prefix, &scopes, &key.CreatedBy, &key.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = make([]domain.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, domain.Permission(scope))
+	}
+	key.LastUsedAt = fromNullTime(lastUsedAt)
+	key.RevokedAt = fromNullTime(revokedAt)
+	return key, nil
+}
+
+func NewAPIKeyRepo(db *DB) *APIKeyPostgresRepo {
+	return &APIKeyPostgresRepo{db}
+}
+
+// Create stores key unused and unrevoked whatever key says
+func (repo *APIKeyPostgresRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	_, err := repo.db.Exec(ctx,
+		`INSERT INTO api_keys (id, name, hash, prefix, scopes, created_by, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		key.ID, key.Name, key.Hash, key.Prefix, scopes, key.CreatedBy, key.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *APIKeyPostgresRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	rows, _ := repo.db.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE hash = $1`, hash)
+	key, err := pgx.CollectOneRow(rows, scanAPIKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", apiKeyErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", apiKeyErrorPrefix)
+	}
+	return key, nil
+}
+
+// GetAll returns all keys including revoked ones, newest first
+func (repo *APIKeyPostgresRepo) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	rows, _ := repo.db.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC`)
+	keys, err := pgx.CollectRows(rows, scanAPIKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", apiKeyErrorPrefix)
+	}
+	return keys, nil
+}
+
+// Revoke disables key, revoked key can't be revoked again
+func (repo *APIKeyPostgresRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, revokedAt, id)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// Touch records when key was last used
+func (repo *APIKeyPostgresRepo) Touch(ctx context.Context, id string, lastUsedAt time.Time) error {
+	tag, err := repo.db.Exec(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, lastUsedAt, id)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: touch", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces admin who created keys with anonymous one
+func (repo *APIKeyPostgresRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	_, err := repo.db.Exec(ctx, `UPDATE api_keys SET created_by = $1 WHERE created_by = $2`, anonymous, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", apiKeyErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/pkg/keyenc"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	signingKeyErrorPrefix = "[repository.db.postgres.key]"
+)
+
+var _ repository.SigningKeyRepository = (*SigningKeyPostgresRepo)(nil)
+
+// SigningKeyPostgresRepo keeps private keys encrypted with key-encryption key
+type SigningKeyPostgresRepo struct {
+	db     *DB
+	cipher *keyenc.Cipher
+}
+
+type signingKeyRow struct {
+	ID           string
+	Algorithm    string
+	EncryptedKey []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+func NewSigningKeyRepo(db *DB, cipher *keyenc.Cipher) *SigningKeyPostgresRepo {
+	return &SigningKeyPostgresRepo{db, cipher}
+}
+
+func (repo *SigningKeyPostgresRepo) Create(ctx context.Context, key *domain.SigningKey) error {
+	privateKey, err := repo.cipher.Encrypt(key.ID, key.PrivateKey)
+	if err != nil {
+		return errors.Wrapf(domain.ErrConversion, "%s: encrypt private key", signingKeyErrorPrefix)
+	}
+
+	_, err = repo.db.Exec(ctx,
+		`INSERT INTO signing_keys (id, algorithm, encrypted_private_key, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)`,
+		key.ID, key.Algorithm, privateKey, key.CreatedAt, key.ExpiresAt,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", signingKeyErrorPrefix)
+	}
+	return nil
+}
+
+// GetActive returns keys not expired at the given time, newest first
+func (repo *SigningKeyPostgresRepo) GetActive(ctx context.Context, now time.Time) ([]*domain.SigningKey, error) {
+	rows, _ := repo.db.Query(ctx,
+		`SELECT id, algorithm, encrypted_private_key, created_at, expires_at FROM signing_keys
+		WHERE expires_at > $1
+		ORDER BY created_at DESC`,
+		now,
+	)
+	keyRows, err := pgx.CollectRows(rows, pgx.RowToStructByPos[signingKeyRow])
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", signingKeyErrorPrefix)
+	}
+
+	keys := make([]*domain.SigningKey, 0, len(keyRows))
+	for _, keyRow := range keyRows {
+		privateKey, err := repo.cipher.Decrypt(keyRow.ID, keyRow.EncryptedKey)
+		if err != nil {
+			return nil, errors.Wrapf(domain.ErrConversion, "%s: decrypt private key %s", signingKeyErrorPrefix, keyRow.ID)
+		}
+		keys = append(keys, &domain.SigningKey{
+			ID:         keyRow.ID,
+			Algorithm:  keyRow.Algorithm,
+			PrivateKey: privateKey,
+			CreatedAt:  keyRow.CreatedAt,
+			ExpiresAt:  keyRow.ExpiresAt,
+		})
+	}
+	return keys, nil
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"server/internal/repository/db/migration"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	migrationErrorPrefix = "[repository.db.postgres.migration]"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLock is key of advisory lock held while migrating, so instances starting together
+// don't apply the same migration twice
+const migrationLock = 7394208610
+
+// Migrate applies migrations not applied yet, each in its own transaction
+func (db *DB) Migrate(ctx context.Context) error {
+	pending, err := migration.Load(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	// advisory lock belongs to session, so all statements go through one connection
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "%s: migrate", migrationErrorPrefix)
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLock)
+	if err != nil {
+		return errors.Wrapf(err, "%s: lock", migrationErrorPrefix)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLock)
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    integer PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create schema_migrations", migrationErrorPrefix)
+	}
+
+	rows, err := conn.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return errors.Wrapf(err, "%s: applied versions", migrationErrorPrefix)
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return errors.Wrapf(err, "%s: applied versions", migrationErrorPrefix)
+	}
+
+	for _, m := range pending {
+		if slices.Contains(applied, m.Version) {
+			continue
+		}
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, m.SQL)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "%s: apply %s", migrationErrorPrefix, m.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package postgres
+
+import (
+	"server/internal/repository/db/migration"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	_, err := migration.Load(migrations, "migrations")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,27 @@
+CREATE TABLE users (
+    id                   text PRIMARY KEY,
+    nickname             text,
+    wallet               text,
+    wallets              text[] NOT NULL DEFAULT '{}',
+    created_at           timestamptz NOT NULL,
+    role                 text NOT NULL DEFAULT 'player',
+    nickname_changed_at  timestamptz,
+    avatar_url           text NOT NULL DEFAULT '',
+    avatar_nft           text NOT NULL DEFAULT '',
+    bio                  text NOT NULL DEFAULT '',
+    country              text NOT NULL DEFAULT '',
+    privacy_private      boolean NOT NULL DEFAULT false,
+    privacy_hide_country boolean NOT NULL DEFAULT false,
+    privacy_show_wallet  boolean NOT NULL DEFAULT false,
+    delete_requested_at  timestamptz,
+    -- device binds guest to one device, users with wallet have none
+    device               text
+);
+
+-- nicknames are unique ignoring case, byte order makes prefix search a range scan
+CREATE UNIQUE INDEX users_nickname_unique ON users ((lower(nickname)) COLLATE "C");
+CREATE UNIQUE INDEX users_device_unique ON users (device);
+-- one primary wallet can't belong to two users, so concurrent logins with it create one user
+CREATE UNIQUE INDEX users_wallet_unique ON users (wallet);
+CREATE INDEX users_wallets ON users USING gin (wallets);
+CREATE INDEX users_delete_requested_at ON users (delete_requested_at) WHERE delete_requested_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- purge_started_at is set once purge of user starts, deletion can't be cancelled since
+ALTER TABLE users ADD COLUMN purge_started_at timestamptz;
@@ -0,0 +1,10 @@
+-- nonces are issued for login with wallet and consumed once before they expire
+CREATE TABLE nonces (
+    value      text PRIMARY KEY,
+    wallet     text NOT NULL,
+    consumed   boolean NOT NULL DEFAULT false,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+
+CREATE INDEX nonces_expires_at ON nonces (expires_at);
@@ -0,0 +1,15 @@
+-- refresh tokens are stored by hash, family is session tokens are rotated within
+CREATE TABLE refresh_tokens (
+    hash       text PRIMARY KEY,
+    family     text NOT NULL,
+    user_id    text NOT NULL,
+    wallet     text NOT NULL DEFAULT '',
+    used       boolean NOT NULL DEFAULT false,
+    revoked    boolean NOT NULL DEFAULT false,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+
+CREATE INDEX refresh_tokens_family ON refresh_tokens (family);
+CREATE INDEX refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX refresh_tokens_expires_at ON refresh_tokens (expires_at);
@@ -0,0 +1,8 @@
+-- private keys are PKCS #8 DER encrypted with key-encryption key
+CREATE TABLE signing_keys (
+    id                    text PRIMARY KEY,
+    algorithm             text NOT NULL,
+    encrypted_private_key bytea NOT NULL,
+    created_at            timestamptz NOT NULL,
+    expires_at            timestamptz NOT NULL
+);
@@ -0,0 +1,11 @@
+-- session id matches refresh token family
+CREATE TABLE sessions (
+    id           text PRIMARY KEY,
+    user_id      text NOT NULL,
+    user_agent   text NOT NULL DEFAULT '',
+    ip           text NOT NULL DEFAULT '',
+    created_at   timestamptz NOT NULL,
+    last_seen_at timestamptz NOT NULL
+);
+
+CREATE INDEX sessions_user_id ON sessions (user_id);
@@ -0,0 +1,12 @@
+-- role_changes are audit of role assignments, kept after user is gone until user is purged
+CREATE TABLE role_changes (
+    id            text PRIMARY KEY,
+    user_id       text NOT NULL,
+    role          text NOT NULL,
+    previous_role text NOT NULL,
+    changed_by    text NOT NULL,
+    created_at    timestamptz NOT NULL
+);
+
+CREATE INDEX role_changes_user_id ON role_changes (user_id);
+CREATE INDEX role_changes_changed_by ON role_changes (changed_by);
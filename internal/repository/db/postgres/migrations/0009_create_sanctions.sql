@@ -0,0 +1,16 @@
+-- permanent bans have no expires_at, sanctions lifted before they expire have lifted_at
+CREATE TABLE sanctions (
+    id         text PRIMARY KEY,
+    user_id    text NOT NULL,
+    type       text NOT NULL,
+    reason     text NOT NULL DEFAULT '',
+    issued_by  text NOT NULL,
+    created_at timestamptz NOT NULL,
+    expires_at timestamptz,
+    lifted_by  text,
+    lifted_at  timestamptz
+);
+
+CREATE INDEX sanctions_user_id ON sanctions (user_id);
+CREATE INDEX sanctions_issued_by ON sanctions (issued_by);
+CREATE INDEX sanctions_lifted_by ON sanctions (lifted_by);
@@ -0,0 +1,14 @@
+-- api keys are stored by hash
+CREATE TABLE api_keys (
+    id           text PRIMARY KEY,
+    name         text NOT NULL,
+    hash         text NOT NULL UNIQUE,
+    prefix       text NOT NULL,
+    scopes       text[] NOT NULL,
+    created_by   text NOT NULL,
+    created_at   timestamptz NOT NULL,
+    last_used_at timestamptz,
+    revoked_at   timestamptz
+);
+
+CREATE INDEX api_keys_created_by ON api_keys (created_by);
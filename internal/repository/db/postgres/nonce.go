@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	nonceErrorPrefix = "[repository.db.postgres.nonce]"
+)
+
+var _ repository.NonceRepository = (*NoncePostgresRepo)(nil)
+
+type NoncePostgresRepo struct {
+	db *DB
+}
+
+func NewNonceRepo(db *DB) *NoncePostgresRepo {
+	return &NoncePostgresRepo{db}
+}
+
+// Create stores nonce, expired nonces are dropped meanwhile as nothing else removes them
+func (repo *NoncePostgresRepo) Create(ctx context.Context, nonce *domain.Nonce) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM nonces WHERE expires_at <= $1`, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", nonceErrorPrefix)
+	}
+
+	_, err = repo.db.Exec(ctx,
+		`INSERT INTO nonces (value, wallet, expires_at, created_at) VALUES ($1, $2, $3, $4)`,
+		nonce.Value, string(nonce.Wallet), nonce.ExpiresAt, nullTime(nonce.CreatedAt),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", nonceErrorPrefix)
+	}
+	return nil
+}
+
+// Consume marks nonce issued for wallet as used. Conditions and update are applied
+// in a single statement, so a nonce can be consumed only once and only before it expires.
+func (repo *NoncePostgresRepo) Consume(ctx context.Context, wallet domain.Address, value string) error {
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE nonces SET consumed = true WHERE value = $1 AND wallet = $2 AND NOT consumed AND expires_at > $3`,
+		value, string(wallet), time.Now(),
+	)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: consume", nonceErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package postgres is PostgreSQL storage backend. It keeps either all data or users only, next to
+// another storage backend. Schema is created by versioned migrations applied on connect, so every
+// instance runs against the schema its code expects.
+package postgres
+
+import (
+	"context"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/pkg/keyenc"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+var (
+	postgresErrorPrefix = "[repository.db.postgres]"
+)
+
+// uniqueViolation is SQLSTATE of unique constraint violation
+const uniqueViolation = "23505"
+
+type DB struct {
+	*pgxpool.Pool
+}
+
+// Connect connects to database in config
+func Connect(ctx context.Context) (*DB, error) {
+	cfg := config.Get()
+	if cfg.PostgresURL == "" {
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: connection url not provided", postgresErrorPrefix)
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(cfg.PostgresURL)
+	if err != nil {
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: connection url: %s", postgresErrorPrefix, err)
+	}
+	return Open(ctx, poolConfig)
+}
+
+// Open connects to database and migrates it to the latest schema
+func Open(ctx context.Context, poolConfig *pgxpool.Config) (*DB, error) {
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: disconnected", postgresErrorPrefix)
+	}
+
+	res := &DB{pool}
+	if err := res.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	err = res.Migrate(ctx)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// NewRepositories returns storages of database, signing keys are encrypted with cipher
+func NewRepositories(db *DB, cipher *keyenc.Cipher) *repository.Repositories {
+	return &repository.Repositories{
+		User:         NewUserRepo(db),
+		Nonce:        NewNonceRepo(db),
+		RefreshToken: NewRefreshTokenRepo(db),
+		Revocation:   NewRevocationRepo(db),
+		SigningKey:   NewSigningKeyRepo(db, cipher),
+		Session:      NewSessionRepo(db),
+		RoleChange:   NewRoleChangeRepo(db),
+		Sanction:     NewSanctionRepo(db),
+		APIKey:       NewAPIKeyRepo(db),
+	}
+}
+
+func (db *DB) Ping(ctx context.Context) error {
+	if err := db.Pool.Ping(ctx); err != nil {
+		return errors.Wrapf(err, "%s: disconnected", postgresErrorPrefix)
+	}
+	return nil
+}
+
+// isUniqueViolation checks err is caused by violation of one of unique indexes
+func isUniqueViolation(err error, indexes ...string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == uniqueViolation && slices.Contains(indexes, pgErr.ConstraintName)
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository/repositorytest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// open connects to schema of its own, so signing keys other runs encrypted with their
+// key-encryption keys aren't found and schema is migrated from scratch
+func open(t *testing.T) *DB {
+	url := config.Get().PostgresURL
+	if url == "" {
+		t.Skip("POSTGRES_URL not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+
+	schema := pgx.Identifier{"test_" + strings.ToLower(shortuuid.New())}.Sanitize()
+	_, err = admin.Exec(ctx, `CREATE SCHEMA `+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec(context.Background(), `DROP SCHEMA `+schema+` CASCADE`)
+	})
+
+	poolConfig, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = schema
+	db, err := Open(ctx, poolConfig)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	// the second run finds all migrations applied
+	require.NoError(t, db.Migrate(ctx))
+	return db
+}
+
+func TestRepositories_Contract(t *testing.T) {
+	repositorytest.Repositories(t, NewRepositories(open(t), repositorytest.KeyCipher(t)))
+}
+
+func TestSigningKeyRepo_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	db := open(t)
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key := &domain.SigningKey{
+		ID:         "kid",
+		Algorithm:  "EdDSA",
+		PrivateKey: privateKey,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	require.NoError(t, NewSigningKeyRepo(db, repositorytest.KeyCipher(t)).Create(ctx, key))
+
+	var stored []byte
+	require.NoError(t, db.QueryRow(ctx, `SELECT encrypted_private_key FROM signing_keys WHERE id = $1`, key.ID).Scan(&stored))
+	_, err = x509.ParsePKCS8PrivateKey(stored)
+	assert.Error(t, err, "private key is stored in plain")
+
+	// keys can't be read with another key-encryption key
+	_, err = NewSigningKeyRepo(db, repositorytest.KeyCipher(t)).GetActive(ctx, time.Now())
+	assert.ErrorIs(t, err, domain.ErrConversion)
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	revocationErrorPrefix = "[repository.db.postgres.revocation]"
+)
+
+var _ repository.RevocationRepository = (*RevocationPostgresRepo)(nil)
+
+type RevocationPostgresRepo struct {
+	db *DB
+}
+
+func NewRevocationRepo(db *DB) *RevocationPostgresRepo {
+	return &RevocationPostgresRepo{db}
+}
+
+// RevokeAccessToken stores token id until token expires, expired tokens are dropped meanwhile
+func (repo *RevocationPostgresRepo) RevokeAccessToken(ctx context.Context, token *domain.AccessToken) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= $1`, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke access token", revocationErrorPrefix)
+	}
+
+	_, err = repo.db.Exec(ctx,
+		`INSERT INTO revoked_tokens (id, user_id, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, expires_at = excluded.expires_at`,
+		token.ID, token.UserID, token.ExpiresAt,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke access token", revocationErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RevocationPostgresRepo) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	var revoked bool
+	err := repo.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE id = $1)`, id).Scan(&revoked)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: is access token revoked", revocationErrorPrefix)
+	}
+	return revoked, nil
+}
+
+// RevokeUser revokes all user access tokens issued before revokedAt
+func (repo *RevocationPostgresRepo) RevokeUser(ctx context.Context, userID string, revokedAt time.Time) error {
+	_, err := repo.db.Exec(ctx,
+		`INSERT INTO revoked_users (user_id, revoked_at) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_at = excluded.revoked_at`,
+		userID, revokedAt,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke user", revocationErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RevocationPostgresRepo) GetUserRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	var revokedAt time.Time
+	err := repo.db.QueryRow(ctx, `SELECT revoked_at FROM revoked_users WHERE user_id = $1`, userID).Scan(&revokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, errors.Wrapf(domain.ErrNoDocuments, "%s: get user revoked at", revocationErrorPrefix)
+		}
+		return time.Time{}, errors.Wrapf(err, "%s: get user revoked at", revocationErrorPrefix)
+	}
+	return revokedAt, nil
+}
+
+// DeleteByUser removes revoked access tokens and revocation of all tokens of user
+func (repo *RevocationPostgresRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", revocationErrorPrefix)
+	}
+	_, err = repo.db.Exec(ctx, `DELETE FROM revoked_users WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", revocationErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	roleChangeErrorPrefix = "[repository.db.postgres.role]"
+)
+
+var _ repository.RoleChangeRepository = (*RoleChangePostgresRepo)(nil)
+
+type RoleChangePostgresRepo struct {
+	db *DB
+}
+
+func scanRoleChange(row pgx.CollectableRow) (*domain.RoleChange, error) {
+	change := &domain.RoleChange{}
+	var role, previousRole string
+	err := row.Scan(&change.ID, &change.UserID, &role, &previousRole, &change.ChangedBy, &change.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	change.Role = domain.Role(role)
+	change.PreviousRole = domain.Role(previousRole)
+	return change, nil
+}
+
+func NewRoleChangeRepo(db *DB) *RoleChangePostgresRepo {
+	return &RoleChangePostgresRepo{db}
+}
+
+func (repo *RoleChangePostgresRepo) Create(ctx context.Context, change *domain.RoleChange) error {
+	_, err := repo.db.Exec(ctx,
+		`INSERT INTO role_changes (id, user_id, role, previous_role, changed_by, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		change.ID, change.UserID, string(change.Role), string(change.PreviousRole), change.ChangedBy, change.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", roleChangeErrorPrefix)
+	}
+	return nil
+}
+
+// GetByUser returns role changes of user, newest first
+func (repo *RoleChangePostgresRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RoleChange, error) {
+	rows, _ := repo.db.Query(ctx,
+		`SELECT id, user_id, role, previous_role, changed_by, created_at FROM role_changes
+		WHERE user_id = $1
+		ORDER BY created_at DESC`,
+		userID,
+	)
+	changes, err := pgx.CollectRows(rows, scanRoleChange)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", roleChangeErrorPrefix)
+	}
+	return changes, nil
+}
+
+func (repo *RoleChangePostgresRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM role_changes WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", roleChangeErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces author of role changes made by user with anonymous one
+func (repo *RoleChangePostgresRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	_, err := repo.db.Exec(ctx, `UPDATE role_changes SET changed_by = $1 WHERE changed_by = $2`, anonymous, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", roleChangeErrorPrefix)
+	}
+	return nil
+}
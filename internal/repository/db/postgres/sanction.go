@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	sanctionErrorPrefix = "[repository.db.postgres.sanction]"
+)
+
+var _ repository.SanctionRepository = (*SanctionPostgresRepo)(nil)
+
+type SanctionPostgresRepo struct {
+	db *DB
+}
+
+// sanctionColumns are selected in order scanSanction reads them
+const sanctionColumns = `id, user_id, type, reason, issued_by, created_at, expires_at, coalesce(lifted_by, ''), lifted_at`
+
+func scanSanction(row pgx.CollectableRow) (*domain.Sanction, error) {
+	sanction := &domain.Sanction{}
+	var sanctionType string
+	var expiresAt, liftedAt *time.Time
+	err := row.Scan(
+		&sanction.ID, &sanction.UserID, &sanctionType, &sanction.Reason, &sanction.IssuedBy,
+		&sanction.CreatedAt, &expiresAt, &sanction.LiftedBy, &liftedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	sanction.Type = domain.SanctionType(sanctionType)
+	sanction.ExpiresAt = fromNullTime(expiresAt)
+	sanction.LiftedAt = fromNullTime(liftedAt)
+	return sanction, nil
+}
+
+func NewSanctionRepo(db *DB) *SanctionPostgresRepo {
+	return &SanctionPostgresRepo{db}
+}
+
+// Create stores sanction unlifted whatever sanction says
+func (repo *SanctionPostgresRepo) Create(ctx context.Context, sanction *domain.Sanction) error {
+	_, err := repo.db.Exec(ctx,
+		`INSERT INTO sanctions (id, user_id, type, reason, issued_by, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		sanction.ID, sanction.UserID, string(sanction.Type), sanction.Reason, sanction.IssuedBy,
+		sanction.CreatedAt, nullTime(sanction.ExpiresAt),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionPostgresRepo) GetById(ctx context.Context, id string) (*domain.Sanction, error) {
+	rows, _ := repo.db.Query(ctx, `SELECT `+sanctionColumns+` FROM sanctions WHERE id = $1`, id)
+	sanction, err := pgx.CollectOneRow(rows, scanSanction)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by id", sanctionErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by id", sanctionErrorPrefix)
+	}
+	return sanction, nil
+}
+
+// GetByUser returns all sanctions of user, newest first
+func (repo *SanctionPostgresRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Sanction, error) {
+	rows, _ := repo.db.Query(ctx,
+		`SELECT `+sanctionColumns+` FROM sanctions WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	sanctions, err := pgx.CollectRows(rows, scanSanction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sanctionErrorPrefix)
+	}
+	return sanctions, nil
+}
+
+// GetActive returns sanctions of user neither lifted nor expired at now, newest first
+func (repo *SanctionPostgresRepo) GetActive(ctx context.Context, userID string, now time.Time) ([]*domain.Sanction, error) {
+	rows, _ := repo.db.Query(ctx,
+		`SELECT `+sanctionColumns+` FROM sanctions
+		WHERE user_id = $1 AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY created_at DESC`,
+		userID, now,
+	)
+	sanctions, err := pgx.CollectRows(rows, scanSanction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", sanctionErrorPrefix)
+	}
+	return sanctions, nil
+}
+
+// Lift ends sanction before it expires, lifted sanction can't be lifted again
+func (repo *SanctionPostgresRepo) Lift(ctx context.Context, id, liftedBy string, liftedAt time.Time) error {
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE sanctions SET lifted_by = $1, lifted_at = $2 WHERE id = $3 AND lifted_at IS NULL`,
+		liftedBy, liftedAt, id,
+	)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: lift", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionPostgresRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM sanctions WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces moderator who issued or lifted sanctions with anonymous one
+func (repo *SanctionPostgresRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	_, err := repo.db.Exec(ctx,
+		`UPDATE sanctions SET
+			issued_by = CASE WHEN issued_by = $1 THEN $2 ELSE issued_by END,
+			lifted_by = CASE WHEN lifted_by = $1 THEN $2 ELSE lifted_by END
+		WHERE issued_by = $1 OR lifted_by = $1`,
+		userID, anonymous,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", sanctionErrorPrefix)
+	}
+	return nil
+}
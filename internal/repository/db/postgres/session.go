@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	sessionErrorPrefix = "[repository.db.postgres.session]"
+)
+
+var _ repository.SessionRepository = (*SessionPostgresRepo)(nil)
+
+type SessionPostgresRepo struct {
+	db *DB
+}
+
+func scanSession(row pgx.CollectableRow) (*domain.Session, error) {
+	session := &domain.Session{}
+	err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastSeenAt)
+	return session, err
+}
+
+func NewSessionRepo(db *DB) *SessionPostgresRepo {
+	return &SessionPostgresRepo{db}
+}
+
+func (repo *SessionPostgresRepo) Create(ctx context.Context, session *domain.Session) error {
+	_, err := repo.db.Exec(ctx,
+		`INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_seen_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		session.ID, session.UserID, session.UserAgent, session.IP, session.CreatedAt, session.LastSeenAt,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", sessionErrorPrefix)
+	}
+	return nil
+}
+
+// Touch updates session device and last seen time
+func (repo *SessionPostgresRepo) Touch(ctx context.Context, id string, device *domain.Device, lastSeenAt time.Time) error {
+	tag, err := repo.db.Exec(ctx,
+		`UPDATE sessions SET user_agent = $1, ip = $2, last_seen_at = $3 WHERE id = $4`,
+		device.UserAgent, device.IP, lastSeenAt, id,
+	)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: touch", sessionErrorPrefix)
+	}
+	return nil
+}
+
+// GetByUser returns user sessions, recently seen first
+func (repo *SessionPostgresRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	rows, _ := repo.db.Query(ctx,
+		`SELECT id, user_id, user_agent, ip, created_at, last_seen_at FROM sessions
+		WHERE user_id = $1
+		ORDER BY last_seen_at DESC`,
+		userID,
+	)
+	sessions, err := pgx.CollectRows(rows, scanSession)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sessionErrorPrefix)
+	}
+	return sessions, nil
+}
+
+// Exists checks user has session with id
+func (repo *SessionPostgresRepo) Exists(ctx context.Context, userID, id string) (bool, error) {
+	var exists bool
+	err := repo.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND user_id = $2)`, id, userID,
+	).Scan(&exists)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: exists", sessionErrorPrefix)
+	}
+	return exists, nil
+}
+
+// Delete removes session only if it belongs to user
+func (repo *SessionPostgresRepo) Delete(ctx context.Context, userID, id string) error {
+	tag, err := repo.db.Exec(ctx, `DELETE FROM sessions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete", sessionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SessionPostgresRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", sessionErrorPrefix)
+	}
+	return nil
+}
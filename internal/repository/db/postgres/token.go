@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	refreshTokenErrorPrefix = "[repository.db.postgres.token]"
+)
+
+var _ repository.RefreshTokenRepository = (*RefreshTokenPostgresRepo)(nil)
+
+type RefreshTokenPostgresRepo struct {
+	db *DB
+}
+
+// refreshTokenColumns are selected in order scanRefreshToken reads them
+const refreshTokenColumns = `hash, family, user_id, wallet, used, revoked, expires_at, created_at`
+
+func scanRefreshToken(row pgx.CollectableRow) (*domain.RefreshToken, error) {
+	token := &domain.RefreshToken{}
+	var createdAt *time.Time
+	err := row.Scan(&token.Hash, &token.Family, &token.UserID, &token.Wallet, &token.Used, &token.Revoked, &token.ExpiresAt, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	token.CreatedAt = fromNullTime(createdAt)
+	return token, nil
+}
+
+func NewRefreshTokenRepo(db *DB) *RefreshTokenPostgresRepo {
+	return &RefreshTokenPostgresRepo{db}
+}
+
+// Create stores token, expired tokens are dropped meanwhile as nothing else removes them
+func (repo *RefreshTokenPostgresRepo) Create(ctx context.Context, token *domain.RefreshToken) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM refresh_tokens WHERE expires_at <= $1`, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", refreshTokenErrorPrefix)
+	}
+
+	_, err = repo.db.Exec(ctx,
+		`INSERT INTO refresh_tokens (hash, family, user_id, wallet, used, revoked, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		token.Hash, token.Family, token.UserID, token.Wallet, token.Used, token.Revoked,
+		token.ExpiresAt, nullTime(token.CreatedAt),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenPostgresRepo) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	rows, _ := repo.db.Query(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE hash = $1`, hash)
+	token, err := pgx.CollectOneRow(rows, scanRefreshToken)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", refreshTokenErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", refreshTokenErrorPrefix)
+	}
+	return token, nil
+}
+
+// GetByUser returns refresh tokens of user, recently created first
+func (repo *RefreshTokenPostgresRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RefreshToken, error) {
+	rows, _ := repo.db.Query(ctx,
+		`SELECT `+refreshTokenColumns+` FROM refresh_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC NULLS LAST`,
+		userID,
+	)
+	tokens, err := pgx.CollectRows(rows, scanRefreshToken)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", refreshTokenErrorPrefix)
+	}
+	return tokens, nil
+}
+
+// MarkUsed flags token as rotated, only one caller can succeed for a token
+func (repo *RefreshTokenPostgresRepo) MarkUsed(ctx context.Context, hash string) error {
+	tag, err := repo.db.Exec(ctx, `UPDATE refresh_tokens SET used = true WHERE hash = $1 AND NOT used`, hash)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: mark used", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenPostgresRepo) RevokeFamily(ctx context.Context, family string) error {
+	_, err := repo.db.Exec(ctx, `UPDATE refresh_tokens SET revoked = true WHERE family = $1`, family)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke family", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenPostgresRepo) RevokeUser(ctx context.Context, userID string) error {
+	_, err := repo.db.Exec(ctx, `UPDATE refresh_tokens SET revoked = true WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke user", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenPostgresRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.Exec(ctx, `DELETE FROM refresh_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", refreshTokenErrorPrefix)
+	}
+	return nil
+}
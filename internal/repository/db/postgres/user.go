@@ -0,0 +1,387 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+var (
+	userErrorPrefix = "[repository.db.postgres.user]"
+)
+
+var _ repository.UserRepository = (*UserPostgresRepo)(nil)
+
+type UserPostgresRepo struct {
+	db *DB
+}
+
+// userNicknameIndex makes nicknames unique ignoring case, users without nickname are not indexed
+const userNicknameIndex = "users_nickname_unique"
+
+// userKeys are unique indexes besides nickname one, user violating them already exists
+var userKeys = []string{"users_pkey", "users_wallet_unique", "users_device_unique"}
+
+// userColumns are selected in order of userRow fields
+const userColumns = `id, coalesce(nickname, ''), coalesce(wallet, ''), wallets, created_at, role,
+	nickname_changed_at, avatar_url, avatar_nft, bio, country,
+	privacy_private, privacy_hide_country, privacy_show_wallet,
+	delete_requested_at, purge_started_at, coalesce(device, '')`
+
+// userNickname orders and compares nicknames ignoring case the way nickname index does
+const userNickname = `lower(nickname) COLLATE "C"`
+
+type userRow struct {
+	ID        string
+	Nickname  string
+	Wallet    string
+	Wallets   []string
+	CreatedAt time.Time
+	Role      string
+
+	NicknameChangedAt *time.Time
+
+	AvatarURL          string
+	AvatarNFT          string
+	Bio                string
+	Country            string
+	PrivacyPrivate     bool
+	PrivacyHideCountry bool
+	PrivacyShowWallet  bool
+
+	DeleteRequestedAt *time.Time
+	PurgeStartedAt    *time.Time
+
+	Device string
+}
+
+func (u *userRow) domain() *domain.User {
+	user := &domain.User{
+		ID:        u.ID,
+		Nickname:  u.Nickname,
+		Wallet:    u.Wallet,
+		CreatedAt: u.CreatedAt,
+		Role:      domain.Role(u.Role),
+
+		Avatar:  domain.Avatar{URL: u.AvatarURL, NFT: u.AvatarNFT},
+		Bio:     u.Bio,
+		Country: u.Country,
+		Privacy: domain.Privacy{
+			Private:     u.PrivacyPrivate,
+			HideCountry: u.PrivacyHideCountry,
+			ShowWallet:  u.PrivacyShowWallet,
+		},
+
+		Device: u.Device,
+	}
+	// user without linked wallets has none, like in other storages
+	if len(u.Wallets) > 0 {
+		user.Wallets = u.Wallets
+	}
+	if u.NicknameChangedAt != nil {
+		user.NicknameChangedAt = *u.NicknameChangedAt
+	}
+	if u.DeleteRequestedAt != nil {
+		user.DeleteRequestedAt = *u.DeleteRequestedAt
+	}
+	if u.PurgeStartedAt != nil {
+		user.PurgeStartedAt = *u.PurgeStartedAt
+	}
+	return user
+}
+
+func scanUser(row pgx.CollectableRow) (*userRow, error) {
+	u := &userRow{}
+	err := row.Scan(
+		&u.ID, &u.Nickname, &u.Wallet, &u.Wallets, &u.CreatedAt, &u.Role,
+		&u.NicknameChangedAt, &u.AvatarURL, &u.AvatarNFT, &u.Bio, &u.Country,
+		&u.PrivacyPrivate, &u.PrivacyHideCountry, &u.PrivacyShowWallet,
+		&u.DeleteRequestedAt, &u.PurgeStartedAt, &u.Device,
+	)
+	return u, err
+}
+
+// nullTime stores zero time as NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func fromNullTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func NewUserRepo(db *DB) *UserPostgresRepo {
+	return &UserPostgresRepo{db}
+}
+
+func (repo *UserPostgresRepo) GetById(ctx context.Context, id string) (*domain.User, error) {
+	return repo.getOne(ctx, "get by id", `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+}
+
+// GetByWallet returns user by primary or linked wallet
+func (repo *UserPostgresRepo) GetByWallet(ctx context.Context, wallet string) (*domain.User, error) {
+	return repo.getOne(ctx, "get by wallet",
+		`SELECT `+userColumns+` FROM users WHERE wallet = $1 OR wallets @> ARRAY[$1::text] LIMIT 1`, wallet)
+}
+
+// GetByDevice returns guest bound to device
+func (repo *UserPostgresRepo) GetByDevice(ctx context.Context, device string) (*domain.User, error) {
+	return repo.getOne(ctx, "get by device", `SELECT `+userColumns+` FROM users WHERE device = $1`, device)
+}
+
+// Search returns users with nickname starting with prefix ignoring case, ordered by nickname.
+// Page starts after nickname after, from the first user when empty.
+func (repo *UserPostgresRepo) Search(ctx context.Context, prefix, after string, limit int) ([]*domain.User, error) {
+	// U+FFFF sorts after any character, so range covers all nicknames with prefix
+	return repo.getMany(ctx, "search",
+		`SELECT `+userColumns+` FROM users
+		WHERE `+userNickname+` >= lower($1::text) AND `+userNickname+` < lower($1::text) || chr(65535)
+			AND ($2::text = '' OR `+userNickname+` > lower($2::text))
+			AND delete_requested_at IS NULL
+		ORDER BY `+userNickname+`
+		LIMIT $3`,
+		prefix, after, limit,
+	)
+}
+
+// Create saves new user, wallets of user must not belong to another user as primary or linked ones
+func (repo *UserPostgresRepo) Create(ctx context.Context, user *domain.User) error {
+	role := user.Role
+	if role == "" {
+		role = domain.RolePlayer
+	}
+	err := pgx.BeginFunc(ctx, repo.db, func(tx pgx.Tx) error {
+		err := lockWallets(ctx, tx, user.ID, append([]string{user.Wallet}, user.Wallets...)...)
+		if err != nil {
+			return err
+		}
+
+		// profile is filled by updates, new user has none
+		_, err = tx.Exec(ctx,
+			`INSERT INTO users (id, nickname, wallet, wallets, created_at, role, device)
+			VALUES ($1, nullif($2, ''), nullif($3, ''), coalesce($4, '{}'::text[]), $5, $6, nullif($7, ''))`,
+			user.ID, user.Nickname, user.Wallet, user.Wallets, user.CreatedAt, string(role), user.Device,
+		)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err, userNicknameIndex) {
+			return errors.Wrapf(domain.ErrNicknameTaken, "%s: create", userErrorPrefix)
+		}
+		if isUniqueViolation(err, userKeys...) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: create", userErrorPrefix)
+		}
+		return errors.Wrapf(err, "%s: create", userErrorPrefix)
+	}
+	return nil
+}
+
+// Update saves profile fields of user, wallets are changed by dedicated methods
+func (repo *UserPostgresRepo) Update(ctx context.Context, user *domain.User) error {
+	err := repo.exec(ctx, "update",
+		`UPDATE users SET nickname = nullif($2, ''), nickname_changed_at = $3,
+			avatar_url = $4, avatar_nft = $5, bio = $6, country = $7,
+			privacy_private = $8, privacy_hide_country = $9, privacy_show_wallet = $10
+		WHERE id = $1`,
+		user.ID, user.Nickname, nullTime(user.NicknameChangedAt),
+		user.Avatar.URL, user.Avatar.NFT, user.Bio, user.Country,
+		user.Privacy.Private, user.Privacy.HideCountry, user.Privacy.ShowWallet,
+	)
+	if isUniqueViolation(err, userNicknameIndex) {
+		return errors.Wrapf(domain.ErrNicknameTaken, "%s: update", userErrorPrefix)
+	}
+	return err
+}
+
+// Delete removes user who asked to delete account before time
+func (repo *UserPostgresRepo) Delete(ctx context.Context, id string, before time.Time) error {
+	return repo.exec(ctx, "delete", `DELETE FROM users WHERE id = $1 AND delete_requested_at <= $2`, id, before)
+}
+
+// ScheduleDeletion marks user as pending deletion, zero requestedAt cancels deletion unless purge started
+func (repo *UserPostgresRepo) ScheduleDeletion(ctx context.Context, id string, requestedAt time.Time) error {
+	return repo.exec(ctx, "schedule deletion",
+		`UPDATE users SET delete_requested_at = $2 WHERE id = $1 AND purge_started_at IS NULL`, id, nullTime(requestedAt))
+}
+
+// ClaimPurge marks purge of user started if it asked to delete account before time
+func (repo *UserPostgresRepo) ClaimPurge(ctx context.Context, id string, before time.Time) error {
+	return repo.exec(ctx, "claim purge",
+		`UPDATE users SET purge_started_at = coalesce(purge_started_at, now()) WHERE id = $1 AND delete_requested_at <= $2`,
+		id, before)
+}
+
+// GetDeletionDue returns up to limit users who asked to delete account before time
+func (repo *UserPostgresRepo) GetDeletionDue(ctx context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	return repo.getMany(ctx, "get deletion due",
+		`SELECT `+userColumns+` FROM users
+		WHERE delete_requested_at <= $1
+		ORDER BY delete_requested_at
+		LIMIT $2`,
+		before, limit,
+	)
+}
+
+// SetRole assigns role to user, nothing is changed unless user still has previous role
+func (repo *UserPostgresRepo) SetRole(ctx context.Context, id string, role, previous domain.Role) error {
+	return repo.exec(ctx, "set role",
+		`UPDATE users SET role = $2 WHERE id = $1 AND role = $3`, id, string(role), string(previous))
+}
+
+// UpgradeGuest gives guest primary wallet and player role and unbinds it from device,
+// nothing is changed unless user is still guest
+func (repo *UserPostgresRepo) UpgradeGuest(ctx context.Context, id, wallet string) error {
+	return pgx.BeginFunc(ctx, repo.db, func(tx pgx.Tx) error {
+		err := lockWallets(ctx, tx, id, wallet)
+		if err != nil {
+			return errors.Wrapf(err, "%s: upgrade guest", userErrorPrefix)
+		}
+
+		tag, err := tx.Exec(ctx,
+			`UPDATE users SET wallet = $2, role = $3, device = NULL WHERE id = $1 AND role = $4`,
+			id, wallet, string(domain.RolePlayer), string(domain.RoleGuest))
+		if isUniqueViolation(err, userKeys...) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: upgrade guest", userErrorPrefix)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s: upgrade guest", userErrorPrefix)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.Wrapf(domain.ErrNoDocuments, "%s: upgrade guest", userErrorPrefix)
+		}
+		return nil
+	})
+}
+
+// AddWallet links additional wallet to user, wallet must not belong to another user as primary or linked one
+func (repo *UserPostgresRepo) AddWallet(ctx context.Context, id, wallet string) error {
+	return pgx.BeginFunc(ctx, repo.db, func(tx pgx.Tx) error {
+		err := lockWallets(ctx, tx, id, wallet)
+		if err != nil {
+			return errors.Wrapf(err, "%s: add wallet", userErrorPrefix)
+		}
+
+		tag, err := tx.Exec(ctx,
+			`UPDATE users SET wallets = CASE WHEN wallets @> ARRAY[$2::text] THEN wallets ELSE array_append(wallets, $2::text) END
+			WHERE id = $1`,
+			id, wallet)
+		if err != nil {
+			return errors.Wrapf(err, "%s: add wallet", userErrorPrefix)
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.Wrapf(domain.ErrNoDocuments, "%s: add wallet", userErrorPrefix)
+		}
+		return nil
+	})
+}
+
+// RemoveWallet unlinks additional wallet from user
+func (repo *UserPostgresRepo) RemoveWallet(ctx context.Context, id, wallet string) error {
+	return repo.exec(ctx, "remove wallet",
+		`UPDATE users SET wallets = array_remove(wallets, $2::text) WHERE id = $1 AND wallets @> ARRAY[$2::text]`,
+		id, wallet)
+}
+
+// ReplacePrimaryWallet unlinks primary wallet and promotes linked one in its place,
+// nothing is changed unless both wallets still belong to user
+func (repo *UserPostgresRepo) ReplacePrimaryWallet(ctx context.Context, id, primary, wallet string) error {
+	return repo.exec(ctx, "replace primary wallet",
+		`UPDATE users SET wallet = $3, wallets = array_remove(wallets, $3)
+		WHERE id = $1 AND wallet = $2 AND wallets @> ARRAY[$3::text]`,
+		id, primary, wallet)
+}
+
+// lockWallets locks wallets till end of transaction and checks no user but one with id has them
+// as primary or linked wallets, domain.ErrAlreadyExists is returned otherwise. Array elements can't be
+// indexed as unique together with primary wallet, so changes giving user wallets are serialized by
+// advisory locks instead. Locks are taken in order, so transactions locking several wallets don't deadlock.
+func lockWallets(ctx context.Context, tx pgx.Tx, id string, wallets ...string) error {
+	locked := []string{}
+	for _, wallet := range wallets {
+		if wallet != "" {
+			locked = append(locked, wallet)
+		}
+	}
+	if len(locked) == 0 {
+		return nil
+	}
+	sort.Strings(locked)
+
+	for _, wallet := range locked {
+		_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, wallet)
+		if err != nil {
+			return err
+		}
+	}
+
+	var taken bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM users WHERE id <> $1 AND (wallet = ANY($2::text[]) OR wallets && $2::text[]))`,
+		id, locked).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return domain.ErrAlreadyExists
+	}
+	return nil
+}
+
+// getOne returns user selected by query, domain.ErrNoDocuments when there is none
+func (repo *UserPostgresRepo) getOne(ctx context.Context, operation, query string, args ...any) (*domain.User, error) {
+	rows, _ := repo.db.Query(ctx, query, args...)
+	userRow, err := pgx.CollectOneRow(rows, scanUser)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: %s", userErrorPrefix, operation)
+		}
+		return nil, errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+	}
+	return userRow.domain(), nil
+}
+
+func (repo *UserPostgresRepo) getMany(ctx context.Context, operation, query string, args ...any) ([]*domain.User, error) {
+	rows, _ := repo.db.Query(ctx, query, args...)
+	userRows, err := pgx.CollectRows(rows, scanUser)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+	}
+
+	users := make([]*domain.User, 0, len(userRows))
+	for _, userRow := range userRows {
+		users = append(users, userRow.domain())
+	}
+	return users, nil
+}
+
+// exec runs statement changing one user, domain.ErrNoDocuments when no user matched its conditions
+func (repo *UserPostgresRepo) exec(ctx context.Context, operation, query string, args ...any) error {
+	tag, err := repo.db.Exec(ctx, query, args...)
+	if err == nil {
+		err = checkAffected(tag)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+	}
+	return nil
+}
+
+// checkAffected returns domain.ErrNoDocuments when statement changed nothing
+func checkAffected(tag pgconn.CommandTag) error {
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNoDocuments
+	}
+	return nil
+}
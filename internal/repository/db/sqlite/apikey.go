@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	apiKeyErrorPrefix = "[repository.db.sqlite.apikey]"
+)
+
+var _ repository.APIKeyRepository = (*APIKeySQLiteRepo)(nil)
+
+type APIKeySQLiteRepo struct {
+	db *DB
+}
+
+// apiKeyColumns are selected in order scanAPIKey reads them
+const apiKeyColumns = `id, name, hash, prefix, scopes, created_by, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row scanner) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var scopes string
+	var createdAt int64
+	var lastUsedAt, revokedAt sql.NullInt64
+	err := row.Scan(&key.ID, &key.Name, &key.Hash, &key.Prefix, &scopes, &key.CreatedBy, &createdAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(scopes), &key.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	key.CreatedAt = time.Unix(0, createdAt)
+	key.LastUsedAt = fromNullTime(lastUsedAt)
+	key.RevokedAt = fromNullTime(revokedAt)
+	return key, nil
+}
+
+func NewAPIKeyRepo(db *DB) *APIKeySQLiteRepo {
+	return &APIKeySQLiteRepo{db}
+}
+
+// Create stores key unused and unrevoked whatever key says
+func (repo *APIKeySQLiteRepo) Create(ctx context.Context, key *domain.APIKey) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO api_keys (id, name, hash, prefix, scopes, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key.ID, key.Name, key.Hash, key.Prefix, string(scopes), key.CreatedBy, key.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *APIKeySQLiteRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	key, err := scanAPIKey(repo.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE hash = ?`, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", apiKeyErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", apiKeyErrorPrefix)
+	}
+	return key, nil
+}
+
+// GetAll returns all keys including revoked ones, newest first
+func (repo *APIKeySQLiteRepo) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", apiKeyErrorPrefix)
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get all", apiKeyErrorPrefix)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "%s: get all", apiKeyErrorPrefix)
+	}
+	return keys, nil
+}
+
+// Revoke disables key, revoked key can't be revoked again
+func (repo *APIKeySQLiteRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := repo.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, revokedAt.UnixNano(), id)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// Touch records when key was last used
+func (repo *APIKeySQLiteRepo) Touch(ctx context.Context, id string, lastUsedAt time.Time) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, lastUsedAt.UnixNano(), id)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: touch", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces admin who created keys with anonymous one
+func (repo *APIKeySQLiteRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	_, err := repo.db.ExecContext(ctx, `UPDATE api_keys SET created_by = ? WHERE created_by = ?`, anonymous, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", apiKeyErrorPrefix)
+	}
+	return nil
+}
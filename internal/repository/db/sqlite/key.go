@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/pkg/keyenc"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	signingKeyErrorPrefix = "[repository.db.sqlite.key]"
+)
+
+var _ repository.SigningKeyRepository = (*SigningKeySQLiteRepo)(nil)
+
+// SigningKeySQLiteRepo keeps private keys encrypted with key-encryption key
+type SigningKeySQLiteRepo struct {
+	db     *DB
+	cipher *keyenc.Cipher
+}
+
+func NewSigningKeyRepo(db *DB, cipher *keyenc.Cipher) *SigningKeySQLiteRepo {
+	return &SigningKeySQLiteRepo{db, cipher}
+}
+
+func (repo *SigningKeySQLiteRepo) Create(ctx context.Context, key *domain.SigningKey) error {
+	privateKey, err := repo.cipher.Encrypt(key.ID, key.PrivateKey)
+	if err != nil {
+		return errors.Wrapf(domain.ErrConversion, "%s: encrypt private key", signingKeyErrorPrefix)
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO signing_keys (id, algorithm, encrypted_private_key, created_at, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		key.ID, key.Algorithm, privateKey, key.CreatedAt.UnixNano(), key.ExpiresAt.UnixNano(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", signingKeyErrorPrefix)
+	}
+	return nil
+}
+
+// GetActive returns keys not expired at the given time, newest first
+func (repo *SigningKeySQLiteRepo) GetActive(ctx context.Context, now time.Time) ([]*domain.SigningKey, error) {
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT id, algorithm, encrypted_private_key, created_at, expires_at FROM signing_keys
+		WHERE expires_at > ?
+		ORDER BY created_at DESC`,
+		now.UnixNano(),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", signingKeyErrorPrefix)
+	}
+	defer rows.Close()
+
+	keys := []*domain.SigningKey{}
+	for rows.Next() {
+		key := &domain.SigningKey{}
+		var privateKey []byte
+		var createdAt, expiresAt int64
+		err = rows.Scan(&key.ID, &key.Algorithm, &privateKey, &createdAt, &expiresAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get active", signingKeyErrorPrefix)
+		}
+
+		key.PrivateKey, err = repo.cipher.Decrypt(key.ID, privateKey)
+		if err != nil {
+			return nil, errors.Wrapf(domain.ErrConversion, "%s: decrypt private key %s", signingKeyErrorPrefix, key.ID)
+		}
+		key.CreatedAt = time.Unix(0, createdAt)
+		key.ExpiresAt = time.Unix(0, expiresAt)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", signingKeyErrorPrefix)
+	}
+	return keys, nil
+}
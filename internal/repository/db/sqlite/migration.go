@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"embed"
+	"server/internal/repository/db/migration"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	migrationErrorPrefix = "[repository.db.sqlite.migration]"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies migrations not applied yet, each in its own transaction
+func (db *DB) Migrate(ctx context.Context) error {
+	pending, err := migration.Load(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create schema_migrations", migrationErrorPrefix)
+	}
+
+	for _, m := range pending {
+		err = db.apply(ctx, m)
+		if err != nil {
+			return errors.Wrapf(err, "%s: apply %s", migrationErrorPrefix, m.Name)
+		}
+	}
+
+	return nil
+}
+
+// apply runs migration unless it's applied already. Transaction holds write lock from the start,
+// so processes opening the same file don't apply migration twice.
+func (db *DB) apply(ctx context.Context, m *migration.Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`, m.Version).
+		Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, m.SQL)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.Version, m.Name)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
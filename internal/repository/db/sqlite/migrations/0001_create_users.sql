@@ -0,0 +1,37 @@
+-- times are unix nanoseconds, so they compare in order
+CREATE TABLE users (
+    id                   TEXT PRIMARY KEY,
+    -- nicknames are latin, NOCASE compares them ignoring case
+    nickname             TEXT COLLATE NOCASE,
+    wallet               TEXT,
+    created_at           INTEGER NOT NULL,
+    role                 TEXT NOT NULL DEFAULT 'player',
+    nickname_changed_at  INTEGER,
+    avatar_url           TEXT NOT NULL DEFAULT '',
+    avatar_nft           TEXT NOT NULL DEFAULT '',
+    bio                  TEXT NOT NULL DEFAULT '',
+    country              TEXT NOT NULL DEFAULT '',
+    privacy_private      INTEGER NOT NULL DEFAULT 0,
+    privacy_hide_country INTEGER NOT NULL DEFAULT 0,
+    privacy_show_wallet  INTEGER NOT NULL DEFAULT 0,
+    delete_requested_at  INTEGER,
+    -- device binds guest to one device, users with wallet have none
+    device               TEXT
+);
+
+CREATE UNIQUE INDEX users_nickname_unique ON users (nickname);
+CREATE UNIQUE INDEX users_device_unique ON users (device);
+-- one primary wallet can't belong to two users, so concurrent logins with it create one user
+CREATE UNIQUE INDEX users_wallet_unique ON users (wallet);
+CREATE INDEX users_delete_requested_at ON users (delete_requested_at) WHERE delete_requested_at IS NOT NULL;
+
+-- user_wallets are wallets linked to user besides primary one, seq keeps order they were linked in
+CREATE TABLE user_wallets (
+    seq     INTEGER PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    wallet  TEXT NOT NULL,
+    UNIQUE (user_id, wallet)
+);
+
+-- one wallet can't be linked to two users, so concurrent links of it link it once
+CREATE UNIQUE INDEX user_wallets_wallet_unique ON user_wallets (wallet);
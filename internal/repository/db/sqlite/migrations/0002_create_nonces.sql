@@ -0,0 +1,10 @@
+-- nonces are issued for login with wallet and consumed once before they expire
+CREATE TABLE nonces (
+    value      TEXT PRIMARY KEY,
+    wallet     TEXT NOT NULL,
+    consumed   INTEGER NOT NULL DEFAULT 0,
+    expires_at INTEGER NOT NULL,
+    created_at INTEGER
+);
+
+CREATE INDEX nonces_expires_at ON nonces (expires_at);
@@ -0,0 +1,15 @@
+-- revoked_tokens are access tokens revoked before they expire
+CREATE TABLE revoked_tokens (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX revoked_tokens_user_id ON revoked_tokens (user_id);
+CREATE INDEX revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+-- revoked_users revoke all access tokens of user issued before revoked_at
+CREATE TABLE revoked_users (
+    user_id    TEXT PRIMARY KEY,
+    revoked_at INTEGER NOT NULL
+);
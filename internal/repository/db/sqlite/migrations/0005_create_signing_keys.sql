@@ -0,0 +1,8 @@
+-- private keys are PKCS #8 DER
+CREATE TABLE signing_keys (
+    id          TEXT PRIMARY KEY,
+    algorithm   TEXT NOT NULL,
+    private_key BLOB NOT NULL,
+    created_at  INTEGER NOT NULL,
+    expires_at  INTEGER NOT NULL
+);
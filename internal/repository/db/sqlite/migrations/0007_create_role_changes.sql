@@ -0,0 +1,12 @@
+-- role_changes are audit of role assignments, kept after user is gone until user is purged
+CREATE TABLE role_changes (
+    id            TEXT PRIMARY KEY,
+    user_id       TEXT NOT NULL,
+    role          TEXT NOT NULL,
+    previous_role TEXT NOT NULL,
+    changed_by    TEXT NOT NULL,
+    created_at    INTEGER NOT NULL
+);
+
+CREATE INDEX role_changes_user_id ON role_changes (user_id);
+CREATE INDEX role_changes_changed_by ON role_changes (changed_by);
@@ -0,0 +1,14 @@
+-- permanent bans have no expires_at, sanctions lifted before they expire have lifted_at
+CREATE TABLE sanctions (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    reason     TEXT NOT NULL DEFAULT '',
+    issued_by  TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    expires_at INTEGER,
+    lifted_by  TEXT,
+    lifted_at  INTEGER
+);
+
+CREATE INDEX sanctions_user_id ON sanctions (user_id);
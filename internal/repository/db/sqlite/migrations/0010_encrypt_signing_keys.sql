@@ -0,0 +1,4 @@
+-- private keys are PKCS #8 DER encrypted with key-encryption key, keys stored in plain before are dropped
+-- and a new one is created on start, tokens they signed stop being accepted and are refreshed
+DELETE FROM signing_keys;
+ALTER TABLE signing_keys RENAME COLUMN private_key TO encrypted_private_key;
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	nonceErrorPrefix = "[repository.db.sqlite.nonce]"
+)
+
+var _ repository.NonceRepository = (*NonceSQLiteRepo)(nil)
+
+type NonceSQLiteRepo struct {
+	db *DB
+}
+
+func NewNonceRepo(db *DB) *NonceSQLiteRepo {
+	return &NonceSQLiteRepo{db}
+}
+
+// Create stores nonce, expired nonces are dropped meanwhile as nothing else removes them
+func (repo *NonceSQLiteRepo) Create(ctx context.Context, nonce *domain.Nonce) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM nonces WHERE expires_at <= ?`, time.Now().UnixNano())
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", nonceErrorPrefix)
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO nonces (value, wallet, expires_at, created_at) VALUES (?, ?, ?, ?)`,
+		nonce.Value, string(nonce.Wallet), nonce.ExpiresAt.UnixNano(), nullTime(nonce.CreatedAt),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", nonceErrorPrefix)
+	}
+	return nil
+}
+
+// Consume marks nonce issued for wallet as used. Conditions and update are applied
+// in a single statement, so a nonce can be consumed only once and only before it expires.
+func (repo *NonceSQLiteRepo) Consume(ctx context.Context, wallet domain.Address, value string) error {
+	result, err := repo.db.ExecContext(ctx,
+		`UPDATE nonces SET consumed = 1 WHERE value = ? AND wallet = ? AND consumed = 0 AND expires_at > ?`,
+		value, string(wallet), time.Now().UnixNano(),
+	)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: consume", nonceErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	revocationErrorPrefix = "[repository.db.sqlite.revocation]"
+)
+
+var _ repository.RevocationRepository = (*RevocationSQLiteRepo)(nil)
+
+type RevocationSQLiteRepo struct {
+	db *DB
+}
+
+func NewRevocationRepo(db *DB) *RevocationSQLiteRepo {
+	return &RevocationSQLiteRepo{db}
+}
+
+// RevokeAccessToken stores token id until token expires, expired tokens are dropped meanwhile
+func (repo *RevocationSQLiteRepo) RevokeAccessToken(ctx context.Context, token *domain.AccessToken) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= ?`, time.Now().UnixNano())
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke access token", revocationErrorPrefix)
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO revoked_tokens (id, user_id, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, expires_at = excluded.expires_at`,
+		token.ID, token.UserID, token.ExpiresAt.UnixNano(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke access token", revocationErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RevocationSQLiteRepo) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	var revoked bool
+	err := repo.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE id = ?)`, id).Scan(&revoked)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: is access token revoked", revocationErrorPrefix)
+	}
+	return revoked, nil
+}
+
+// RevokeUser revokes all user access tokens issued before revokedAt
+func (repo *RevocationSQLiteRepo) RevokeUser(ctx context.Context, userID string, revokedAt time.Time) error {
+	_, err := repo.db.ExecContext(ctx,
+		`INSERT INTO revoked_users (user_id, revoked_at) VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_at = excluded.revoked_at`,
+		userID, revokedAt.UnixNano(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke user", revocationErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RevocationSQLiteRepo) GetUserRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	var revokedAt int64
+	err := repo.db.QueryRowContext(ctx, `SELECT revoked_at FROM revoked_users WHERE user_id = ?`, userID).Scan(&revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, errors.Wrapf(domain.ErrNoDocuments, "%s: get user revoked at", revocationErrorPrefix)
+		}
+		return time.Time{}, errors.Wrapf(err, "%s: get user revoked at", revocationErrorPrefix)
+	}
+	return time.Unix(0, revokedAt), nil
+}
+
+// DeleteByUser removes revoked access tokens and revocation of all tokens of user
+func (repo *RevocationSQLiteRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", revocationErrorPrefix)
+	}
+	_, err = repo.db.ExecContext(ctx, `DELETE FROM revoked_users WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", revocationErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	roleChangeErrorPrefix = "[repository.db.sqlite.role]"
+)
+
+var _ repository.RoleChangeRepository = (*RoleChangeSQLiteRepo)(nil)
+
+type RoleChangeSQLiteRepo struct {
+	db *DB
+}
+
+func NewRoleChangeRepo(db *DB) *RoleChangeSQLiteRepo {
+	return &RoleChangeSQLiteRepo{db}
+}
+
+func (repo *RoleChangeSQLiteRepo) Create(ctx context.Context, change *domain.RoleChange) error {
+	_, err := repo.db.ExecContext(ctx,
+		`INSERT INTO role_changes (id, user_id, role, previous_role, changed_by, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		change.ID, change.UserID, string(change.Role), string(change.PreviousRole), change.ChangedBy, change.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", roleChangeErrorPrefix)
+	}
+	return nil
+}
+
+// GetByUser returns role changes of user, newest first
+func (repo *RoleChangeSQLiteRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RoleChange, error) {
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT id, user_id, role, previous_role, changed_by, created_at FROM role_changes
+		WHERE user_id = ?
+		ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", roleChangeErrorPrefix)
+	}
+	defer rows.Close()
+
+	changes := []*domain.RoleChange{}
+	for rows.Next() {
+		change := &domain.RoleChange{}
+		var role, previousRole string
+		var createdAt int64
+		err = rows.Scan(&change.ID, &change.UserID, &role, &previousRole, &change.ChangedBy, &createdAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get by user", roleChangeErrorPrefix)
+		}
+		change.Role = domain.Role(role)
+		change.PreviousRole = domain.Role(previousRole)
+		change.CreatedAt = time.Unix(0, createdAt)
+		changes = append(changes, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", roleChangeErrorPrefix)
+	}
+	return changes, nil
+}
+
+func (repo *RoleChangeSQLiteRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM role_changes WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", roleChangeErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces author of role changes made by user with anonymous one
+func (repo *RoleChangeSQLiteRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	_, err := repo.db.ExecContext(ctx, `UPDATE role_changes SET changed_by = ? WHERE changed_by = ?`, anonymous, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", roleChangeErrorPrefix)
+	}
+	return nil
+}
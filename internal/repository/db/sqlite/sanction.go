@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	sanctionErrorPrefix = "[repository.db.sqlite.sanction]"
+)
+
+var _ repository.SanctionRepository = (*SanctionSQLiteRepo)(nil)
+
+type SanctionSQLiteRepo struct {
+	db *DB
+}
+
+// sanctionColumns are selected in order scanSanction reads them
+const sanctionColumns = `id, user_id, type, reason, issued_by, created_at, expires_at, coalesce(lifted_by, ''), lifted_at`
+
+func NewSanctionRepo(db *DB) *SanctionSQLiteRepo {
+	return &SanctionSQLiteRepo{db}
+}
+
+// Create stores sanction unlifted whatever sanction says
+func (repo *SanctionSQLiteRepo) Create(ctx context.Context, sanction *domain.Sanction) error {
+	_, err := repo.db.ExecContext(ctx,
+		`INSERT INTO sanctions (id, user_id, type, reason, issued_by, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		sanction.ID, sanction.UserID, string(sanction.Type), sanction.Reason, sanction.IssuedBy,
+		sanction.CreatedAt.UnixNano(), nullTime(sanction.ExpiresAt),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionSQLiteRepo) GetById(ctx context.Context, id string) (*domain.Sanction, error) {
+	sanctions, err := repo.find(ctx, `SELECT `+sanctionColumns+` FROM sanctions WHERE id = ?`, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id", sanctionErrorPrefix)
+	}
+	if len(sanctions) == 0 {
+		return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by id", sanctionErrorPrefix)
+	}
+	return sanctions[0], nil
+}
+
+// GetByUser returns all sanctions of user, newest first
+func (repo *SanctionSQLiteRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Sanction, error) {
+	sanctions, err := repo.find(ctx,
+		`SELECT `+sanctionColumns+` FROM sanctions WHERE user_id = ? ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sanctionErrorPrefix)
+	}
+	return sanctions, nil
+}
+
+// GetActive returns sanctions of user neither lifted nor expired at now, newest first
+func (repo *SanctionSQLiteRepo) GetActive(ctx context.Context, userID string, now time.Time) ([]*domain.Sanction, error) {
+	sanctions, err := repo.find(ctx,
+		`SELECT `+sanctionColumns+` FROM sanctions
+		WHERE user_id = ? AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY created_at DESC`,
+		userID, now.UnixNano(),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get active", sanctionErrorPrefix)
+	}
+	return sanctions, nil
+}
+
+// Lift ends sanction before it expires, lifted sanction can't be lifted again
+func (repo *SanctionSQLiteRepo) Lift(ctx context.Context, id, liftedBy string, liftedAt time.Time) error {
+	result, err := repo.db.ExecContext(ctx,
+		`UPDATE sanctions SET lifted_by = ?, lifted_at = ? WHERE id = ? AND lifted_at IS NULL`,
+		liftedBy, liftedAt.UnixNano(), id,
+	)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: lift", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionSQLiteRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM sanctions WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+// AnonymizeActor replaces moderator who issued or lifted sanctions with anonymous one
+func (repo *SanctionSQLiteRepo) AnonymizeActor(ctx context.Context, userID, anonymous string) error {
+	_, err := repo.db.ExecContext(ctx,
+		`UPDATE sanctions SET
+			issued_by = CASE WHEN issued_by = ?1 THEN ?2 ELSE issued_by END,
+			lifted_by = CASE WHEN lifted_by = ?1 THEN ?2 ELSE lifted_by END
+		WHERE issued_by = ?1 OR lifted_by = ?1`,
+		userID, anonymous,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: anonymize actor", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SanctionSQLiteRepo) find(ctx context.Context, query string, args ...any) ([]*domain.Sanction, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sanctions := []*domain.Sanction{}
+	for rows.Next() {
+		sanction := &domain.Sanction{}
+		var sanctionType string
+		var createdAt int64
+		var expiresAt, liftedAt sql.NullInt64
+		err = rows.Scan(
+			&sanction.ID, &sanction.UserID, &sanctionType, &sanction.Reason, &sanction.IssuedBy,
+			&createdAt, &expiresAt, &sanction.LiftedBy, &liftedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sanction.Type = domain.SanctionType(sanctionType)
+		sanction.CreatedAt = time.Unix(0, createdAt)
+		sanction.ExpiresAt = fromNullTime(expiresAt)
+		sanction.LiftedAt = fromNullTime(liftedAt)
+		sanctions = append(sanctions, sanction)
+	}
+	return sanctions, rows.Err()
+}
@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	sessionErrorPrefix = "[repository.db.sqlite.session]"
+)
+
+var _ repository.SessionRepository = (*SessionSQLiteRepo)(nil)
+
+type SessionSQLiteRepo struct {
+	db *DB
+}
+
+func NewSessionRepo(db *DB) *SessionSQLiteRepo {
+	return &SessionSQLiteRepo{db}
+}
+
+func (repo *SessionSQLiteRepo) Create(ctx context.Context, session *domain.Session) error {
+	_, err := repo.db.ExecContext(ctx,
+		`INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_seen_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		session.ID, session.UserID, session.UserAgent, session.IP, session.CreatedAt.UnixNano(), session.LastSeenAt.UnixNano(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", sessionErrorPrefix)
+	}
+	return nil
+}
+
+// Touch updates session device and last seen time
+func (repo *SessionSQLiteRepo) Touch(ctx context.Context, id string, device *domain.Device, lastSeenAt time.Time) error {
+	result, err := repo.db.ExecContext(ctx,
+		`UPDATE sessions SET user_agent = ?, ip = ?, last_seen_at = ? WHERE id = ?`,
+		device.UserAgent, device.IP, lastSeenAt.UnixNano(), id,
+	)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: touch", sessionErrorPrefix)
+	}
+	return nil
+}
+
+// GetByUser returns user sessions, recently seen first
+func (repo *SessionSQLiteRepo) GetByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT id, user_id, user_agent, ip, created_at, last_seen_at FROM sessions
+		WHERE user_id = ?
+		ORDER BY last_seen_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sessionErrorPrefix)
+	}
+	defer rows.Close()
+
+	sessions := []*domain.Session{}
+	for rows.Next() {
+		session := &domain.Session{}
+		var createdAt, lastSeenAt int64
+		err = rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &createdAt, &lastSeenAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get by user", sessionErrorPrefix)
+		}
+		session.CreatedAt = time.Unix(0, createdAt)
+		session.LastSeenAt = time.Unix(0, lastSeenAt)
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", sessionErrorPrefix)
+	}
+	return sessions, nil
+}
+
+// Delete removes session only if it belongs to user
+func (repo *SessionSQLiteRepo) Exists(ctx context.Context, userID, id string) (bool, error) {
+	var exists bool
+	err := repo.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sessions WHERE id = ? AND user_id = ?)`, id, userID,
+	).Scan(&exists)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: exists", sessionErrorPrefix)
+	}
+	return exists, nil
+}
+
+func (repo *SessionSQLiteRepo) Delete(ctx context.Context, userID, id string) error {
+	result, err := repo.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ? AND user_id = ?`, id, userID)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete", sessionErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *SessionSQLiteRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", sessionErrorPrefix)
+	}
+	return nil
+}
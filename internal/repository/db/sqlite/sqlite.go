@@ -0,0 +1,94 @@
+// Package sqlite is SQLite storage backend keeping data in a single file, for single-node deployments
+// and integration tests. It keeps either all data or users only, next to another storage backend.
+// Database runs in WAL mode, so readers don't wait for writer, and schema is created by versioned
+// migrations applied on connect.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/pkg/keyenc"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var (
+	sqliteErrorPrefix = "[repository.db.sqlite]"
+)
+
+// connectionPragmas are applied to every connection. Writers wait for each other instead of failing,
+// and transactions take write lock when they begin, so they don't fail upgrading read lock.
+const connectionPragmas = "_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_txlock=immediate"
+
+type DB struct {
+	*sql.DB
+}
+
+// Connect opens database file in config
+func Connect(ctx context.Context) (*DB, error) {
+	cfg := config.Get()
+	if cfg.SQLitePath == "" {
+		return nil, errors.Wrapf(domain.ErrConfig, "%s: database path not provided", sqliteErrorPrefix)
+	}
+	return Open(ctx, cfg.SQLitePath)
+}
+
+// Open opens database file at path, creating it when missing, and migrates it to the latest schema
+func Open(ctx context.Context, path string) (*DB, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?"+connectionPragmas)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: open", sqliteErrorPrefix)
+	}
+
+	res := &DB{db}
+	err = res.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrapf(err, "%s: open", sqliteErrorPrefix)
+	}
+
+	err = res.Migrate(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// NewRepositories returns storages of database, signing keys are encrypted with cipher
+func NewRepositories(db *DB, cipher *keyenc.Cipher) *repository.Repositories {
+	return &repository.Repositories{
+		User:         NewUserRepo(db),
+		Nonce:        NewNonceRepo(db),
+		RefreshToken: NewRefreshTokenRepo(db),
+		Revocation:   NewRevocationRepo(db),
+		SigningKey:   NewSigningKeyRepo(db, cipher),
+		Session:      NewSessionRepo(db),
+		RoleChange:   NewRoleChangeRepo(db),
+		Sanction:     NewSanctionRepo(db),
+		APIKey:       NewAPIKeyRepo(db),
+	}
+}
+
+// isUniqueViolation checks err is caused by violation of unique index or primary key
+// on one of columns of table, e.g. users.nickname
+func isUniqueViolation(err error, columns ...string) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	if sqliteErr.Code() != sqlite3.SQLITE_CONSTRAINT_UNIQUE && sqliteErr.Code() != sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+		return false
+	}
+	return slices.ContainsFunc(columns, func(column string) bool {
+		return strings.Contains(sqliteErr.Error(), column)
+	})
+}
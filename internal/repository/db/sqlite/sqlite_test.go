@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"path/filepath"
+	"server/internal/domain"
+	"server/internal/repository/db/migration"
+	"server/internal/repository/repositorytest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func open(t *testing.T) *DB {
+	db, err := Open(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRepositories_Contract(t *testing.T) {
+	repositorytest.Repositories(t, NewRepositories(open(t), repositorytest.KeyCipher(t)))
+}
+
+func TestSigningKeyRepo_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	db := open(t)
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key := &domain.SigningKey{
+		ID:         "kid",
+		Algorithm:  "EdDSA",
+		PrivateKey: privateKey,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	require.NoError(t, NewSigningKeyRepo(db, repositorytest.KeyCipher(t)).Create(ctx, key))
+
+	var stored []byte
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT encrypted_private_key FROM signing_keys WHERE id = ?`, key.ID).Scan(&stored))
+	_, err = x509.ParsePKCS8PrivateKey(stored)
+	assert.Error(t, err, "private key is stored in plain")
+
+	// keys can't be read with another key-encryption key
+	_, err = NewSigningKeyRepo(db, repositorytest.KeyCipher(t)).GetActive(ctx, time.Now())
+	assert.ErrorIs(t, err, domain.ErrConversion)
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := Open(ctx, path)
+	require.NoError(t, err)
+
+	var journalMode string
+	require.NoError(t, db.QueryRowContext(ctx, `PRAGMA journal_mode`).Scan(&journalMode))
+	assert.Equal(t, "wal", journalMode)
+	require.NoError(t, db.Close())
+
+	// migrations applied already are skipped
+	db, err = Open(ctx, path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var applied int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM schema_migrations`).Scan(&applied))
+	pending, err := migration.Load(migrations, "migrations")
+	require.NoError(t, err)
+	assert.Equal(t, len(pending), applied)
+}
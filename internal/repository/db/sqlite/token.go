@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	refreshTokenErrorPrefix = "[repository.db.sqlite.token]"
+)
+
+var _ repository.RefreshTokenRepository = (*RefreshTokenSQLiteRepo)(nil)
+
+type RefreshTokenSQLiteRepo struct {
+	db *DB
+}
+
+func NewRefreshTokenRepo(db *DB) *RefreshTokenSQLiteRepo {
+	return &RefreshTokenSQLiteRepo{db}
+}
+
+// Create stores token, expired tokens are dropped meanwhile as nothing else removes them
+func (repo *RefreshTokenSQLiteRepo) Create(ctx context.Context, token *domain.RefreshToken) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at <= ?`, time.Now().UnixNano())
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", refreshTokenErrorPrefix)
+	}
+
+	_, err = repo.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (hash, family, user_id, wallet, used, revoked, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		token.Hash, token.Family, token.UserID, token.Wallet, token.Used, token.Revoked,
+		token.ExpiresAt.UnixNano(), nullTime(token.CreatedAt),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "%s: create", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenSQLiteRepo) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	token := &domain.RefreshToken{}
+	var expiresAt int64
+	var createdAt sql.NullInt64
+	err := repo.db.QueryRowContext(ctx,
+		`SELECT hash, family, user_id, wallet, used, revoked, expires_at, created_at FROM refresh_tokens WHERE hash = ?`,
+		hash,
+	).Scan(&token.Hash, &token.Family, &token.UserID, &token.Wallet, &token.Used, &token.Revoked, &expiresAt, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: get by hash", refreshTokenErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: get by hash", refreshTokenErrorPrefix)
+	}
+	token.ExpiresAt = time.Unix(0, expiresAt)
+	token.CreatedAt = fromNullTime(createdAt)
+	return token, nil
+}
+
+// GetByUser returns refresh tokens of user, recently created first
+func (repo *RefreshTokenSQLiteRepo) GetByUser(ctx context.Context, userID string) ([]*domain.RefreshToken, error) {
+	rows, err := repo.db.QueryContext(ctx,
+		`SELECT hash, family, user_id, wallet, used, revoked, expires_at, created_at FROM refresh_tokens
+		WHERE user_id = ?
+		ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", refreshTokenErrorPrefix)
+	}
+	defer rows.Close()
+
+	tokens := []*domain.RefreshToken{}
+	for rows.Next() {
+		token := &domain.RefreshToken{}
+		var expiresAt int64
+		var createdAt sql.NullInt64
+		err = rows.Scan(&token.Hash, &token.Family, &token.UserID, &token.Wallet, &token.Used, &token.Revoked, &expiresAt, &createdAt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get by user", refreshTokenErrorPrefix)
+		}
+		token.ExpiresAt = time.Unix(0, expiresAt)
+		token.CreatedAt = fromNullTime(createdAt)
+		tokens = append(tokens, token)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "%s: get by user", refreshTokenErrorPrefix)
+	}
+	return tokens, nil
+}
+
+// MarkUsed flags token as rotated, only one caller can succeed for a token
+func (repo *RefreshTokenSQLiteRepo) MarkUsed(ctx context.Context, hash string) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE refresh_tokens SET used = 1 WHERE hash = ? AND used = 0`, hash)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: mark used", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenSQLiteRepo) RevokeFamily(ctx context.Context, family string) error {
+	_, err := repo.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked = 1 WHERE family = ?`, family)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke family", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenSQLiteRepo) RevokeUser(ctx context.Context, userID string) error {
+	_, err := repo.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked = 1 WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: revoke user", refreshTokenErrorPrefix)
+	}
+	return nil
+}
+
+func (repo *RefreshTokenSQLiteRepo) DeleteByUser(ctx context.Context, userID string) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE user_id = ?`, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete by user", refreshTokenErrorPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"server/internal/domain"
+	"server/internal/repository"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	userErrorPrefix = "[repository.db.sqlite.user]"
+)
+
+var _ repository.UserRepository = (*UserSQLiteRepo)(nil)
+
+type UserSQLiteRepo struct {
+	db *DB
+}
+
+// userNicknameColumn is unique ignoring case, users without nickname are not indexed
+const userNicknameColumn = "users.nickname"
+
+// userKeys are unique columns besides nickname, user violating them already exists
+var userKeys = []string{"users.id", "users.wallet", "users.device", userLinkedWalletColumn}
+
+// userLinkedWalletColumn is unique, one wallet can't be linked to two users
+const userLinkedWalletColumn = "user_wallets.wallet"
+
+// userColumns are selected in order of userRow fields, linked wallets are JSON array
+const userColumns = `id, coalesce(nickname, ''), coalesce(wallet, ''),
+	(SELECT json_group_array(wallet) FROM (SELECT wallet FROM user_wallets WHERE user_id = users.id ORDER BY seq)),
+	created_at, role, nickname_changed_at, avatar_url, avatar_nft, bio, country,
+	privacy_private, privacy_hide_country, privacy_show_wallet,
+	delete_requested_at, purge_started_at, coalesce(device, '')`
+
+type userRow struct {
+	ID        string
+	Nickname  string
+	Wallet    string
+	Wallets   string
+	CreatedAt int64
+	Role      string
+
+	NicknameChangedAt sql.NullInt64
+
+	AvatarURL          string
+	AvatarNFT          string
+	Bio                string
+	Country            string
+	PrivacyPrivate     bool
+	PrivacyHideCountry bool
+	PrivacyShowWallet  bool
+
+	DeleteRequestedAt sql.NullInt64
+	PurgeStartedAt    sql.NullInt64
+
+	Device string
+}
+
+type scanner interface {
+	Scan(...any) error
+}
+
+func scanUser(row scanner) (*domain.User, error) {
+	u := &userRow{}
+	err := row.Scan(
+		&u.ID, &u.Nickname, &u.Wallet, &u.Wallets, &u.CreatedAt, &u.Role,
+		&u.NicknameChangedAt, &u.AvatarURL, &u.AvatarNFT, &u.Bio, &u.Country,
+		&u.PrivacyPrivate, &u.PrivacyHideCountry, &u.PrivacyShowWallet,
+		&u.DeleteRequestedAt, &u.PurgeStartedAt, &u.Device,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return u.domain()
+}
+
+func (u *userRow) domain() (*domain.User, error) {
+	user := &domain.User{
+		ID:        u.ID,
+		Nickname:  u.Nickname,
+		Wallet:    u.Wallet,
+		CreatedAt: time.Unix(0, u.CreatedAt),
+		Role:      domain.Role(u.Role),
+
+		NicknameChangedAt: fromNullTime(u.NicknameChangedAt),
+
+		Avatar:  domain.Avatar{URL: u.AvatarURL, NFT: u.AvatarNFT},
+		Bio:     u.Bio,
+		Country: u.Country,
+		Privacy: domain.Privacy{
+			Private:     u.PrivacyPrivate,
+			HideCountry: u.PrivacyHideCountry,
+			ShowWallet:  u.PrivacyShowWallet,
+		},
+
+		DeleteRequestedAt: fromNullTime(u.DeleteRequestedAt),
+		PurgeStartedAt:    fromNullTime(u.PurgeStartedAt),
+
+		Device: u.Device,
+	}
+
+	wallets := []string{}
+	err := json.Unmarshal([]byte(u.Wallets), &wallets)
+	if err != nil {
+		return nil, err
+	}
+	// user without linked wallets has none, like in other storages
+	if len(wallets) > 0 {
+		user.Wallets = wallets
+	}
+	return user, nil
+}
+
+// nullTime stores zero time as NULL
+func nullTime(t time.Time) sql.NullInt64 {
+	if t.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func fromNullTime(t sql.NullInt64) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return time.Unix(0, t.Int64)
+}
+
+func NewUserRepo(db *DB) *UserSQLiteRepo {
+	return &UserSQLiteRepo{db}
+}
+
+func (repo *UserSQLiteRepo) GetById(ctx context.Context, id string) (*domain.User, error) {
+	return repo.getOne(ctx, "get by id", `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+}
+
+// GetByWallet returns user by primary or linked wallet
+func (repo *UserSQLiteRepo) GetByWallet(ctx context.Context, wallet string) (*domain.User, error) {
+	return repo.getOne(ctx, "get by wallet",
+		`SELECT `+userColumns+` FROM users
+		WHERE wallet = ? OR id IN (SELECT user_id FROM user_wallets WHERE wallet = ?)
+		LIMIT 1`,
+		wallet, wallet)
+}
+
+// GetByDevice returns guest bound to device
+func (repo *UserSQLiteRepo) GetByDevice(ctx context.Context, device string) (*domain.User, error) {
+	return repo.getOne(ctx, "get by device", `SELECT `+userColumns+` FROM users WHERE device = ?`, device)
+}
+
+// Search returns users with nickname starting with prefix ignoring case, ordered by nickname.
+// Page starts after nickname after, from the first user when empty.
+func (repo *UserSQLiteRepo) Search(ctx context.Context, prefix, after string, limit int) ([]*domain.User, error) {
+	// U+FFFF sorts after any character, so range covers all nicknames with prefix
+	return repo.getMany(ctx, "search",
+		`SELECT `+userColumns+` FROM users
+		WHERE nickname >= ? AND nickname < ? || char(65535)
+			AND (? = '' OR nickname > ?)
+			AND delete_requested_at IS NULL
+		ORDER BY nickname
+		LIMIT ?`,
+		prefix, prefix, after, after, limit,
+	)
+}
+
+func (repo *UserSQLiteRepo) Create(ctx context.Context, user *domain.User) error {
+	role := user.Role
+	if role == "" {
+		role = domain.RolePlayer
+	}
+	// profile is filled by updates, new user has none
+	err := repo.tx(ctx, func(tx *sql.Tx) error {
+		err := checkWalletsFree(ctx, tx, user.ID, append([]string{user.Wallet}, user.Wallets...)...)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO users (id, nickname, wallet, created_at, role, device)
+			VALUES (?, nullif(?, ''), nullif(?, ''), ?, ?, nullif(?, ''))`,
+			user.ID, user.Nickname, user.Wallet, user.CreatedAt.UnixNano(), string(role), user.Device,
+		)
+		if err != nil {
+			return err
+		}
+		for _, wallet := range user.Wallets {
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO user_wallets (user_id, wallet) VALUES (?, ?) ON CONFLICT (user_id, wallet) DO NOTHING`,
+				user.ID, wallet)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if isUniqueViolation(err, userNicknameColumn) {
+			return errors.Wrapf(domain.ErrNicknameTaken, "%s: create", userErrorPrefix)
+		}
+		if isUniqueViolation(err, userKeys...) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: create", userErrorPrefix)
+		}
+		return errors.Wrapf(err, "%s: create", userErrorPrefix)
+	}
+	return nil
+}
+
+// Update saves profile fields of user, wallets are changed by dedicated methods
+func (repo *UserSQLiteRepo) Update(ctx context.Context, user *domain.User) error {
+	err := repo.exec(ctx, "update",
+		`UPDATE users SET nickname = nullif(?, ''), nickname_changed_at = ?,
+			avatar_url = ?, avatar_nft = ?, bio = ?, country = ?,
+			privacy_private = ?, privacy_hide_country = ?, privacy_show_wallet = ?
+		WHERE id = ?`,
+		user.Nickname, nullTime(user.NicknameChangedAt),
+		user.Avatar.URL, user.Avatar.NFT, user.Bio, user.Country,
+		user.Privacy.Private, user.Privacy.HideCountry, user.Privacy.ShowWallet,
+		user.ID,
+	)
+	if isUniqueViolation(err, userNicknameColumn) {
+		return errors.Wrapf(domain.ErrNicknameTaken, "%s: update", userErrorPrefix)
+	}
+	return err
+}
+
+// Delete removes user with linked wallets if user asked to delete account before time
+func (repo *UserSQLiteRepo) Delete(ctx context.Context, id string, before time.Time) error {
+	return repo.exec(ctx, "delete",
+		`DELETE FROM users WHERE id = ? AND delete_requested_at <= ?`, id, before.UnixNano())
+}
+
+// ScheduleDeletion marks user as pending deletion, zero requestedAt cancels deletion unless purge started
+func (repo *UserSQLiteRepo) ScheduleDeletion(ctx context.Context, id string, requestedAt time.Time) error {
+	return repo.exec(ctx, "schedule deletion",
+		`UPDATE users SET delete_requested_at = ? WHERE id = ? AND purge_started_at IS NULL`, nullTime(requestedAt), id)
+}
+
+// ClaimPurge marks purge of user started if it asked to delete account before time
+func (repo *UserSQLiteRepo) ClaimPurge(ctx context.Context, id string, before time.Time) error {
+	return repo.exec(ctx, "claim purge",
+		`UPDATE users SET purge_started_at = coalesce(purge_started_at, ?) WHERE id = ? AND delete_requested_at <= ?`,
+		time.Now().UnixNano(), id, before.UnixNano())
+}
+
+// GetDeletionDue returns up to limit users who asked to delete account before time
+func (repo *UserSQLiteRepo) GetDeletionDue(ctx context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	return repo.getMany(ctx, "get deletion due",
+		`SELECT `+userColumns+` FROM users
+		WHERE delete_requested_at <= ?
+		ORDER BY delete_requested_at
+		LIMIT ?`,
+		before.UnixNano(), limit,
+	)
+}
+
+// SetRole assigns role to user, nothing is changed unless user still has previous role
+func (repo *UserSQLiteRepo) SetRole(ctx context.Context, id string, role, previous domain.Role) error {
+	return repo.exec(ctx, "set role",
+		`UPDATE users SET role = ? WHERE id = ? AND role = ?`, string(role), id, string(previous))
+}
+
+// UpgradeGuest gives guest primary wallet and player role and unbinds it from device,
+// nothing is changed unless user is still guest
+func (repo *UserSQLiteRepo) UpgradeGuest(ctx context.Context, id, wallet string) error {
+	err := repo.tx(ctx, func(tx *sql.Tx) error {
+		err := checkWalletsFree(ctx, tx, id, wallet)
+		if err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx,
+			`UPDATE users SET wallet = ?, role = ?, device = NULL WHERE id = ? AND role = ?`,
+			wallet, string(domain.RolePlayer), id, string(domain.RoleGuest))
+		if err != nil {
+			return err
+		}
+		return checkAffected(result)
+	})
+	if err != nil {
+		if isUniqueViolation(err, userKeys...) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: upgrade guest", userErrorPrefix)
+		}
+		return errors.Wrapf(err, "%s: upgrade guest", userErrorPrefix)
+	}
+	return nil
+}
+
+// AddWallet links additional wallet to user
+func (repo *UserSQLiteRepo) AddWallet(ctx context.Context, id, wallet string) error {
+	err := repo.tx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)`, id).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrNoDocuments
+		}
+		err = checkWalletsFree(ctx, tx, id, wallet)
+		if err != nil {
+			return err
+		}
+		// wallet linked already is left in its place, wallet linked to another user violates unique index
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO user_wallets (user_id, wallet) VALUES (?, ?) ON CONFLICT (user_id, wallet) DO NOTHING`,
+			id, wallet)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err, userLinkedWalletColumn) {
+			return errors.Wrapf(domain.ErrAlreadyExists, "%s: add wallet", userErrorPrefix)
+		}
+		return errors.Wrapf(err, "%s: add wallet", userErrorPrefix)
+	}
+	return nil
+}
+
+// RemoveWallet unlinks additional wallet from user
+func (repo *UserSQLiteRepo) RemoveWallet(ctx context.Context, id, wallet string) error {
+	return repo.exec(ctx, "remove wallet", `DELETE FROM user_wallets WHERE user_id = ? AND wallet = ?`, id, wallet)
+}
+
+// ReplacePrimaryWallet unlinks primary wallet and promotes linked one in its place,
+// nothing is changed unless both wallets still belong to user
+func (repo *UserSQLiteRepo) ReplacePrimaryWallet(ctx context.Context, id, primary, wallet string) error {
+	err := repo.tx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM user_wallets WHERE user_id = ? AND wallet = ?`, id, wallet)
+		if err != nil {
+			return err
+		}
+		err = checkAffected(result)
+		if err != nil {
+			return err
+		}
+		result, err = tx.ExecContext(ctx, `UPDATE users SET wallet = ? WHERE id = ? AND wallet = ?`, wallet, id, primary)
+		if err != nil {
+			return err
+		}
+		return checkAffected(result)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "%s: replace primary wallet", userErrorPrefix)
+	}
+	return nil
+}
+
+// checkWalletsFree fails with domain.ErrAlreadyExists when another user has one of wallets as primary or linked one.
+// Unique indexes cover each column alone, write transactions are serialized, so check holds until commit.
+func checkWalletsFree(ctx context.Context, tx *sql.Tx, id string, wallets ...string) error {
+	for _, wallet := range wallets {
+		if wallet == "" {
+			continue
+		}
+		var taken bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM users WHERE id <> ? AND wallet = ?)
+				OR EXISTS (SELECT 1 FROM user_wallets WHERE user_id <> ? AND wallet = ?)`,
+			id, wallet, id, wallet).Scan(&taken)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return domain.ErrAlreadyExists
+		}
+	}
+	return nil
+}
+
+// getOne returns user selected by query, domain.ErrNoDocuments when there is none
+func (repo *UserSQLiteRepo) getOne(ctx context.Context, operation, query string, args ...any) (*domain.User, error) {
+	user, err := scanUser(repo.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrapf(domain.ErrNoDocuments, "%s: %s", userErrorPrefix, operation)
+		}
+		return nil, errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+	}
+	return user, nil
+}
+
+func (repo *UserSQLiteRepo) getMany(ctx context.Context, operation, query string, args ...any) ([]*domain.User, error) {
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+	}
+	defer rows.Close()
+
+	users := []*domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+		}
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+	}
+	return users, nil
+}
+
+// exec runs statement changing one user, domain.ErrNoDocuments when no user matched its conditions
+func (repo *UserSQLiteRepo) exec(ctx context.Context, operation, query string, args ...any) error {
+	result, err := repo.db.ExecContext(ctx, query, args...)
+	if err == nil {
+		err = checkAffected(result)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s: %s", userErrorPrefix, operation)
+	}
+	return nil
+}
+
+// tx runs statements in transaction, which is rolled back when run fails
+func (repo *UserSQLiteRepo) tx(ctx context.Context, run func(*sql.Tx) error) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = run(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkAffected returns domain.ErrNoDocuments when statement changed nothing
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package repository defines storage contracts every storage backend implements.
+// Services depend on narrower interfaces of their own, which these contracts satisfy.
+package repository
+
+import (
+	"context"
+	"server/internal/domain"
+	"time"
+)
+
+// Storage backends selectable in config, each keeps all data
+const (
+	StorageMongoDB = "mongodb"
+	// StorageMemory keeps everything in process memory, data is lost on restart
+	StorageMemory = "memory"
+	// StorageSQLite keeps everything in database file, it can keep users only as user storage too
+	StorageSQLite = "sqlite"
+	// StoragePostgres keeps everything in PostgreSQL, it can keep users only as user storage too
+	StoragePostgres = "postgres"
+)
+
+// UserRepository stores users. Lookups of missing users and updates matching no user
+// return domain.ErrNoDocuments.
+type UserRepository interface {
+	GetById(context.Context, string) (*domain.User, error)
+	// GetByWallet returns user by primary or linked wallet
+	GetByWallet(context.Context, string) (*domain.User, error)
+	GetByDevice(context.Context, string) (*domain.User, error)
+	// Create returns domain.ErrNicknameTaken when nickname is taken ignoring case
+	Create(context.Context, *domain.User) error
+	// Update saves profile fields, returns domain.ErrNicknameTaken when nickname is taken ignoring case
+	Update(context.Context, *domain.User) error
+	// Search returns users with nickname starting with prefix ignoring case after nickname after,
+	// ordered by nickname, users pending deletion are skipped
+	Search(context.Context, string, string, int) ([]*domain.User, error)
+	// SetRole changes role only if user still has previous one
+	SetRole(context.Context, string, domain.Role, domain.Role) error
+	// ScheduleDeletion marks user as pending deletion, zero time cancels deletion.
+	// Returns domain.ErrNoDocuments once purge of user started.
+	ScheduleDeletion(context.Context, string, time.Time) error
+	GetDeletionDue(context.Context, time.Time, int) ([]*domain.User, error)
+	// ClaimPurge marks purge of user started if it asked to delete account before time,
+	// so deletion can't be cancelled while user data is removed. Claiming again keeps the first claim.
+	ClaimPurge(context.Context, string, time.Time) error
+	// Delete removes user only if it asked to delete account before time, so user who cancelled deletion is kept
+	Delete(context.Context, string, time.Time) error
+	// AddWallet returns domain.ErrAlreadyExists when wallet is linked to another user
+	AddWallet(context.Context, string, string) error
+	RemoveWallet(context.Context, string, string) error
+	// ReplacePrimaryWallet promotes linked wallet in place of primary one if both still belong to user
+	ReplacePrimaryWallet(context.Context, string, string, string) error
+	// UpgradeGuest gives guest wallet and player role if user is still guest
+	UpgradeGuest(context.Context, string, string) error
+}
+
+type NonceRepository interface {
+	Create(context.Context, *domain.Nonce) error
+	// Consume marks nonce used, returns domain.ErrNoDocuments for unknown, used and expired nonces
+	Consume(context.Context, domain.Address, string) error
+}
+
+type RefreshTokenRepository interface {
+	Create(context.Context, *domain.RefreshToken) error
+	GetByHash(context.Context, string) (*domain.RefreshToken, error)
+	// GetByUser returns refresh tokens of user, recently created first
+	GetByUser(context.Context, string) ([]*domain.RefreshToken, error)
+	// MarkUsed returns domain.ErrNoDocuments unless token was unused
+	MarkUsed(context.Context, string) error
+	RevokeFamily(context.Context, string) error
+	RevokeUser(context.Context, string) error
+	DeleteByUser(context.Context, string) error
+}
+
+type RevocationRepository interface {
+	RevokeAccessToken(context.Context, *domain.AccessToken) error
+	IsAccessTokenRevoked(context.Context, string) (bool, error)
+	RevokeUser(context.Context, string, time.Time) error
+	GetUserRevokedAt(context.Context, string) (time.Time, error)
+	DeleteByUser(context.Context, string) error
+}
+
+type SigningKeyRepository interface {
+	Create(context.Context, *domain.SigningKey) error
+	// GetActive returns keys not expired at time, newest first
+	GetActive(context.Context, time.Time) ([]*domain.SigningKey, error)
+}
+
+type SessionRepository interface {
+	Create(context.Context, *domain.Session) error
+	Touch(context.Context, string, *domain.Device, time.Time) error
+	// GetByUser returns sessions of user, recently seen first
+	GetByUser(context.Context, string) ([]*domain.Session, error)
+	// Exists checks user has session with id
+	Exists(context.Context, string, string) (bool, error)
+	// Delete removes session only if it belongs to user
+	Delete(context.Context, string, string) error
+	DeleteByUser(context.Context, string) error
+}
+
+type RoleChangeRepository interface {
+	Create(context.Context, *domain.RoleChange) error
+	// GetByUser returns role changes of user, newest first
+	GetByUser(context.Context, string) ([]*domain.RoleChange, error)
+	DeleteByUser(context.Context, string) error
+	AnonymizeActor(context.Context, string, string) error
+}
+
+type SanctionRepository interface {
+	Create(context.Context, *domain.Sanction) error
+	GetById(context.Context, string) (*domain.Sanction, error)
+	// GetByUser returns sanctions of user, newest first
+	GetByUser(context.Context, string) ([]*domain.Sanction, error)
+	// GetActive returns sanctions of user neither lifted nor expired at time, newest first
+	GetActive(context.Context, string, time.Time) ([]*domain.Sanction, error)
+	// Lift returns domain.ErrNoDocuments for unknown and lifted sanctions
+	Lift(context.Context, string, string, time.Time) error
+	DeleteByUser(context.Context, string) error
+	AnonymizeActor(context.Context, string, string) error
+}
+
+type APIKeyRepository interface {
+	Create(context.Context, *domain.APIKey) error
+	GetByHash(context.Context, string) (*domain.APIKey, error)
+	// GetAll returns all keys including revoked ones, newest first
+	GetAll(context.Context) ([]*domain.APIKey, error)
+	// Revoke returns domain.ErrNoDocuments for unknown and revoked keys
+	Revoke(context.Context, string, time.Time) error
+	Touch(context.Context, string, time.Time) error
+	AnonymizeActor(context.Context, string, string) error
+}
+
+// Repositories are storages of one backend
+type Repositories struct {
+	User         UserRepository
+	Nonce        NonceRepository
+	RefreshToken RefreshTokenRepository
+	Revocation   RevocationRepository
+	SigningKey   SigningKeyRepository
+	Session      SessionRepository
+	RoleChange   RoleChangeRepository
+	Sanction     SanctionRepository
+	APIKey       APIKeyRepository
+}
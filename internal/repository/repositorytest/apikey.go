@@ -0,0 +1,126 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// APIKeyRepository runs API key repository contract against repo
+func APIKeyRepository(t *testing.T, repo repository.APIKeyRepository) {
+	ctx := context.Background()
+
+	newKey := func(t *testing.T, createdAt time.Time) *domain.APIKey {
+		key := &domain.APIKey{
+			ID:        shortuuid.New(),
+			Name:      "matchmaker",
+			Hash:      shortuuid.New(),
+			Prefix:    "abcdefgh",
+			Scopes:    []domain.Permission{domain.PermissionUsersRead, domain.PermissionSanctionsManage},
+			CreatedBy: shortuuid.New(),
+			CreatedAt: createdAt,
+		}
+		require.NoError(t, repo.Create(ctx, key))
+		return key
+	}
+	get := func(t *testing.T, hash string) *domain.APIKey {
+		key, err := repo.GetByHash(ctx, hash)
+		require.NoError(t, err)
+		return key
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.GetByHash(ctx, shortuuid.New())
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+		assert.ErrorIs(t, repo.Revoke(ctx, shortuuid.New(), time.Now()), domain.ErrNoDocuments)
+		assert.ErrorIs(t, repo.Touch(ctx, shortuuid.New(), time.Now()), domain.ErrNoDocuments)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		key := newKey(t, time.Now())
+
+		stored := get(t, key.Hash)
+		assert.Equal(t, key.ID, stored.ID)
+		assert.Equal(t, key.Name, stored.Name)
+		assert.Equal(t, key.Prefix, stored.Prefix)
+		assert.Equal(t, key.Scopes, stored.Scopes)
+		assert.Equal(t, key.CreatedBy, stored.CreatedBy)
+		assert.WithinDuration(t, key.CreatedAt, stored.CreatedAt, timePrecision)
+		assert.True(t, stored.LastUsedAt.IsZero())
+		assert.True(t, stored.RevokedAt.IsZero())
+	})
+
+	t.Run("all newest first", func(t *testing.T) {
+		now := time.Now()
+		old := newKey(t, now.Add(-time.Hour))
+		recent := newKey(t, now)
+		require.NoError(t, repo.Revoke(ctx, old.ID, now))
+
+		keys, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		ids := []string{}
+		for _, key := range keys {
+			if key.ID == old.ID || key.ID == recent.ID {
+				ids = append(ids, key.ID)
+			}
+		}
+		// revoked keys are listed too
+		assert.Equal(t, []string{recent.ID, old.ID}, ids)
+	})
+
+	t.Run("revoke once", func(t *testing.T) {
+		key := newKey(t, time.Now())
+		revokedAt := time.Now()
+
+		require.NoError(t, repo.Revoke(ctx, key.ID, revokedAt))
+		assert.WithinDuration(t, revokedAt, get(t, key.Hash).RevokedAt, timePrecision)
+		assert.ErrorIs(t, repo.Revoke(ctx, key.ID, time.Now()), domain.ErrNoDocuments)
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		key := newKey(t, time.Now())
+		lastUsedAt := time.Now()
+
+		require.NoError(t, repo.Touch(ctx, key.ID, lastUsedAt))
+		assert.WithinDuration(t, lastUsedAt, get(t, key.Hash).LastUsedAt, timePrecision)
+	})
+
+	t.Run("anonymize actor", func(t *testing.T) {
+		key := newKey(t, time.Now())
+		other := newKey(t, time.Now())
+
+		require.NoError(t, repo.AnonymizeActor(ctx, key.CreatedBy, domain.DeletedActor))
+		assert.Equal(t, domain.DeletedActor, get(t, key.Hash).CreatedBy)
+		assert.Equal(t, other.CreatedBy, get(t, other.Hash).CreatedBy)
+		assert.NoError(t, repo.AnonymizeActor(ctx, key.CreatedBy, domain.DeletedActor))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		key := newKey(t, time.Now())
+
+		duplicateID := *key
+		duplicateID.Hash = shortuuid.New()
+		assert.Error(t, repo.Create(ctx, &duplicateID))
+
+		duplicateHash := *key
+		duplicateHash.ID = shortuuid.New()
+		assert.Error(t, repo.Create(ctx, &duplicateHash))
+
+		assert.Equal(t, key.ID, get(t, key.Hash).ID)
+	})
+
+	t.Run("concurrent revoke", func(t *testing.T) {
+		key := newKey(t, time.Now())
+
+		errs := race(func(i int) error {
+			return repo.Revoke(ctx, key.ID, time.Now())
+		})
+		assert.Equal(t, 1, succeeded(errs))
+	})
+}
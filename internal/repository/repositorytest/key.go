@@ -0,0 +1,79 @@
+package repositorytest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/pkg/keyenc"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// KeyCipher returns cipher with random key-encryption key for signing key repositories
+func KeyCipher(t *testing.T) *keyenc.Cipher {
+	kek := make([]byte, keyenc.KeySize)
+	_, err := rand.Read(kek)
+	require.NoError(t, err)
+	cipher, err := keyenc.New(kek)
+	require.NoError(t, err)
+	return cipher
+}
+
+// SigningKeyRepository runs signing key repository contract against repo
+func SigningKeyRepository(t *testing.T, repo repository.SigningKeyRepository) {
+	ctx := context.Background()
+
+	newKey := func(t *testing.T, createdAt, expiresAt time.Time) *domain.SigningKey {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		key := &domain.SigningKey{
+			ID:         shortuuid.New(),
+			Algorithm:  "EdDSA",
+			PrivateKey: privateKey,
+			CreatedAt:  createdAt,
+			ExpiresAt:  expiresAt,
+		}
+		require.NoError(t, repo.Create(ctx, key))
+		return key
+	}
+	// active returns ids of active keys among keys in order they are returned
+	active := func(t *testing.T, now time.Time, keys ...*domain.SigningKey) []string {
+		stored, err := repo.GetActive(ctx, now)
+		require.NoError(t, err)
+		res := []string{}
+		for _, s := range stored {
+			for _, key := range keys {
+				if s.ID == key.ID {
+					assert.Equal(t, key.Algorithm, s.Algorithm)
+					assert.True(t, key.PrivateKey.(ed25519.PrivateKey).Equal(s.PrivateKey))
+					res = append(res, s.ID)
+				}
+			}
+		}
+		return res
+	}
+
+	t.Run("active newest first", func(t *testing.T) {
+		now := time.Now()
+		old := newKey(t, now.Add(-2*time.Hour), now.Add(time.Hour))
+		expired := newKey(t, now.Add(-3*time.Hour), now.Add(-time.Hour))
+		current := newKey(t, now.Add(-time.Hour), now.Add(2*time.Hour))
+
+		assert.Equal(t, []string{current.ID, old.ID}, active(t, now, old, expired, current))
+		assert.Equal(t, []string{current.ID}, active(t, now.Add(90*time.Minute), old, expired, current))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		now := time.Now()
+		key := newKey(t, now, now.Add(time.Hour))
+
+		assert.Error(t, repo.Create(ctx, key))
+		assert.Equal(t, []string{key.ID}, active(t, now, key))
+	})
+}
@@ -0,0 +1,64 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NonceRepository runs nonce repository contract against repo
+func NonceRepository(t *testing.T, repo repository.NonceRepository) {
+	ctx := context.Background()
+
+	newNonce := func(t *testing.T, expiresIn time.Duration) *domain.Nonce {
+		now := time.Now()
+		nonce := &domain.Nonce{
+			Value:     shortuuid.New(),
+			Wallet:    domain.Address("eip155:1:0x" + shortuuid.New()),
+			ExpiresAt: now.Add(expiresIn),
+			CreatedAt: now,
+		}
+		require.NoError(t, repo.Create(ctx, nonce))
+		return nonce
+	}
+
+	t.Run("consume once", func(t *testing.T) {
+		nonce := newNonce(t, time.Minute)
+
+		require.NoError(t, repo.Consume(ctx, nonce.Wallet, nonce.Value))
+		assert.ErrorIs(t, repo.Consume(ctx, nonce.Wallet, nonce.Value), domain.ErrNoDocuments)
+	})
+
+	t.Run("not consumable", func(t *testing.T) {
+		nonce := newNonce(t, time.Minute)
+		expired := newNonce(t, -time.Second)
+
+		assert.ErrorIs(t, repo.Consume(ctx, nonce.Wallet, shortuuid.New()), domain.ErrNoDocuments)
+		assert.ErrorIs(t, repo.Consume(ctx, domain.Address("eip155:1:0x"+shortuuid.New()), nonce.Value), domain.ErrNoDocuments)
+		assert.ErrorIs(t, repo.Consume(ctx, expired.Wallet, expired.Value), domain.ErrNoDocuments)
+
+		// failed attempts don't use nonce up
+		assert.NoError(t, repo.Consume(ctx, nonce.Wallet, nonce.Value))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		nonce := newNonce(t, time.Minute)
+
+		assert.Error(t, repo.Create(ctx, nonce))
+	})
+
+	t.Run("concurrent consume", func(t *testing.T) {
+		nonce := newNonce(t, time.Minute)
+
+		errs := race(func(i int) error {
+			return repo.Consume(ctx, nonce.Wallet, nonce.Value)
+		})
+		assert.Equal(t, 1, succeeded(errs))
+	})
+}
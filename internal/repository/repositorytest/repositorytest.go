@@ -0,0 +1,59 @@
+// Package repositorytest is contract test suite storage backends run against their repositories,
+// so services behave the same whichever backend is selected. Suites leave data they create behind
+// and tell it apart from other data by unique ids, so they run against shared databases too.
+package repositorytest
+
+import (
+	"server/internal/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+// timePrecision is the coarsest time precision among backends, MongoDB keeps milliseconds
+const timePrecision = time.Millisecond
+
+// concurrency is number of goroutines racing for the same change
+const concurrency = 10
+
+// Repositories runs contracts of all repositories of backend
+func Repositories(t *testing.T, repos *repository.Repositories) {
+	t.Run("user", func(t *testing.T) { UserRepository(t, repos.User) })
+	t.Run("nonce", func(t *testing.T) { NonceRepository(t, repos.Nonce) })
+	t.Run("refresh token", func(t *testing.T) { RefreshTokenRepository(t, repos.RefreshToken) })
+	t.Run("revocation", func(t *testing.T) { RevocationRepository(t, repos.Revocation) })
+	t.Run("signing key", func(t *testing.T) { SigningKeyRepository(t, repos.SigningKey) })
+	t.Run("session", func(t *testing.T) { SessionRepository(t, repos.Session) })
+	t.Run("role change", func(t *testing.T) { RoleChangeRepository(t, repos.RoleChange) })
+	t.Run("sanction", func(t *testing.T) { SanctionRepository(t, repos.Sanction) })
+	t.Run("api key", func(t *testing.T) { APIKeyRepository(t, repos.APIKey) })
+}
+
+// race runs change from concurrency goroutines at once and returns their errors
+func race(change func(i int) error) []error {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = change(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// succeeded counts changes without error
+func succeeded(errs []error) int {
+	count := 0
+	for _, err := range errs {
+		if err == nil {
+			count++
+		}
+	}
+	return count
+}
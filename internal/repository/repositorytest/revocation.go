@@ -0,0 +1,86 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RevocationRepository runs access token revocation repository contract against repo
+func RevocationRepository(t *testing.T, repo repository.RevocationRepository) {
+	ctx := context.Background()
+
+	newAccessToken := func(userID string) *domain.AccessToken {
+		now := time.Now()
+		return &domain.AccessToken{
+			ID:        shortuuid.New(),
+			UserID:    userID,
+			IssuedAt:  now,
+			ExpiresAt: now.Add(time.Hour),
+		}
+	}
+	revoked := func(t *testing.T, id string) bool {
+		revoked, err := repo.IsAccessTokenRevoked(ctx, id)
+		require.NoError(t, err)
+		return revoked
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		assert.False(t, revoked(t, shortuuid.New()))
+		_, err := repo.GetUserRevokedAt(ctx, shortuuid.New())
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+	})
+
+	t.Run("revoke access token", func(t *testing.T) {
+		token := newAccessToken(shortuuid.New())
+
+		require.NoError(t, repo.RevokeAccessToken(ctx, token))
+		assert.True(t, revoked(t, token.ID))
+		// logging out twice is not an error
+		assert.NoError(t, repo.RevokeAccessToken(ctx, token))
+	})
+
+	t.Run("revoke user", func(t *testing.T) {
+		userID := shortuuid.New()
+		first := time.Now().Add(-time.Minute)
+		last := time.Now()
+
+		require.NoError(t, repo.RevokeUser(ctx, userID, first))
+		require.NoError(t, repo.RevokeUser(ctx, userID, last))
+
+		revokedAt, err := repo.GetUserRevokedAt(ctx, userID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, last, revokedAt, timePrecision)
+	})
+
+	t.Run("delete by user", func(t *testing.T) {
+		userID := shortuuid.New()
+		token := newAccessToken(userID)
+		otherUser := newAccessToken(shortuuid.New())
+		require.NoError(t, repo.RevokeAccessToken(ctx, token))
+		require.NoError(t, repo.RevokeAccessToken(ctx, otherUser))
+		require.NoError(t, repo.RevokeUser(ctx, userID, time.Now()))
+
+		require.NoError(t, repo.DeleteByUser(ctx, userID))
+		assert.False(t, revoked(t, token.ID))
+		assert.True(t, revoked(t, otherUser.ID))
+		_, err := repo.GetUserRevokedAt(ctx, userID)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+	})
+
+	t.Run("concurrent revoke", func(t *testing.T) {
+		token := newAccessToken(shortuuid.New())
+
+		errs := race(func(i int) error {
+			return repo.RevokeAccessToken(ctx, token)
+		})
+		assert.Equal(t, concurrency, succeeded(errs))
+		assert.True(t, revoked(t, token.ID))
+	})
+}
@@ -0,0 +1,82 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RoleChangeRepository runs role change audit repository contract against repo
+func RoleChangeRepository(t *testing.T, repo repository.RoleChangeRepository) {
+	ctx := context.Background()
+
+	newChange := func(t *testing.T, userID, changedBy string, createdAt time.Time) *domain.RoleChange {
+		change := &domain.RoleChange{
+			ID:           shortuuid.New(),
+			UserID:       userID,
+			Role:         domain.RoleModerator,
+			PreviousRole: domain.RolePlayer,
+			ChangedBy:    changedBy,
+			CreatedAt:    createdAt,
+		}
+		require.NoError(t, repo.Create(ctx, change))
+		return change
+	}
+	get := func(t *testing.T, userID string) []*domain.RoleChange {
+		changes, err := repo.GetByUser(ctx, userID)
+		require.NoError(t, err)
+		return changes
+	}
+
+	t.Run("newest first", func(t *testing.T) {
+		userID, adminID := shortuuid.New(), shortuuid.New()
+		now := time.Now()
+		old := newChange(t, userID, adminID, now.Add(-time.Hour))
+		recent := newChange(t, userID, domain.SystemActor, now)
+		newChange(t, shortuuid.New(), adminID, now)
+
+		changes := get(t, userID)
+		require.Len(t, changes, 2)
+		assert.Equal(t, recent.ID, changes[0].ID)
+		assert.Equal(t, old.ID, changes[1].ID)
+		assert.Equal(t, old.Role, changes[1].Role)
+		assert.Equal(t, old.PreviousRole, changes[1].PreviousRole)
+		assert.Equal(t, adminID, changes[1].ChangedBy)
+
+		assert.Empty(t, get(t, shortuuid.New()))
+	})
+
+	t.Run("anonymize actor", func(t *testing.T) {
+		userID, adminID := shortuuid.New(), shortuuid.New()
+		newChange(t, userID, adminID, time.Now())
+
+		require.NoError(t, repo.AnonymizeActor(ctx, adminID, domain.DeletedActor))
+		assert.Equal(t, domain.DeletedActor, get(t, userID)[0].ChangedBy)
+		assert.NoError(t, repo.AnonymizeActor(ctx, adminID, domain.DeletedActor))
+	})
+
+	t.Run("delete by user", func(t *testing.T) {
+		userID, otherUserID := shortuuid.New(), shortuuid.New()
+		newChange(t, userID, domain.SystemActor, time.Now())
+		newChange(t, otherUserID, domain.SystemActor, time.Now())
+
+		require.NoError(t, repo.DeleteByUser(ctx, userID))
+		assert.Empty(t, get(t, userID))
+		assert.Len(t, get(t, otherUserID), 1)
+		assert.NoError(t, repo.DeleteByUser(ctx, userID))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		userID := shortuuid.New()
+		change := newChange(t, userID, domain.SystemActor, time.Now())
+
+		assert.Error(t, repo.Create(ctx, change))
+		assert.Len(t, get(t, userID), 1)
+	})
+}
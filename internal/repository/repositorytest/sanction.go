@@ -0,0 +1,148 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SanctionRepository runs sanction repository contract against repo
+func SanctionRepository(t *testing.T, repo repository.SanctionRepository) {
+	ctx := context.Background()
+
+	newSanction := func(t *testing.T, userID string, createdAt, expiresAt time.Time) *domain.Sanction {
+		sanction := &domain.Sanction{
+			ID:        shortuuid.New(),
+			UserID:    userID,
+			Type:      domain.SanctionMute,
+			Reason:    "spam",
+			IssuedBy:  shortuuid.New(),
+			CreatedAt: createdAt,
+			ExpiresAt: expiresAt,
+		}
+		require.NoError(t, repo.Create(ctx, sanction))
+		return sanction
+	}
+	ids := func(sanctions []*domain.Sanction) []string {
+		res := []string{}
+		for _, sanction := range sanctions {
+			res = append(res, sanction.ID)
+		}
+		return res
+	}
+	byUser := func(t *testing.T, userID string) []*domain.Sanction {
+		sanctions, err := repo.GetByUser(ctx, userID)
+		require.NoError(t, err)
+		return sanctions
+	}
+	active := func(t *testing.T, userID string, now time.Time) []*domain.Sanction {
+		sanctions, err := repo.GetActive(ctx, userID, now)
+		require.NoError(t, err)
+		return sanctions
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.GetById(ctx, shortuuid.New())
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+		assert.Empty(t, ids(byUser(t, shortuuid.New())))
+		assert.Empty(t, ids(active(t, shortuuid.New(), time.Now())))
+		assert.ErrorIs(t, repo.Lift(ctx, shortuuid.New(), shortuuid.New(), time.Now()), domain.ErrNoDocuments)
+	})
+
+	t.Run("active newest first", func(t *testing.T) {
+		userID := shortuuid.New()
+		now := time.Now()
+		permanent := newSanction(t, userID, now.Add(-3*time.Hour), time.Time{})
+		expired := newSanction(t, userID, now.Add(-2*time.Hour), now.Add(-time.Hour))
+		lifted := newSanction(t, userID, now.Add(-time.Hour), now.Add(time.Hour))
+		recent := newSanction(t, userID, now, now.Add(time.Hour))
+		require.NoError(t, repo.Lift(ctx, lifted.ID, shortuuid.New(), now))
+
+		assert.Equal(t, []string{recent.ID, lifted.ID, expired.ID, permanent.ID}, ids(byUser(t, userID)))
+		assert.Equal(t, []string{recent.ID, permanent.ID}, ids(active(t, userID, now)))
+
+		sanctions := byUser(t, userID)
+		assert.Equal(t, permanent.Type, sanctions[3].Type)
+		assert.Equal(t, permanent.Reason, sanctions[3].Reason)
+		assert.Equal(t, permanent.IssuedBy, sanctions[3].IssuedBy)
+		assert.True(t, sanctions[3].ExpiresAt.IsZero())
+	})
+
+	t.Run("lift once", func(t *testing.T) {
+		userID, moderatorID := shortuuid.New(), shortuuid.New()
+		sanction := newSanction(t, userID, time.Now(), time.Time{})
+		liftedAt := time.Now()
+
+		require.NoError(t, repo.Lift(ctx, sanction.ID, moderatorID, liftedAt))
+		assert.ErrorIs(t, repo.Lift(ctx, sanction.ID, shortuuid.New(), time.Now()), domain.ErrNoDocuments)
+
+		stored, err := repo.GetById(ctx, sanction.ID)
+		require.NoError(t, err)
+		assert.Equal(t, userID, stored.UserID)
+		assert.Equal(t, moderatorID, stored.LiftedBy)
+		assert.WithinDuration(t, liftedAt, stored.LiftedAt, timePrecision)
+	})
+
+	t.Run("created unlifted", func(t *testing.T) {
+		userID := shortuuid.New()
+		sanction := &domain.Sanction{
+			ID: shortuuid.New(), UserID: userID, Type: domain.SanctionBan, IssuedBy: shortuuid.New(),
+			CreatedAt: time.Now(), LiftedBy: shortuuid.New(), LiftedAt: time.Now(),
+		}
+		require.NoError(t, repo.Create(ctx, sanction))
+
+		assert.Equal(t, []string{sanction.ID}, ids(active(t, userID, time.Now())))
+	})
+
+	t.Run("anonymize actor", func(t *testing.T) {
+		userID, moderatorID := shortuuid.New(), shortuuid.New()
+		issued := &domain.Sanction{
+			ID: shortuuid.New(), UserID: userID, Type: domain.SanctionMute, IssuedBy: moderatorID,
+			CreatedAt: time.Now().Add(-time.Minute),
+		}
+		require.NoError(t, repo.Create(ctx, issued))
+		lifted := newSanction(t, userID, time.Now(), time.Time{})
+		require.NoError(t, repo.Lift(ctx, lifted.ID, moderatorID, time.Now()))
+
+		require.NoError(t, repo.AnonymizeActor(ctx, moderatorID, domain.DeletedActor))
+		sanctions := byUser(t, userID)
+		require.Len(t, sanctions, 2)
+		assert.Equal(t, domain.DeletedActor, sanctions[0].LiftedBy)
+		assert.Equal(t, lifted.IssuedBy, sanctions[0].IssuedBy)
+		assert.Equal(t, domain.DeletedActor, sanctions[1].IssuedBy)
+	})
+
+	t.Run("delete by user", func(t *testing.T) {
+		userID, otherUserID := shortuuid.New(), shortuuid.New()
+		newSanction(t, userID, time.Now(), time.Time{})
+		other := newSanction(t, otherUserID, time.Now(), time.Time{})
+
+		require.NoError(t, repo.DeleteByUser(ctx, userID))
+		assert.Empty(t, ids(byUser(t, userID)))
+		assert.Equal(t, []string{other.ID}, ids(byUser(t, otherUserID)))
+		assert.NoError(t, repo.DeleteByUser(ctx, userID))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		userID := shortuuid.New()
+		sanction := newSanction(t, userID, time.Now(), time.Time{})
+
+		assert.Error(t, repo.Create(ctx, sanction))
+		assert.Equal(t, []string{sanction.ID}, ids(byUser(t, userID)))
+	})
+
+	t.Run("concurrent lift", func(t *testing.T) {
+		sanction := newSanction(t, shortuuid.New(), time.Now(), time.Time{})
+
+		errs := race(func(i int) error {
+			return repo.Lift(ctx, sanction.ID, shortuuid.New(), time.Now())
+		})
+		assert.Equal(t, 1, succeeded(errs))
+	})
+}
@@ -0,0 +1,120 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SessionRepository runs session repository contract against repo
+func SessionRepository(t *testing.T, repo repository.SessionRepository) {
+	ctx := context.Background()
+
+	newSession := func(t *testing.T, userID string, lastSeenAt time.Time) *domain.Session {
+		session := &domain.Session{
+			ID:         shortuuid.New(),
+			UserID:     userID,
+			UserAgent:  "Mozilla/5.0",
+			IP:         "192.0.2.1",
+			CreatedAt:  lastSeenAt,
+			LastSeenAt: lastSeenAt,
+		}
+		require.NoError(t, repo.Create(ctx, session))
+		return session
+	}
+	ids := func(t *testing.T, userID string) []string {
+		sessions, err := repo.GetByUser(ctx, userID)
+		require.NoError(t, err)
+		res := []string{}
+		for _, session := range sessions {
+			res = append(res, session.ID)
+		}
+		return res
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Empty(t, ids(t, shortuuid.New()))
+		assert.ErrorIs(t, repo.Touch(ctx, shortuuid.New(), &domain.Device{}, time.Now()), domain.ErrNoDocuments)
+		assert.ErrorIs(t, repo.Delete(ctx, shortuuid.New(), shortuuid.New()), domain.ErrNoDocuments)
+		exists, err := repo.Exists(ctx, shortuuid.New(), shortuuid.New())
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("recently seen first", func(t *testing.T) {
+		userID := shortuuid.New()
+		now := time.Now()
+		old := newSession(t, userID, now.Add(-time.Hour))
+		recent := newSession(t, userID, now.Add(-time.Minute))
+		newSession(t, shortuuid.New(), now)
+
+		assert.Equal(t, []string{recent.ID, old.ID}, ids(t, userID))
+
+		device := &domain.Device{UserAgent: "curl/8.0", IP: "192.0.2.2"}
+		require.NoError(t, repo.Touch(ctx, old.ID, device, now))
+		assert.Equal(t, []string{old.ID, recent.ID}, ids(t, userID))
+
+		sessions, err := repo.GetByUser(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, device.UserAgent, sessions[0].UserAgent)
+		assert.Equal(t, device.IP, sessions[0].IP)
+		assert.WithinDuration(t, now, sessions[0].LastSeenAt, timePrecision)
+		assert.WithinDuration(t, old.CreatedAt, sessions[0].CreatedAt, timePrecision)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		userID := shortuuid.New()
+		session := newSession(t, userID, time.Now())
+
+		exists := func(t *testing.T, userID string) bool {
+			exists, err := repo.Exists(ctx, userID, session.ID)
+			require.NoError(t, err)
+			return exists
+		}
+		assert.True(t, exists(t, userID))
+		assert.False(t, exists(t, shortuuid.New()))
+
+		// session of another user is left as is
+		assert.ErrorIs(t, repo.Delete(ctx, shortuuid.New(), session.ID), domain.ErrNoDocuments)
+		require.NoError(t, repo.Delete(ctx, userID, session.ID))
+		assert.Empty(t, ids(t, userID))
+		assert.False(t, exists(t, userID))
+		assert.ErrorIs(t, repo.Delete(ctx, userID, session.ID), domain.ErrNoDocuments)
+	})
+
+	t.Run("delete by user", func(t *testing.T) {
+		userID, otherUserID := shortuuid.New(), shortuuid.New()
+		newSession(t, userID, time.Now())
+		newSession(t, userID, time.Now())
+		other := newSession(t, otherUserID, time.Now())
+
+		require.NoError(t, repo.DeleteByUser(ctx, userID))
+		assert.Empty(t, ids(t, userID))
+		assert.Equal(t, []string{other.ID}, ids(t, otherUserID))
+		assert.NoError(t, repo.DeleteByUser(ctx, userID))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		userID := shortuuid.New()
+		session := newSession(t, userID, time.Now())
+
+		assert.Error(t, repo.Create(ctx, session))
+		assert.Equal(t, []string{session.ID}, ids(t, userID))
+	})
+
+	t.Run("concurrent delete", func(t *testing.T) {
+		userID := shortuuid.New()
+		session := newSession(t, userID, time.Now())
+
+		errs := race(func(i int) error {
+			return repo.Delete(ctx, userID, session.ID)
+		})
+		assert.Equal(t, 1, succeeded(errs))
+	})
+}
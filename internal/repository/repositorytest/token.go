@@ -0,0 +1,137 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RefreshTokenRepository runs refresh token repository contract against repo
+func RefreshTokenRepository(t *testing.T, repo repository.RefreshTokenRepository) {
+	ctx := context.Background()
+
+	newToken := func(t *testing.T, userID, family string) *domain.RefreshToken {
+		now := time.Now()
+		token := &domain.RefreshToken{
+			Hash:      shortuuid.New(),
+			Family:    family,
+			UserID:    userID,
+			Wallet:    "eip155:1:0x" + userID,
+			ExpiresAt: now.Add(time.Hour),
+			CreatedAt: now,
+		}
+		require.NoError(t, repo.Create(ctx, token))
+		return token
+	}
+	get := func(t *testing.T, hash string) *domain.RefreshToken {
+		token, err := repo.GetByHash(ctx, hash)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.GetByHash(ctx, shortuuid.New())
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+		assert.ErrorIs(t, repo.MarkUsed(ctx, shortuuid.New()), domain.ErrNoDocuments)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		token := newToken(t, shortuuid.New(), shortuuid.New())
+
+		stored := get(t, token.Hash)
+		assert.Equal(t, token.Family, stored.Family)
+		assert.Equal(t, token.UserID, stored.UserID)
+		assert.Equal(t, token.Wallet, stored.Wallet)
+		assert.False(t, stored.Used)
+		assert.False(t, stored.Revoked)
+		assert.WithinDuration(t, token.ExpiresAt, stored.ExpiresAt, timePrecision)
+
+		assert.Error(t, repo.Create(ctx, token))
+	})
+
+	t.Run("mark used once", func(t *testing.T) {
+		token := newToken(t, shortuuid.New(), shortuuid.New())
+
+		require.NoError(t, repo.MarkUsed(ctx, token.Hash))
+		assert.True(t, get(t, token.Hash).Used)
+		assert.ErrorIs(t, repo.MarkUsed(ctx, token.Hash), domain.ErrNoDocuments)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		userID, family := shortuuid.New(), shortuuid.New()
+		rotated := newToken(t, userID, family)
+		current := newToken(t, userID, family)
+		otherDevice := newToken(t, userID, shortuuid.New())
+		otherUser := newToken(t, shortuuid.New(), shortuuid.New())
+
+		require.NoError(t, repo.RevokeFamily(ctx, family))
+		assert.True(t, get(t, rotated.Hash).Revoked)
+		assert.True(t, get(t, current.Hash).Revoked)
+		assert.False(t, get(t, otherDevice.Hash).Revoked)
+
+		require.NoError(t, repo.RevokeUser(ctx, userID))
+		assert.True(t, get(t, otherDevice.Hash).Revoked)
+		assert.False(t, get(t, otherUser.Hash).Revoked)
+
+		// revoking nothing is not an error
+		assert.NoError(t, repo.RevokeFamily(ctx, shortuuid.New()))
+		assert.NoError(t, repo.RevokeUser(ctx, shortuuid.New()))
+	})
+
+	t.Run("get by user", func(t *testing.T) {
+		userID := shortuuid.New()
+		old := &domain.RefreshToken{
+			Hash:      shortuuid.New(),
+			Family:    shortuuid.New(),
+			UserID:    userID,
+			ExpiresAt: time.Now().Add(time.Hour),
+			CreatedAt: time.Now().Add(-time.Hour),
+		}
+		require.NoError(t, repo.Create(ctx, old))
+		recent := newToken(t, userID, shortuuid.New())
+		require.NoError(t, repo.MarkUsed(ctx, recent.Hash))
+		newToken(t, shortuuid.New(), shortuuid.New())
+
+		tokens, err := repo.GetByUser(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.Equal(t, recent.Hash, tokens[0].Hash)
+		assert.Equal(t, recent.Family, tokens[0].Family)
+		assert.True(t, tokens[0].Used)
+		assert.WithinDuration(t, recent.ExpiresAt, tokens[0].ExpiresAt, timePrecision)
+		assert.Equal(t, old.Hash, tokens[1].Hash)
+
+		tokens, err = repo.GetByUser(ctx, shortuuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("delete by user", func(t *testing.T) {
+		userID := shortuuid.New()
+		token := newToken(t, userID, shortuuid.New())
+		otherUser := newToken(t, shortuuid.New(), shortuuid.New())
+
+		require.NoError(t, repo.DeleteByUser(ctx, userID))
+		_, err := repo.GetByHash(ctx, token.Hash)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+		get(t, otherUser.Hash)
+
+		assert.NoError(t, repo.DeleteByUser(ctx, userID))
+	})
+
+	t.Run("concurrent mark used", func(t *testing.T) {
+		token := newToken(t, shortuuid.New(), shortuuid.New())
+
+		// token is rotated once, other requests presenting it are replays
+		errs := race(func(i int) error {
+			return repo.MarkUsed(ctx, token.Hash)
+		})
+		assert.Equal(t, 1, succeeded(errs))
+	})
+}
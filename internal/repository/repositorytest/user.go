@@ -0,0 +1,416 @@
+package repositorytest
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/repository"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UserRepository runs user repository contract against repo
+func UserRepository(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+
+	// newUser returns player with unique id, nickname and wallet
+	newUser := func() *domain.User {
+		id := shortuuid.New()
+		return &domain.User{
+			ID:        id,
+			Nickname:  "u" + id,
+			Wallet:    "eip155:1:0x" + id,
+			CreatedAt: time.Now(),
+			Role:      domain.RolePlayer,
+		}
+	}
+	create := func(t *testing.T, user *domain.User) *domain.User {
+		require.NoError(t, repo.Create(ctx, user))
+		return user
+	}
+	get := func(t *testing.T, id string) *domain.User {
+		user, err := repo.GetById(ctx, id)
+		require.NoError(t, err)
+		return user
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		id := shortuuid.New()
+
+		_, err := repo.GetById(ctx, id)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+		_, err = repo.GetByWallet(ctx, "eip155:1:0x"+id)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+		_, err = repo.GetByDevice(ctx, id)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		user := newUser()
+		user.Wallets = []string{"eip155:1:0xlinked" + user.ID}
+		user.Bio = "not stored, profile is filled by updates"
+		create(t, user)
+
+		stored := get(t, user.ID)
+		assert.Equal(t, user.Nickname, stored.Nickname)
+		assert.Equal(t, user.Wallet, stored.Wallet)
+		assert.Equal(t, user.Wallets, stored.Wallets)
+		assert.Equal(t, domain.RolePlayer, stored.Role)
+		assert.WithinDuration(t, user.CreatedAt, stored.CreatedAt, timePrecision)
+		assert.Empty(t, stored.Bio)
+		assert.True(t, stored.NicknameChangedAt.IsZero())
+		assert.True(t, stored.DeleteRequestedAt.IsZero())
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		user := create(t, newUser())
+
+		stored := get(t, user.ID)
+		stored.Nickname = "changed"
+		stored.Wallets = append(stored.Wallets, "eip155:1:0xchanged")
+
+		assert.Equal(t, user.Nickname, get(t, user.ID).Nickname)
+		assert.Empty(t, get(t, user.ID).Wallets)
+
+		linked := "eip155:1:0xlinked" + user.ID
+		require.NoError(t, repo.AddWallet(ctx, user.ID, linked))
+		stored = get(t, user.ID)
+		stored.Wallets[0] = "eip155:1:0xchanged"
+
+		stored, err := repo.GetByWallet(ctx, linked)
+		require.NoError(t, err)
+		assert.Equal(t, []string{linked}, stored.Wallets)
+	})
+
+	t.Run("nickname taken ignoring case", func(t *testing.T) {
+		user := create(t, newUser())
+
+		taken := newUser()
+		taken.Nickname = strings.ToUpper(user.Nickname)
+		assert.ErrorIs(t, repo.Create(ctx, taken), domain.ErrNicknameTaken)
+
+		other := create(t, newUser())
+		other.Nickname = strings.ToUpper(user.Nickname)
+		assert.ErrorIs(t, repo.Update(ctx, other), domain.ErrNicknameTaken)
+
+		// user changes case of own nickname
+		user.Nickname = strings.ToUpper(user.Nickname)
+		assert.NoError(t, repo.Update(ctx, user))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		user := create(t, newUser())
+
+		user.Nickname = "n" + user.ID
+		user.NicknameChangedAt = time.Now()
+		user.Avatar = domain.Avatar{URL: "https://example.com/avatar.png"}
+		user.Bio = "gg"
+		user.Country = "DE"
+		user.Privacy = domain.Privacy{HideCountry: true, ShowWallet: true}
+		require.NoError(t, repo.Update(ctx, user))
+
+		stored := get(t, user.ID)
+		assert.Equal(t, user.Nickname, stored.Nickname)
+		assert.WithinDuration(t, user.NicknameChangedAt, stored.NicknameChangedAt, timePrecision)
+		assert.Equal(t, user.Avatar, stored.Avatar)
+		assert.Equal(t, user.Bio, stored.Bio)
+		assert.Equal(t, user.Country, stored.Country)
+		assert.Equal(t, user.Privacy, stored.Privacy)
+
+		assert.ErrorIs(t, repo.Update(ctx, newUser()), domain.ErrNoDocuments)
+	})
+
+	t.Run("wallets", func(t *testing.T) {
+		user := create(t, newUser())
+		linked := "eip155:1:0xlinked" + user.ID
+
+		require.NoError(t, repo.AddWallet(ctx, user.ID, linked))
+		// linking the same wallet again changes nothing
+		require.NoError(t, repo.AddWallet(ctx, user.ID, linked))
+		assert.Equal(t, []string{linked}, get(t, user.ID).Wallets)
+
+		// one wallet can't be linked to two users
+		other := create(t, newUser())
+		assert.ErrorIs(t, repo.AddWallet(ctx, other.ID, linked), domain.ErrAlreadyExists)
+		assert.Empty(t, get(t, other.ID).Wallets)
+		// nor be primary wallet of one user and linked wallet of another
+		assert.ErrorIs(t, repo.AddWallet(ctx, other.ID, user.Wallet), domain.ErrAlreadyExists)
+		assert.Empty(t, get(t, other.ID).Wallets)
+		primaryLinked := newUser()
+		primaryLinked.Wallet = linked
+		assert.ErrorIs(t, repo.Create(ctx, primaryLinked), domain.ErrAlreadyExists)
+
+		byLinked, err := repo.GetByWallet(ctx, linked)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, byLinked.ID)
+		byPrimary, err := repo.GetByWallet(ctx, user.Wallet)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, byPrimary.ID)
+
+		assert.ErrorIs(t, repo.ReplacePrimaryWallet(ctx, user.ID, linked, user.Wallet), domain.ErrNoDocuments)
+		require.NoError(t, repo.ReplacePrimaryWallet(ctx, user.ID, user.Wallet, linked))
+		stored := get(t, user.ID)
+		assert.Equal(t, linked, stored.Wallet)
+		assert.Empty(t, stored.Wallets)
+		_, err = repo.GetByWallet(ctx, user.Wallet)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+
+		require.NoError(t, repo.AddWallet(ctx, user.ID, user.Wallet))
+		require.NoError(t, repo.RemoveWallet(ctx, user.ID, user.Wallet))
+		assert.ErrorIs(t, repo.RemoveWallet(ctx, user.ID, user.Wallet), domain.ErrNoDocuments)
+		assert.Empty(t, get(t, user.ID).Wallets)
+
+		assert.ErrorIs(t, repo.AddWallet(ctx, shortuuid.New(), linked), domain.ErrNoDocuments)
+	})
+
+	t.Run("set role", func(t *testing.T) {
+		user := create(t, newUser())
+
+		assert.ErrorIs(t, repo.SetRole(ctx, user.ID, domain.RoleAdmin, domain.RoleModerator), domain.ErrNoDocuments)
+		require.NoError(t, repo.SetRole(ctx, user.ID, domain.RoleModerator, domain.RolePlayer))
+		assert.Equal(t, domain.RoleModerator, get(t, user.ID).Role)
+
+		assert.ErrorIs(t, repo.SetRole(ctx, shortuuid.New(), domain.RoleModerator, domain.RolePlayer), domain.ErrNoDocuments)
+	})
+
+	t.Run("upgrade guest", func(t *testing.T) {
+		guest := newUser()
+		guest.Wallet = ""
+		guest.Role = domain.RoleGuest
+		guest.Device = "device" + guest.ID
+		create(t, guest)
+
+		byDevice, err := repo.GetByDevice(ctx, guest.Device)
+		require.NoError(t, err)
+		assert.Equal(t, guest.ID, byDevice.ID)
+
+		wallet := "eip155:1:0x" + guest.ID
+		require.NoError(t, repo.UpgradeGuest(ctx, guest.ID, wallet))
+		stored := get(t, guest.ID)
+		assert.Equal(t, wallet, stored.Wallet)
+		assert.Equal(t, domain.RolePlayer, stored.Role)
+		assert.Empty(t, stored.Device)
+		_, err = repo.GetByDevice(ctx, guest.Device)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+
+		// player is not guest anymore
+		assert.ErrorIs(t, repo.UpgradeGuest(ctx, guest.ID, wallet), domain.ErrNoDocuments)
+
+		// wallet linked to another user can't become primary wallet of guest
+		other := create(t, newUser())
+		linked := "eip155:1:0xlinked" + other.ID
+		require.NoError(t, repo.AddWallet(ctx, other.ID, linked))
+		guest = newUser()
+		guest.Wallet = ""
+		guest.Role = domain.RoleGuest
+		guest.Device = "device" + guest.ID
+		create(t, guest)
+		assert.ErrorIs(t, repo.UpgradeGuest(ctx, guest.ID, linked), domain.ErrAlreadyExists)
+		assert.True(t, get(t, guest.ID).IsGuest())
+	})
+
+	t.Run("search", func(t *testing.T) {
+		prefix := "s" + strings.ToLower(shortuuid.New())
+		nicknames := []string{prefix + "B", prefix + "a", prefix + "c", prefix + "D"}
+		for _, nickname := range nicknames {
+			user := newUser()
+			user.Nickname = nickname
+			create(t, user)
+		}
+		pending := newUser()
+		pending.Nickname = prefix + "e"
+		create(t, pending)
+		require.NoError(t, repo.ScheduleDeletion(ctx, pending.ID, time.Now()))
+
+		search := func(query, after string, limit int) []string {
+			users, err := repo.Search(ctx, query, after, limit)
+			require.NoError(t, err)
+			res := []string{}
+			for _, user := range users {
+				res = append(res, user.Nickname)
+			}
+			return res
+		}
+
+		assert.Equal(t, []string{prefix + "a", prefix + "B", prefix + "c", prefix + "D"}, search(strings.ToUpper(prefix), "", 10))
+		assert.Equal(t, []string{prefix + "a", prefix + "B"}, search(prefix, "", 2))
+		assert.Equal(t, []string{prefix + "c", prefix + "D"}, search(prefix, prefix+"b", 10))
+		assert.Empty(t, search(prefix+"x", "", 10))
+
+		// pending user is still due for deletion otherwise
+		require.NoError(t, repo.Delete(ctx, pending.ID, time.Now()))
+	})
+
+	t.Run("deletion", func(t *testing.T) {
+		user := create(t, newUser())
+		requestedAt := time.Now().Add(-time.Hour)
+
+		require.NoError(t, repo.ScheduleDeletion(ctx, user.ID, requestedAt))
+		assert.WithinDuration(t, requestedAt, get(t, user.ID).DeleteRequestedAt, timePrecision)
+
+		due := func(before time.Time) bool {
+			users, err := repo.GetDeletionDue(ctx, before, 1000)
+			require.NoError(t, err)
+			for _, due := range users {
+				if due.ID == user.ID {
+					return true
+				}
+			}
+			return false
+		}
+		assert.True(t, due(time.Now()))
+		assert.False(t, due(requestedAt.Add(-time.Minute)))
+
+		require.NoError(t, repo.ScheduleDeletion(ctx, user.ID, time.Time{}))
+		assert.True(t, get(t, user.ID).DeleteRequestedAt.IsZero())
+		assert.False(t, due(time.Now()))
+
+		assert.ErrorIs(t, repo.ScheduleDeletion(ctx, shortuuid.New(), time.Now()), domain.ErrNoDocuments)
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		user := create(t, newUser())
+		guest := newUser()
+		guest.Wallet = ""
+		guest.Role = domain.RoleGuest
+		guest.Device = "device" + guest.ID
+		create(t, guest)
+
+		duplicateID := newUser()
+		duplicateID.ID = user.ID
+		assert.ErrorIs(t, repo.Create(ctx, duplicateID), domain.ErrAlreadyExists)
+		assert.Equal(t, user.Nickname, get(t, user.ID).Nickname)
+
+		duplicateWallet := newUser()
+		duplicateWallet.Wallet = user.Wallet
+		assert.ErrorIs(t, repo.Create(ctx, duplicateWallet), domain.ErrAlreadyExists)
+
+		duplicateDevice := newUser()
+		duplicateDevice.Wallet = ""
+		duplicateDevice.Role = domain.RoleGuest
+		duplicateDevice.Device = guest.Device
+		assert.ErrorIs(t, repo.Create(ctx, duplicateDevice), domain.ErrAlreadyExists)
+	})
+
+	t.Run("concurrent create", func(t *testing.T) {
+		nickname := "c" + shortuuid.New()
+
+		errs := race(func(i int) error {
+			user := newUser()
+			user.Nickname = nickname
+			if i%2 == 0 {
+				user.Nickname = strings.ToUpper(nickname)
+			}
+			return repo.Create(ctx, user)
+		})
+
+		// nicknames differ only in case, so just one user gets it
+		assert.Equal(t, 1, succeeded(errs))
+		for _, err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, domain.ErrNicknameTaken)
+			}
+		}
+	})
+
+	t.Run("concurrent create with wallet", func(t *testing.T) {
+		wallet := newUser().Wallet
+
+		errs := race(func(i int) error {
+			user := newUser()
+			user.Wallet = wallet
+			return repo.Create(ctx, user)
+		})
+
+		// logins with new wallet create one user, others find it exists
+		assert.Equal(t, 1, succeeded(errs))
+		for _, err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+			}
+		}
+	})
+
+	t.Run("concurrent conditional updates", func(t *testing.T) {
+		user := create(t, newUser())
+		errs := race(func(i int) error {
+			return repo.SetRole(ctx, user.ID, domain.RoleModerator, domain.RolePlayer)
+		})
+		assert.Equal(t, 1, succeeded(errs))
+
+		guest := newUser()
+		guest.Wallet = ""
+		guest.Role = domain.RoleGuest
+		guest.Device = "device" + guest.ID
+		create(t, guest)
+		errs = race(func(i int) error {
+			return repo.UpgradeGuest(ctx, guest.ID, "eip155:1:0x"+guest.ID+strconv.Itoa(i))
+		})
+		assert.Equal(t, 1, succeeded(errs))
+
+		linked := "eip155:1:0xlinked" + user.ID
+		require.NoError(t, repo.AddWallet(ctx, user.ID, linked))
+		errs = race(func(i int) error {
+			return repo.RemoveWallet(ctx, user.ID, linked)
+		})
+		assert.Equal(t, 1, succeeded(errs))
+		for _, err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, domain.ErrNoDocuments)
+			}
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		user := create(t, newUser())
+		requestedAt := time.Now().Add(-time.Hour)
+
+		// user who didn't ask for deletion, asked later or cancelled it is kept
+		assert.ErrorIs(t, repo.Delete(ctx, user.ID, time.Now()), domain.ErrNoDocuments)
+		require.NoError(t, repo.ScheduleDeletion(ctx, user.ID, requestedAt))
+		assert.ErrorIs(t, repo.Delete(ctx, user.ID, requestedAt.Add(-time.Minute)), domain.ErrNoDocuments)
+		require.NoError(t, repo.ScheduleDeletion(ctx, user.ID, time.Time{}))
+		assert.ErrorIs(t, repo.Delete(ctx, user.ID, time.Now()), domain.ErrNoDocuments)
+		get(t, user.ID)
+
+		require.NoError(t, repo.ScheduleDeletion(ctx, user.ID, requestedAt))
+		require.NoError(t, repo.Delete(ctx, user.ID, time.Now()))
+		_, err := repo.GetById(ctx, user.ID)
+		assert.ErrorIs(t, err, domain.ErrNoDocuments)
+		assert.ErrorIs(t, repo.Delete(ctx, user.ID, time.Now()), domain.ErrNoDocuments)
+
+		// nickname of deleted user is free
+		create(t, &domain.User{ID: shortuuid.New(), Nickname: user.Nickname, CreatedAt: time.Now(), Role: domain.RolePlayer})
+	})
+
+	t.Run("claim purge", func(t *testing.T) {
+		user := create(t, newUser())
+		requestedAt := time.Now().Add(-time.Hour)
+
+		// user who didn't ask for deletion or asked later isn't claimed
+		assert.ErrorIs(t, repo.ClaimPurge(ctx, user.ID, time.Now()), domain.ErrNoDocuments)
+		require.NoError(t, repo.ScheduleDeletion(ctx, user.ID, requestedAt))
+		assert.ErrorIs(t, repo.ClaimPurge(ctx, user.ID, requestedAt.Add(-time.Minute)), domain.ErrNoDocuments)
+		assert.True(t, get(t, user.ID).PurgeStartedAt.IsZero())
+		assert.ErrorIs(t, repo.ClaimPurge(ctx, shortuuid.New(), time.Now()), domain.ErrNoDocuments)
+
+		require.NoError(t, repo.ClaimPurge(ctx, user.ID, time.Now()))
+		claimedAt := get(t, user.ID).PurgeStartedAt
+		assert.WithinDuration(t, time.Now(), claimedAt, time.Minute)
+
+		// purge resumed after interruption keeps the first claim
+		require.NoError(t, repo.ClaimPurge(ctx, user.ID, time.Now()))
+		assert.WithinDuration(t, claimedAt, get(t, user.ID).PurgeStartedAt, timePrecision)
+
+		// deletion can't be cancelled once purge started
+		assert.ErrorIs(t, repo.ScheduleDeletion(ctx, user.ID, time.Time{}), domain.ErrNoDocuments)
+		assert.WithinDuration(t, requestedAt, get(t, user.ID).DeleteRequestedAt, timePrecision)
+
+		require.NoError(t, repo.Delete(ctx, user.ID, time.Now()))
+	})
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"server/internal/domain"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	accountErrorPrefix = "[service.account]"
+)
+
+// purgeBatchSize bounds number of users purged in one run
+const purgeBatchSize = 100
+
+type AccountServiceConfig struct {
+	// DeletionGracePeriod is how long player can cancel deletion by logging in
+	DeletionGracePeriod time.Duration
+	// PurgeInterval is how often accounts past grace period are purged
+	PurgeInterval time.Duration
+}
+
+// AccountService handles personal data of player: export and account deletion
+type AccountService struct {
+	userRepository         UserRepository
+	sessionRepository      SessionRepository
+	refreshTokenRepository RefreshTokenRepository
+	revocationRepository   RevocationRepository
+	roleChangeRepository   RoleChangeRepository
+	sanctionRepository     SanctionRepository
+	apiKeyRepository       APIKeyRepository
+	cfg                    AccountServiceConfig
+}
+
+func NewAccountService(
+	userRepository UserRepository,
+	sessionRepository SessionRepository,
+	refreshTokenRepository RefreshTokenRepository,
+	revocationRepository RevocationRepository,
+	roleChangeRepository RoleChangeRepository,
+	sanctionRepository SanctionRepository,
+	apiKeyRepository APIKeyRepository,
+	cfg AccountServiceConfig,
+) *AccountService {
+	return &AccountService{
+		userRepository,
+		sessionRepository,
+		refreshTokenRepository,
+		revocationRepository,
+		roleChangeRepository,
+		sanctionRepository,
+		apiKeyRepository,
+		cfg,
+	}
+}
+
+// RequestDeletion puts account to pending deletion state and returns time it's purged at.
+// Account is hidden from other players meanwhile, logging in again cancels deletion.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID string) (time.Time, error) {
+	user, err := s.userRepository.GetById(ctx, userID)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "%s: request deletion", accountErrorPrefix)
+	}
+	if !user.DeleteRequestedAt.IsZero() {
+		return user.DeleteRequestedAt.Add(s.cfg.DeletionGracePeriod), nil
+	}
+
+	now := time.Now()
+	err = s.userRepository.ScheduleDeletion(ctx, userID, now)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "%s: request deletion", accountErrorPrefix)
+	}
+
+	return now.Add(s.cfg.DeletionGracePeriod), nil
+}
+
+// Export collects everything stored about user. Sanctions kept for its wallets are included,
+// staff who changed its role or sanctioned it are exported as domain.StaffActor.
+func (s *AccountService) Export(ctx context.Context, userID string) (*domain.UserExport, error) {
+	user, err := s.userRepository.GetById(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: export user", accountErrorPrefix)
+	}
+
+	sessions, err := s.sessionRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: export sessions", accountErrorPrefix)
+	}
+
+	refreshTokens, err := s.refreshTokenRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: export refresh tokens", accountErrorPrefix)
+	}
+
+	roleChanges, err := s.roleChangeRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: export role changes", accountErrorPrefix)
+	}
+	for _, change := range roleChanges {
+		change.ChangedBy = staffActor(userID, change.ChangedBy)
+	}
+
+	sanctions, err := s.sanctionRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: export sanctions", accountErrorPrefix)
+	}
+	for _, wallet := range user.AllWallets() {
+		walletSanctions, err := s.sanctionRepository.GetByUser(ctx, domain.WalletSubject(wallet))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: export sanctions of wallet", accountErrorPrefix)
+		}
+		sanctions = append(sanctions, walletSanctions...)
+	}
+	for _, sanction := range sanctions {
+		sanction.IssuedBy = staffActor(userID, sanction.IssuedBy)
+		sanction.LiftedBy = staffActor(userID, sanction.LiftedBy)
+	}
+
+	keys, err := s.apiKeyRepository.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: export api keys", accountErrorPrefix)
+	}
+	apiKeys := make([]*domain.APIKey, 0)
+	for _, key := range keys {
+		if key.CreatedBy == userID {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+
+	return &domain.UserExport{
+		User:          user,
+		Sessions:      sessions,
+		RefreshTokens: refreshTokens,
+		RoleChanges:   roleChanges,
+		Sanctions:     sanctions,
+		APIKeys:       apiKeys,
+		ExportedAt:    time.Now(),
+	}, nil
+}
+
+// staffActor hides id of staff member who authored change to user, user itself and
+// non-personal actors are kept
+func staffActor(userID, actor string) string {
+	if actor == "" || actor == userID || actor == domain.SystemActor || actor == domain.DeletedActor {
+		return actor
+	}
+	return domain.StaffActor
+}
+
+// Purge deletes accounts which grace period is over and returns number of deleted accounts.
+// Account failing to be purged doesn't hold back others, errors of all failed accounts are returned together.
+func (s *AccountService) Purge(ctx context.Context) (int, error) {
+	before := time.Now().Add(-s.cfg.DeletionGracePeriod)
+	users, err := s.userRepository.GetDeletionDue(ctx, before, purgeBatchSize)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s: purge", accountErrorPrefix)
+	}
+
+	purged := 0
+	errs := []error{}
+	for _, user := range users {
+		deleted, err := s.purgeUser(ctx, user, before)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if deleted {
+			purged++
+		}
+	}
+	return purged, stderrors.Join(errs...)
+}
+
+// purgeUser removes user data from all repositories and anonymizes records user authored as staff.
+// Bans and suspensions are kept for wallets of user. Nonces aren't purged, they are bound to wallet
+// and expire within minutes. Purge is claimed first, so user can't cancel deletion by logging in
+// while its data is removed, and user who cancelled deletion before is kept, false is returned then.
+// User is deleted last, so purge interrupted midway is repeated on next run.
+func (s *AccountService) purgeUser(ctx context.Context, user *domain.User, before time.Time) (bool, error) {
+	userID := user.ID
+	err := s.userRepository.ClaimPurge(ctx, userID, before)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "%s: claim purge of %s", accountErrorPrefix, userID)
+	}
+	// sanctions are kept for wallets user has now, it could link one since it was listed
+	user, err = s.userRepository.GetById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "%s: purge user %s", accountErrorPrefix, userID)
+	}
+
+	err = s.sessionRepository.DeleteByUser(ctx, userID)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: purge sessions of %s", accountErrorPrefix, userID)
+	}
+
+	err = s.refreshTokenRepository.DeleteByUser(ctx, userID)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: purge refresh tokens of %s", accountErrorPrefix, userID)
+	}
+
+	err = s.revocationRepository.DeleteByUser(ctx, userID)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: purge revocations of %s", accountErrorPrefix, userID)
+	}
+
+	err = s.roleChangeRepository.DeleteByUser(ctx, userID)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: purge role changes of %s", accountErrorPrefix, userID)
+	}
+	err = s.roleChangeRepository.AnonymizeActor(ctx, userID, domain.DeletedActor)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: anonymize role changes by %s", accountErrorPrefix, userID)
+	}
+
+	err = s.keepWalletSanctions(ctx, user)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: keep sanctions of %s", accountErrorPrefix, userID)
+	}
+	err = s.sanctionRepository.DeleteByUser(ctx, userID)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: purge sanctions of %s", accountErrorPrefix, userID)
+	}
+	err = s.sanctionRepository.AnonymizeActor(ctx, userID, domain.DeletedActor)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: anonymize sanctions by %s", accountErrorPrefix, userID)
+	}
+
+	err = s.apiKeyRepository.AnonymizeActor(ctx, userID, domain.DeletedActor)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: anonymize api keys by %s", accountErrorPrefix, userID)
+	}
+
+	// user is gone once another instance purging it concurrently deleted it
+	err = s.userRepository.Delete(ctx, userID, before)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "%s: purge user %s", accountErrorPrefix, userID)
+	}
+	return true, nil
+}
+
+// keepWalletSanctions copies active bans and suspensions of user to its wallets, so banned player
+// can't sign up again clean. Copies have ids derived from sanction and wallet, repeated purge skips them.
+func (s *AccountService) keepWalletSanctions(ctx context.Context, user *domain.User) error {
+	sanctions, err := s.sanctionRepository.GetActive(ctx, user.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, sanction := range sanctions {
+		if !sanction.BlocksLogin() {
+			continue
+		}
+		for _, wallet := range user.AllWallets() {
+			kept := *sanction
+			kept.ID = shortuuid.NewWithNamespace(sanction.ID + " " + wallet)
+			kept.UserID = domain.WalletSubject(wallet)
+
+			_, err = s.sanctionRepository.GetById(ctx, kept.ID)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, domain.ErrNoDocuments) {
+				return err
+			}
+			err = s.sanctionRepository.Create(ctx, &kept)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Run purges accounts on schedule until context is done
+func (s *AccountService) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(s.cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.Purge(ctx)
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"server/internal/domain"
+	"server/internal/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testAccountServiceConfig = AccountServiceConfig{
+	DeletionGracePeriod: 30 * 24 * time.Hour,
+	PurgeInterval:       time.Hour,
+}
+
+func TestAccountService_RequestDeletion(t *testing.T) {
+	requestedAt := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository)
+		purgeAt      time.Time
+	}{
+		{
+			name: "schedule deletion",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user"}, nil)
+				userRepo.On("ScheduleDeletion", ctx, "user", mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "deletion already pending",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", DeleteRequestedAt: requestedAt}, nil)
+			},
+			purgeAt: requestedAt.Add(testAccountServiceConfig.DeletionGracePeriod),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		accountService := NewAccountService(userRepo, mocks.NewSessionRepository(t), mocks.NewRefreshTokenRepository(t),
+			mocks.NewRevocationRepository(t), mocks.NewRoleChangeRepository(t), mocks.NewSanctionRepository(t), mocks.NewAPIKeyRepository(t), testAccountServiceConfig)
+
+		test.expectations(ctx, userRepo)
+
+		purgeAt, err := accountService.RequestDeletion(ctx, "user")
+		require.NoError(t, err)
+		if test.purgeAt.IsZero() {
+			assert.WithinDuration(t, time.Now().Add(testAccountServiceConfig.DeletionGracePeriod), purgeAt, time.Minute)
+		} else {
+			assert.Equal(t, test.purgeAt, purgeAt)
+		}
+	}
+}
+
+func TestAccountService_Purge(t *testing.T) {
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	sessionRepo := mocks.NewSessionRepository(t)
+	tokenRepo := mocks.NewRefreshTokenRepository(t)
+	revocationRepo := mocks.NewRevocationRepository(t)
+	roleChangeRepo := mocks.NewRoleChangeRepository(t)
+	sanctionRepo := mocks.NewSanctionRepository(t)
+	apiKeyRepo := mocks.NewAPIKeyRepository(t)
+	accountService := NewAccountService(userRepo, sessionRepo, tokenRepo, revocationRepo, roleChangeRepo, sanctionRepo, apiKeyRepo, testAccountServiceConfig)
+
+	due := mock.MatchedBy(func(before time.Time) bool {
+		return before.Before(time.Now().Add(-testAccountServiceConfig.DeletionGracePeriod + time.Minute))
+	})
+	requestedAt := time.Now().Add(-testAccountServiceConfig.DeletionGracePeriod - time.Hour)
+	userRepo.On("GetDeletionDue", ctx, due, purgeBatchSize).Return([]*domain.User{
+		{ID: "failing", Wallet: "failing"},
+		{ID: "user", Wallet: "primary"},
+		{ID: "purged", Wallet: "purged"},
+		{ID: "cancelled", Wallet: "cancelled"},
+	}, nil)
+	for _, id := range []string{"failing", "user", "purged"} {
+		userRepo.On("ClaimPurge", ctx, id, due).Return(nil)
+	}
+	for _, id := range []string{"failing", "purged"} {
+		userRepo.On("GetById", ctx, id).Return(&domain.User{ID: id, Wallet: id, DeleteRequestedAt: requestedAt}, nil)
+	}
+	// wallet linked after user was found due is banned too
+	userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Wallet: "primary", Wallets: []string{"linked"}, DeleteRequestedAt: requestedAt}, nil)
+	// user logged in after being found due, nothing is purged
+	userRepo.On("ClaimPurge", ctx, "cancelled", due).Return(domain.ErrNoDocuments)
+	// failing user doesn't hold back others
+	sessionRepo.On("DeleteByUser", ctx, "failing").Return(errors.New("unavailable"))
+
+	// ban is kept for both wallets, mute isn't kept
+	ban := &domain.Sanction{ID: "ban", UserID: "user", Type: domain.SanctionBan, Reason: "cheating"}
+	mute := &domain.Sanction{ID: "mute", UserID: "user", Type: domain.SanctionMute, ExpiresAt: time.Now().Add(time.Hour)}
+	sanctionRepo.On("GetActive", ctx, "user", mock.AnythingOfType("time.Time")).Return([]*domain.Sanction{ban, mute}, nil)
+	sanctionRepo.On("GetById", ctx, mock.AnythingOfType("string")).Return(nil, domain.ErrNoDocuments).Twice()
+	for _, wallet := range []string{"primary", "linked"} {
+		wallet := wallet
+		sanctionRepo.On("Create", ctx, mock.MatchedBy(func(sanction *domain.Sanction) bool {
+			return sanction.UserID == domain.WalletSubject(wallet) && sanction.ID != ban.ID &&
+				sanction.Type == domain.SanctionBan && sanction.Reason == ban.Reason
+		})).Return(nil).Once()
+	}
+	// ban was kept by purge interrupted before
+	suspension := &domain.Sanction{ID: "suspension", UserID: "purged", Type: domain.SanctionSuspension, ExpiresAt: time.Now().Add(time.Hour)}
+	sanctionRepo.On("GetActive", ctx, "purged", mock.AnythingOfType("time.Time")).Return([]*domain.Sanction{suspension}, nil)
+	sanctionRepo.On("GetById", ctx, mock.AnythingOfType("string")).Return(&domain.Sanction{}, nil).Once()
+
+	for _, id := range []string{"user", "purged"} {
+		sessionRepo.On("DeleteByUser", ctx, id).Return(nil)
+		tokenRepo.On("DeleteByUser", ctx, id).Return(nil)
+		revocationRepo.On("DeleteByUser", ctx, id).Return(nil)
+		roleChangeRepo.On("DeleteByUser", ctx, id).Return(nil)
+		roleChangeRepo.On("AnonymizeActor", ctx, id, domain.DeletedActor).Return(nil)
+		sanctionRepo.On("DeleteByUser", ctx, id).Return(nil)
+		sanctionRepo.On("AnonymizeActor", ctx, id, domain.DeletedActor).Return(nil)
+		apiKeyRepo.On("AnonymizeActor", ctx, id, domain.DeletedActor).Return(nil)
+	}
+	userRepo.On("Delete", ctx, "user", due).Return(nil)
+	// user was purged by another instance meanwhile
+	userRepo.On("Delete", ctx, "purged", due).Return(domain.ErrNoDocuments)
+
+	purged, err := accountService.Purge(ctx)
+	assert.ErrorContains(t, err, "unavailable")
+	assert.Equal(t, 1, purged)
+}
+
+func TestAccountService_Export(t *testing.T) {
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	sessionRepo := mocks.NewSessionRepository(t)
+	refreshTokenRepo := mocks.NewRefreshTokenRepository(t)
+	roleChangeRepo := mocks.NewRoleChangeRepository(t)
+	sanctionRepo := mocks.NewSanctionRepository(t)
+	apiKeyRepo := mocks.NewAPIKeyRepository(t)
+	accountService := NewAccountService(userRepo, sessionRepo, refreshTokenRepo,
+		mocks.NewRevocationRepository(t), roleChangeRepo, sanctionRepo, apiKeyRepo, testAccountServiceConfig)
+
+	userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Wallet: "primary", Wallets: []string{"linked"}}, nil)
+	sessionRepo.On("GetByUser", ctx, "user").Return([]*domain.Session{{ID: "session"}}, nil)
+	refreshTokenRepo.On("GetByUser", ctx, "user").Return([]*domain.RefreshToken{{Hash: "hash", Family: "session"}}, nil)
+	roleChangeRepo.On("GetByUser", ctx, "user").Return([]*domain.RoleChange{
+		{ID: "promoted", ChangedBy: "admin"},
+		{ID: "bootstrap", ChangedBy: domain.SystemActor},
+	}, nil)
+	sanctionRepo.On("GetByUser", ctx, "user").Return([]*domain.Sanction{{ID: "sanction", IssuedBy: "moderator", LiftedBy: "admin"}}, nil)
+	sanctionRepo.On("GetByUser", ctx, domain.WalletSubject("primary")).Return([]*domain.Sanction{{ID: "kept", IssuedBy: domain.DeletedActor}}, nil)
+	sanctionRepo.On("GetByUser", ctx, domain.WalletSubject("linked")).Return([]*domain.Sanction{}, nil)
+	apiKeyRepo.On("GetAll", ctx).Return([]*domain.APIKey{{ID: "own", CreatedBy: "user"}, {ID: "other", CreatedBy: "admin"}}, nil)
+
+	export, err := accountService.Export(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "user", export.User.ID)
+	assert.Len(t, export.Sessions, 1)
+	require.Len(t, export.RefreshTokens, 1)
+	assert.Equal(t, "session", export.RefreshTokens[0].Family)
+	require.Len(t, export.APIKeys, 1)
+	assert.Equal(t, "own", export.APIKeys[0].ID)
+
+	// staff identities are redacted
+	require.Len(t, export.RoleChanges, 2)
+	assert.Equal(t, domain.StaffActor, export.RoleChanges[0].ChangedBy)
+	assert.Equal(t, domain.SystemActor, export.RoleChanges[1].ChangedBy)
+	require.Len(t, export.Sanctions, 2)
+	assert.Equal(t, "sanction", export.Sanctions[0].ID)
+	assert.Equal(t, domain.StaffActor, export.Sanctions[0].IssuedBy)
+	assert.Equal(t, domain.StaffActor, export.Sanctions[0].LiftedBy)
+	assert.Equal(t, "kept", export.Sanctions[1].ID)
+	assert.Equal(t, domain.DeletedActor, export.Sanctions[1].IssuedBy)
+	assert.Empty(t, export.Sanctions[1].LiftedBy)
+}
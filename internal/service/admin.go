@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"server/internal/domain"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	adminErrorPrefix = "[service.admin]"
+)
+
+//go:generate mockery --dir . --name RoleChangeRepository --output ./mocks
+type RoleChangeRepository interface {
+	Create(context.Context, *domain.RoleChange) error
+	GetByUser(context.Context, string) ([]*domain.RoleChange, error)
+	DeleteByUser(context.Context, string) error
+	AnonymizeActor(context.Context, string, string) error
+}
+
+//go:generate mockery --dir . --name TokenRevoker --output ./mocks
+type TokenRevoker interface {
+	LogoutAll(context.Context, string) error
+}
+
+// AdminService is user management available to staff
+type AdminService struct {
+	userRepository       UserRepository
+	roleChangeRepository RoleChangeRepository
+	tokenRevoker         TokenRevoker
+	nicknameGenerator    NicknameGenerator
+}
+
+func NewAdminService(
+	userRepository UserRepository,
+	roleChangeRepository RoleChangeRepository,
+	tokenRevoker TokenRevoker,
+	nicknameGenerator NicknameGenerator,
+) *AdminService {
+	return &AdminService{userRepository, roleChangeRepository, tokenRevoker, nicknameGenerator}
+}
+
+// GetUser returns user by id
+func (s *AdminService) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	user, err := s.userRepository.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: user %s", adminErrorPrefix, id)
+		}
+		return nil, errors.Wrapf(err, "%s: get user", adminErrorPrefix)
+	}
+	return user, nil
+}
+
+// GetUserByWallet returns user by primary or linked wallet
+func (s *AdminService) GetUserByWallet(ctx context.Context, wallet string) (*domain.User, error) {
+	user, err := s.userRepository.GetByWallet(ctx, wallet)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: wallet %s", adminErrorPrefix, wallet)
+		}
+		return nil, errors.Wrapf(err, "%s: get user by wallet", adminErrorPrefix)
+	}
+	return user, nil
+}
+
+// SetRole assigns role to user and records who assigned it. Users can't change their own role,
+// so the last admin can't lock everyone out by accident. Staff must outrank both current and new role of user,
+// so admins appoint moderators, while admins are appointed by server tools.
+// User is logged out everywhere once role changes, since access tokens carry role, so new role applies at once.
+func (s *AdminService) SetRole(ctx context.Context, principal *domain.Principal, userID string, role domain.Role) (*domain.User, error) {
+	if principal.Kind == domain.PrincipalUser && principal.ID == userID {
+		return nil, errors.Wrapf(domain.ErrRole, "%s: own role can't be changed", adminErrorPrefix)
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !principal.Outranks(user.Role) || !principal.Outranks(role) {
+		return nil, errors.Wrapf(domain.ErrForbidden, "%s: role %s of user can't be changed to %s by %s",
+			adminErrorPrefix, user.Role, role, principal.Actor())
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	// guest has no wallet to log in with other than guest device
+	if user.IsGuest() || role == domain.RoleGuest {
+		return nil, errors.Wrapf(domain.ErrRole, "%s: guest role can't be changed, guest signs in with wallet instead", adminErrorPrefix)
+	}
+
+	err = s.userRepository.SetRole(ctx, userID, role, user.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrRole, "%s: role of user %s changed concurrently", adminErrorPrefix, userID)
+		}
+		return nil, errors.Wrapf(err, "%s: set role", adminErrorPrefix)
+	}
+
+	change := &domain.RoleChange{
+		ID:           shortuuid.New(),
+		UserID:       userID,
+		Role:         role,
+		PreviousRole: user.Role,
+		ChangedBy:    principal.Actor(),
+		CreatedAt:    time.Now(),
+	}
+	err = s.roleChangeRepository.Create(ctx, change)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: record role change", adminErrorPrefix)
+	}
+
+	// tokens are revoked after the change, so tokens issued meanwhile have new role
+	err = s.tokenRevoker.LogoutAll(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: revoke tokens of user %s", adminErrorPrefix, userID)
+	}
+
+	user.Role = role
+	return user, nil
+}
+
+// RoleChanges returns role change history of user, newest first
+func (s *AdminService) RoleChanges(ctx context.Context, userID string) ([]*domain.RoleChange, error) {
+	changes, err := s.roleChangeRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: role changes", adminErrorPrefix)
+	}
+	return changes, nil
+}
+
+// ResetProfile replaces nickname with generated one and clears avatar and bio, e.g. when they are offensive.
+// Player may choose new nickname right away. Staff profile can be reset only by staff of higher role.
+func (s *AdminService) ResetProfile(ctx context.Context, principal *domain.Principal, userID string) (*domain.User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !principal.Outranks(user.Role) {
+		return nil, errors.Wrapf(domain.ErrForbidden, "%s: profile of %s can't be reset by %s", adminErrorPrefix, user.Role, principal.Actor())
+	}
+
+	user.Avatar = domain.Avatar{}
+	user.Bio = ""
+	user.NicknameChangedAt = time.Time{}
+	for attempt := 1; ; attempt++ {
+		user.Nickname, err = s.nicknameGenerator.Generate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: generate nickname", adminErrorPrefix)
+		}
+		err = s.userRepository.Update(ctx, user)
+		if err == nil || !errors.Is(err, domain.ErrNicknameTaken) || attempt == nicknameAttempts {
+			break
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: reset profile", adminErrorPrefix)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"server/internal/domain"
+	"server/internal/service/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminService_SetRole(t *testing.T) {
+	admin := &domain.Principal{Kind: domain.PrincipalUser, ID: "admin", Role: domain.RoleAdmin}
+	errTimeout := errors.New("timeout")
+
+	testCases := []struct {
+		name         string
+		actor        *domain.Principal
+		role         domain.Role
+		expectations func(context.Context, *mocks.UserRepository, *mocks.RoleChangeRepository, *mocks.TokenRevoker)
+		err          error
+	}{
+		{
+			name:  "promote player",
+			actor: admin,
+			role:  domain.RoleModerator,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RolePlayer}, nil)
+				userRepo.On("SetRole", ctx, "user", domain.RoleModerator, domain.RolePlayer).Return(nil)
+				roleChangeRepo.On("Create", ctx, mock.MatchedBy(func(change *domain.RoleChange) bool {
+					return change.UserID == "user" && change.ChangedBy == "admin" &&
+						change.Role == domain.RoleModerator && change.PreviousRole == domain.RolePlayer
+				})).Return(nil)
+				tokenRevoker.On("LogoutAll", ctx, "user").Return(nil)
+			},
+		},
+		{
+			name:  "same role",
+			actor: admin,
+			role:  domain.RolePlayer,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RolePlayer}, nil)
+			},
+		},
+		{
+			name:  "own role",
+			actor: &domain.Principal{Kind: domain.PrincipalUser, ID: "user", Role: domain.RoleAdmin},
+			role:  domain.RolePlayer,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+			},
+			err: domain.ErrRole,
+		},
+		{
+			name:  "unknown user",
+			actor: admin,
+			role:  domain.RoleModerator,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(nil, domain.ErrNoDocuments)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name:  "concurrent change",
+			actor: admin,
+			role:  domain.RoleModerator,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RolePlayer}, nil)
+				userRepo.On("SetRole", ctx, "user", domain.RoleModerator, domain.RolePlayer).Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrRole,
+		},
+		{
+			name:  "promote guest",
+			actor: admin,
+			role:  domain.RolePlayer,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleGuest}, nil)
+			},
+			err: domain.ErrRole,
+		},
+		{
+			name:  "promote to own rank",
+			actor: admin,
+			role:  domain.RoleAdmin,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name:  "demote equal rank",
+			actor: admin,
+			role:  domain.RolePlayer,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleAdmin}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name:  "promote to admin by server tool",
+			actor: domain.SystemPrincipal(),
+			role:  domain.RoleAdmin,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RolePlayer}, nil)
+				userRepo.On("SetRole", ctx, "user", domain.RoleAdmin, domain.RolePlayer).Return(nil)
+				roleChangeRepo.On("Create", ctx, mock.MatchedBy(func(change *domain.RoleChange) bool {
+					return change.ChangedBy == domain.SystemActor
+				})).Return(nil)
+				tokenRevoker.On("LogoutAll", ctx, "user").Return(nil)
+			},
+		},
+		{
+			// demoted user must not keep role of its tokens
+			name:  "tokens not revoked",
+			actor: admin,
+			role:  domain.RolePlayer,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, roleChangeRepo *mocks.RoleChangeRepository, tokenRevoker *mocks.TokenRevoker) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+				userRepo.On("SetRole", ctx, "user", domain.RolePlayer, domain.RoleModerator).Return(nil)
+				roleChangeRepo.On("Create", ctx, mock.AnythingOfType("*domain.RoleChange")).Return(nil)
+				tokenRevoker.On("LogoutAll", ctx, "user").Return(errTimeout)
+			},
+			err: errTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		roleChangeRepo := mocks.NewRoleChangeRepository(t)
+		tokenRevoker := mocks.NewTokenRevoker(t)
+		adminService := NewAdminService(userRepo, roleChangeRepo, tokenRevoker, mocks.NewNicknameGenerator(t))
+
+		test.expectations(ctx, userRepo, roleChangeRepo, tokenRevoker)
+
+		user, err := adminService.SetRole(ctx, test.actor, "user", test.role)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			require.NoError(t, err)
+			assert.Equal(t, test.role, user.Role)
+		}
+	}
+}
+
+func TestAdminService_ResetProfile(t *testing.T) {
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	nicknameGenerator := mocks.NewNicknameGenerator(t)
+	adminService := NewAdminService(userRepo, mocks.NewRoleChangeRepository(t), mocks.NewTokenRevoker(t), nicknameGenerator)
+
+	userRepo.On("GetById", ctx, "user").Return(&domain.User{
+		ID:       "user",
+		Nickname: "Offensive",
+		Bio:      "offensive",
+		Avatar:   domain.Avatar{URL: "https://example.com/offensive.png"},
+	}, nil)
+	nicknameGenerator.On("Generate").Return("BraveOtter42", nil).Once()
+	nicknameGenerator.On("Generate").Return("BraveOtter43", nil).Once()
+	userRepo.On("Update", ctx, mock.MatchedBy(func(user *domain.User) bool { return user.Nickname == "BraveOtter42" })).
+		Return(domain.ErrNicknameTaken)
+	userRepo.On("Update", ctx, mock.MatchedBy(func(user *domain.User) bool { return user.Nickname == "BraveOtter43" })).
+		Return(nil)
+
+	moderator := &domain.Principal{Kind: domain.PrincipalUser, ID: "moderator", Role: domain.RoleModerator}
+	user, err := adminService.ResetProfile(ctx, moderator, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "BraveOtter43", user.Nickname)
+	assert.Empty(t, user.Bio)
+	assert.Equal(t, domain.Avatar{}, user.Avatar)
+	assert.True(t, user.NicknameChangedAt.IsZero())
+}
+
+func TestAdminService_ResetProfileOfStaff(t *testing.T) {
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	adminService := NewAdminService(userRepo, mocks.NewRoleChangeRepository(t), mocks.NewTokenRevoker(t), mocks.NewNicknameGenerator(t))
+
+	userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Nickname: "Moderator", Role: domain.RoleModerator}, nil)
+
+	moderator := &domain.Principal{Kind: domain.PrincipalUser, ID: "moderator", Role: domain.RoleModerator}
+	_, err := adminService.ResetProfile(ctx, moderator, "user")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	apiKey := &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "key", Scopes: []domain.Permission{domain.PermissionUsersManage}}
+	_, err = adminService.ResetProfile(ctx, apiKey, "user")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"server/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	apiKeyErrorPrefix = "[service.apikey]"
+)
+
+const (
+	apiKeyBytes = 32
+	// apiKeyPrefixLength is length of key beginning stored in plain to tell keys apart
+	apiKeyPrefixLength = 8
+	// apiKeyTouchInterval limits writes of last used time for keys called often
+	apiKeyTouchInterval = time.Minute
+)
+
+//go:generate mockery --dir . --name APIKeyRepository --output ./mocks
+type APIKeyRepository interface {
+	Create(context.Context, *domain.APIKey) error
+	GetByHash(context.Context, string) (*domain.APIKey, error)
+	GetAll(context.Context) ([]*domain.APIKey, error)
+	Revoke(context.Context, string, time.Time) error
+	Touch(context.Context, string, time.Time) error
+	AnonymizeActor(context.Context, string, string) error
+}
+
+// APIKeyService manages keys services use to call API, only hashes of keys are stored
+type APIKeyService struct {
+	repository APIKeyRepository
+}
+
+func NewAPIKeyService(repository APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repository}
+}
+
+// Create issues key scoped to permissions on behalf of admin, key is returned only once
+func (s *APIKeyService) Create(ctx context.Context, actorID string, req *domain.APIKeyReq) (*domain.APIKey, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", errors.Wrapf(domain.ErrAPIKey, "%s: name is empty", apiKeyErrorPrefix)
+	}
+	if len(req.Scopes) == 0 {
+		return nil, "", errors.Wrapf(domain.ErrAPIKey, "%s: scopes are empty", apiKeyErrorPrefix)
+	}
+	scopes := make([]domain.Permission, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		permission, err := domain.ParseAPIKeyScope(string(scope))
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+		}
+		if !containsPermission(scopes, permission) {
+			scopes = append(scopes, permission)
+		}
+	}
+
+	value := make([]byte, apiKeyBytes)
+	_, err := rand.Read(value)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+	token := base64.RawURLEncoding.EncodeToString(value)
+
+	key := &domain.APIKey{
+		ID:        shortuuid.New(),
+		Name:      name,
+		Hash:      hashSecret(token),
+		Prefix:    token[:apiKeyPrefixLength],
+		Scopes:    scopes,
+		CreatedBy: actorID,
+		CreatedAt: time.Now(),
+	}
+	err = s.repository.Create(ctx, key)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: create", apiKeyErrorPrefix)
+	}
+
+	return key, token, nil
+}
+
+// List returns all keys including revoked ones, newest first
+func (s *APIKeyService) List(ctx context.Context) ([]*domain.APIKey, error) {
+	keys, err := s.repository.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list", apiKeyErrorPrefix)
+	}
+	return keys, nil
+}
+
+// Revoke disables key immediately
+func (s *APIKeyService) Revoke(ctx context.Context, id string) error {
+	err := s.repository.Revoke(ctx, id, time.Now())
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: api key %s not found or revoked", apiKeyErrorPrefix, id)
+		}
+		return errors.Wrapf(err, "%s: revoke", apiKeyErrorPrefix)
+	}
+	return nil
+}
+
+// Authenticate returns key caller presented, ErrToken is returned for unknown and revoked keys
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (*domain.APIKey, error) {
+	key, err := s.repository.GetByHash(ctx, hashSecret(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrToken, "%s: api key not found", apiKeyErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: authenticate", apiKeyErrorPrefix)
+	}
+	if !key.RevokedAt.IsZero() {
+		return nil, errors.Wrapf(domain.ErrToken, "%s: api key revoked", apiKeyErrorPrefix)
+	}
+
+	now := time.Now()
+	if now.Sub(key.LastUsedAt) >= apiKeyTouchInterval {
+		err = s.repository.Touch(ctx, key.ID, now)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: authenticate", apiKeyErrorPrefix)
+		}
+		key.LastUsedAt = now
+	}
+
+	return key, nil
+}
+
+func containsPermission(permissions []domain.Permission, permission domain.Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_Create(t *testing.T) {
+	testCases := []struct {
+		name         string
+		input        *domain.APIKeyReq
+		expectations func(context.Context, *mocks.APIKeyRepository)
+		scopes       []domain.Permission
+		err          error
+	}{
+		{
+			name:  "success",
+			input: &domain.APIKeyReq{Name: "game server", Scopes: []domain.Permission{"users:read", "users:read", "sanctions:manage"}},
+			expectations: func(ctx context.Context, apiKeyRepo *mocks.APIKeyRepository) {
+				apiKeyRepo.On("Create", ctx, mock.MatchedBy(func(key *domain.APIKey) bool {
+					return key.Name == "game server" && key.CreatedBy == "admin" && len(key.Hash) == 64
+				})).Return(nil)
+			},
+			scopes: []domain.Permission{domain.PermissionUsersRead, domain.PermissionSanctionsManage},
+		},
+		{
+			name:         "no name",
+			input:        &domain.APIKeyReq{Name: " ", Scopes: []domain.Permission{domain.PermissionUsersRead}},
+			expectations: func(ctx context.Context, apiKeyRepo *mocks.APIKeyRepository) {},
+			err:          domain.ErrAPIKey,
+		},
+		{
+			name:         "no scopes",
+			input:        &domain.APIKeyReq{Name: "bot"},
+			expectations: func(ctx context.Context, apiKeyRepo *mocks.APIKeyRepository) {},
+			err:          domain.ErrAPIKey,
+		},
+		{
+			name:         "staff scope",
+			input:        &domain.APIKeyReq{Name: "bot", Scopes: []domain.Permission{domain.PermissionRolesManage}},
+			expectations: func(ctx context.Context, apiKeyRepo *mocks.APIKeyRepository) {},
+			err:          domain.ErrAPIKey,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		apiKeyRepo := mocks.NewAPIKeyRepository(t)
+		apiKeyService := NewAPIKeyService(apiKeyRepo)
+
+		test.expectations(ctx, apiKeyRepo)
+
+		key, token, err := apiKeyService.Create(ctx, "admin", test.input)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			require.NoError(t, err)
+			assert.Equal(t, test.scopes, key.Scopes)
+			assert.Equal(t, hashSecret(token), key.Hash)
+			assert.Equal(t, token[:apiKeyPrefixLength], key.Prefix)
+		}
+	}
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+
+	apiKeyRepo := mocks.NewAPIKeyRepository(t)
+	apiKeyService := NewAPIKeyService(apiKeyRepo)
+
+	apiKeyRepo.On("GetByHash", ctx, hashSecret("stale")).
+		Return(&domain.APIKey{ID: "stale", LastUsedAt: time.Now().Add(-time.Hour)}, nil)
+	apiKeyRepo.On("Touch", ctx, "stale", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	apiKeyRepo.On("GetByHash", ctx, hashSecret("fresh")).
+		Return(&domain.APIKey{ID: "fresh", LastUsedAt: time.Now()}, nil)
+	apiKeyRepo.On("GetByHash", ctx, hashSecret("revoked")).
+		Return(&domain.APIKey{ID: "revoked", RevokedAt: time.Now()}, nil)
+	apiKeyRepo.On("GetByHash", ctx, hashSecret("unknown")).Return(nil, domain.ErrNoDocuments)
+
+	key, err := apiKeyService.Authenticate(ctx, "stale")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), key.LastUsedAt, time.Second)
+
+	// recently used key isn't touched again
+	_, err = apiKeyService.Authenticate(ctx, "fresh")
+	assert.NoError(t, err)
+
+	_, err = apiKeyService.Authenticate(ctx, "revoked")
+	assert.ErrorIs(t, err, domain.ErrToken)
+
+	_, err = apiKeyService.Authenticate(ctx, "unknown")
+	assert.ErrorIs(t, err, domain.ErrToken)
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	ctx := context.Background()
+
+	apiKeyRepo := mocks.NewAPIKeyRepository(t)
+	apiKeyService := NewAPIKeyService(apiKeyRepo)
+
+	apiKeyRepo.On("Revoke", ctx, "key", mock.AnythingOfType("time.Time")).Return(nil)
+	apiKeyRepo.On("Revoke", ctx, "revoked", mock.AnythingOfType("time.Time")).Return(domain.ErrNoDocuments)
+
+	assert.NoError(t, apiKeyService.Revoke(ctx, "key"))
+	assert.ErrorIs(t, apiKeyService.Revoke(ctx, "revoked"), domain.ErrNotFound)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/pkg/jwk"
+	"sync"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	keyErrorPrefix = "[service.key]"
+)
+
+// keyReloadInterval is how often keys rotated by other server instances are picked up
+const keyReloadInterval = time.Minute
+
+// keyPublishLead is how long new key is published before it signs tokens
+const keyPublishLead = domain.KeyPublishLead
+
+//go:generate mockery --dir . --name SigningKeyRepository --output ./mocks
+type SigningKeyRepository interface {
+	Create(context.Context, *domain.SigningKey) error
+	GetActive(context.Context, time.Time) ([]*domain.SigningKey, error)
+}
+
+type KeyServiceConfig struct {
+	Algorithm string
+	// RotationPeriod is how long key is used for signing
+	RotationPeriod time.Duration
+	// AccessTokenTTL keeps key published after rotation until tokens it signed expire
+	AccessTokenTTL time.Duration
+}
+
+// KeyService keeps access token signing keys, newest key published for keyPublishLead signs tokens
+// and all not expired keys are published for verification
+type KeyService struct {
+	repository SigningKeyRepository
+	cfg        KeyServiceConfig
+
+	mu   sync.RWMutex
+	keys []*domain.SigningKey
+}
+
+func NewKeyService(repository SigningKeyRepository, cfg KeyServiceConfig) *KeyService {
+	return &KeyService{repository: repository, cfg: cfg}
+}
+
+// Rotate loads active keys and creates next signing key keyPublishLead before newest one is older than
+// rotation period, current key keeps signing until next one is published for keyPublishLead
+func (s *KeyService) Rotate(ctx context.Context) error {
+	now := time.Now()
+
+	keys, err := s.repository.GetActive(ctx, now)
+	if err != nil {
+		return errors.Wrapf(err, "%s: rotate", keyErrorPrefix)
+	}
+
+	if len(keys) == 0 || keys[0].Algorithm != s.cfg.Algorithm || !now.Before(keys[0].CreatedAt.Add(s.cfg.RotationPeriod-keyPublishLead)) {
+		privateKey, err := jwk.GenerateKey(s.cfg.Algorithm)
+		if err != nil {
+			return errors.Wrapf(err, "%s: rotate", keyErrorPrefix)
+		}
+		key := &domain.SigningKey{
+			ID:         shortuuid.New(),
+			Algorithm:  s.cfg.Algorithm,
+			PrivateKey: privateKey,
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(keyPublishLead + s.cfg.RotationPeriod + s.cfg.AccessTokenTTL),
+		}
+		err = s.repository.Create(ctx, key)
+		if err != nil {
+			return errors.Wrapf(err, "%s: rotate", keyErrorPrefix)
+		}
+		keys = append([]*domain.SigningKey{key}, keys...)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Run rotates keys on schedule until context is done
+func (s *KeyService) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(keyReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.Rotate(ctx)
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// SigningKey returns key new tokens are signed with: newest key published for keyPublishLead,
+// or the oldest one when none is, e.g. on first start when there is no other key
+func (s *KeyService) SigningKey() (*domain.SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	var signing *domain.SigningKey
+	// keys are sorted newest first
+	for _, key := range s.keys {
+		if !now.Before(key.ExpiresAt) {
+			continue
+		}
+		signing = key
+		if !now.Before(key.CreatedAt.Add(keyPublishLead)) {
+			break
+		}
+	}
+	if signing == nil {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: no signing key", keyErrorPrefix)
+	}
+	return signing, nil
+}
+
+// PublicKey returns not expired key by id
+func (s *KeyService) PublicKey(id string) (*domain.SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range s.keys {
+		if key.ID == id && now.Before(key.ExpiresAt) {
+			return key, nil
+		}
+	}
+	return nil, errors.Wrapf(domain.ErrNotFound, "%s: key %s", keyErrorPrefix, id)
+}
+
+// PublicKeys returns all not expired keys
+func (s *KeyService) PublicKeys() []*domain.SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]*domain.SigningKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if now.Before(key.ExpiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/service/mocks"
+	"server/pkg/jwk"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testKeyServiceConfig = KeyServiceConfig{
+	Algorithm:      jwk.AlgorithmEdDSA,
+	RotationPeriod: time.Hour,
+	AccessTokenTTL: 15 * time.Minute,
+}
+
+func testSigningKey(t *testing.T, id string, createdAt time.Time) *domain.SigningKey {
+	privateKey, err := jwk.GenerateKey(jwk.AlgorithmEdDSA)
+	require.NoError(t, err)
+	return &domain.SigningKey{
+		ID:         id,
+		Algorithm:  jwk.AlgorithmEdDSA,
+		PrivateKey: privateKey,
+		CreatedAt:  createdAt,
+		ExpiresAt:  createdAt.Add(testKeyServiceConfig.RotationPeriod + testKeyServiceConfig.AccessTokenTTL),
+	}
+}
+
+func TestKeyService_Rotate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Log("testing first key")
+
+	keyRepo := mocks.NewSigningKeyRepository(t)
+	keyService := NewKeyService(keyRepo, testKeyServiceConfig)
+
+	_, err := keyService.SigningKey()
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	keyRepo.On("GetActive", ctx, mock.AnythingOfType("time.Time")).Return([]*domain.SigningKey{}, nil)
+	keyRepo.On("Create", ctx, mock.AnythingOfType("*domain.SigningKey")).Return(nil)
+
+	err = keyService.Rotate(ctx)
+	require.NoError(t, err)
+
+	key, err := keyService.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, jwk.AlgorithmEdDSA, key.Algorithm)
+	assert.Len(t, keyService.PublicKeys(), 1)
+
+	t.Log("testing fresh key is kept")
+
+	fresh := testSigningKey(t, "fresh", now.Add(-time.Minute))
+
+	keyRepo = mocks.NewSigningKeyRepository(t)
+	keyService = NewKeyService(keyRepo, testKeyServiceConfig)
+
+	keyRepo.On("GetActive", ctx, mock.AnythingOfType("time.Time")).Return([]*domain.SigningKey{fresh}, nil)
+
+	err = keyService.Rotate(ctx)
+	require.NoError(t, err)
+
+	key, err = keyService.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", key.ID)
+
+	t.Log("testing next key is published ahead and signs after publish lead")
+
+	current := testSigningKey(t, "current", now.Add(-testKeyServiceConfig.RotationPeriod+keyPublishLead))
+
+	keyRepo = mocks.NewSigningKeyRepository(t)
+	keyService = NewKeyService(keyRepo, testKeyServiceConfig)
+
+	var next *domain.SigningKey
+	keyRepo.On("GetActive", ctx, mock.AnythingOfType("time.Time")).Return([]*domain.SigningKey{current}, nil)
+	keyRepo.On("Create", ctx, mock.AnythingOfType("*domain.SigningKey")).Run(func(args mock.Arguments) {
+		next = args.Get(1).(*domain.SigningKey)
+	}).Return(nil)
+
+	err = keyService.Rotate(ctx)
+	require.NoError(t, err)
+
+	// next key is verifiable before anything is signed with it
+	key, err = keyService.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "current", key.ID)
+	published, err := keyService.PublicKey(next.ID)
+	require.NoError(t, err)
+	assert.Equal(t, next, published)
+	assert.Len(t, keyService.PublicKeys(), 2)
+
+	t.Log("testing published next key signs while old key is still published")
+
+	old := testSigningKey(t, "old", now.Add(-testKeyServiceConfig.RotationPeriod-time.Minute))
+	next = testSigningKey(t, "next", now.Add(-keyPublishLead))
+
+	keyRepo = mocks.NewSigningKeyRepository(t)
+	keyService = NewKeyService(keyRepo, testKeyServiceConfig)
+
+	keyRepo.On("GetActive", ctx, mock.AnythingOfType("time.Time")).Return([]*domain.SigningKey{next, old}, nil)
+
+	err = keyService.Rotate(ctx)
+	require.NoError(t, err)
+
+	key, err = keyService.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "next", key.ID)
+
+	published, err = keyService.PublicKey("old")
+	require.NoError(t, err)
+	assert.Equal(t, old, published)
+	assert.Len(t, keyService.PublicKeys(), 2)
+
+	_, err = keyService.PublicKey("unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -7,6 +7,8 @@ import (
 	domain "server/internal/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -14,6 +16,34 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// AddWallet provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) AddWallet(_a0 context.Context, _a1 string, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimPurge provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) ClaimPurge(_a0 context.Context, _a1 string, _a2 time.Time) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: _a0, _a1
 func (_m *UserRepository) Create(_a0 context.Context, _a1 *domain.User) error {
 	ret := _m.Called(_a0, _a1)
@@ -28,6 +58,72 @@ func (_m *UserRepository) Create(_a0 context.Context, _a1 *domain.User) error {
 	return r0
 }
 
+// Delete provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) Delete(_a0 context.Context, _a1 string, _a2 time.Time) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByDevice provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) GetByDevice(_a0 context.Context, _a1 string) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.User, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) GetById(_a0 context.Context, _a1 string) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.User, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByWallet provides a mock function with given fields: _a0, _a1
 func (_m *UserRepository) GetByWallet(_a0 context.Context, _a1 string) (*domain.User, error) {
 	ret := _m.Called(_a0, _a1)
@@ -54,6 +150,142 @@ func (_m *UserRepository) GetByWallet(_a0 context.Context, _a1 string) (*domain.
 	return r0, r1
 }
 
+// GetDeletionDue provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) GetDeletionDue(_a0 context.Context, _a1 time.Time, _a2 int) ([]*domain.User, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]*domain.User, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []*domain.User); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveWallet provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) RemoveWallet(_a0 context.Context, _a1 string, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplacePrimaryWallet provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *UserRepository) ReplacePrimaryWallet(_a0 context.Context, _a1 string, _a2 string, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ScheduleDeletion provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) ScheduleDeletion(_a0 context.Context, _a1 string, _a2 time.Time) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *UserRepository) Search(_a0 context.Context, _a1 string, _a2 string, _a3 int) ([]*domain.User, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 []*domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]*domain.User, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []*domain.User); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetRole provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *UserRepository) SetRole(_a0 context.Context, _a1 string, _a2 domain.Role, _a3 domain.Role) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Role, domain.Role) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) Update(_a0 context.Context, _a1 *domain.User) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpgradeGuest provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserRepository) UpgradeGuest(_a0 context.Context, _a1 string, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepository(t interface {
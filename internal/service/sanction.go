@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"server/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	sanctionErrorPrefix = "[service.sanction]"
+)
+
+//go:generate mockery --dir . --name SanctionRepository --output ./mocks
+type SanctionRepository interface {
+	Create(context.Context, *domain.Sanction) error
+	GetById(context.Context, string) (*domain.Sanction, error)
+	GetByUser(context.Context, string) ([]*domain.Sanction, error)
+	GetActive(context.Context, string, time.Time) ([]*domain.Sanction, error)
+	Lift(context.Context, string, string, time.Time) error
+	DeleteByUser(context.Context, string) error
+	AnonymizeActor(context.Context, string, string) error
+}
+
+// SanctionService issues and checks moderation measures against users
+type SanctionService struct {
+	repository     SanctionRepository
+	userRepository UserRepository
+}
+
+func NewSanctionService(repository SanctionRepository, userRepository UserRepository) *SanctionService {
+	return &SanctionService{repository, userRepository}
+}
+
+// Issue sanctions user on behalf of moderator, ban is permanent and other sanctions need duration.
+// Staff can be sanctioned only by staff of higher role.
+func (s *SanctionService) Issue(ctx context.Context, principal *domain.Principal, userID string, req *domain.SanctionReq) (*domain.Sanction, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.Wrapf(domain.ErrSanction, "%s: reason is empty", sanctionErrorPrefix)
+	}
+	switch req.Type {
+	case domain.SanctionBan:
+		if req.Duration != 0 {
+			return nil, errors.Wrapf(domain.ErrSanction, "%s: ban is permanent, use suspension", sanctionErrorPrefix)
+		}
+	case domain.SanctionSuspension, domain.SanctionMute:
+		if req.Duration <= 0 {
+			return nil, errors.Wrapf(domain.ErrSanction, "%s: %s needs duration", sanctionErrorPrefix, req.Type)
+		}
+	default:
+		return nil, errors.Wrapf(domain.ErrSanction, "%s: sanction type %q unknown", sanctionErrorPrefix, req.Type)
+	}
+	actorID := principal.Actor()
+	if actorID == userID {
+		return nil, errors.Wrapf(domain.ErrSanction, "%s: own account can't be sanctioned", sanctionErrorPrefix)
+	}
+
+	user, err := s.userRepository.GetById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: user %s", sanctionErrorPrefix, userID)
+		}
+		return nil, errors.Wrapf(err, "%s: issue", sanctionErrorPrefix)
+	}
+	if !principal.Outranks(user.Role) {
+		return nil, errors.Wrapf(domain.ErrForbidden, "%s: %s can't be sanctioned by %s", sanctionErrorPrefix, user.Role, actorID)
+	}
+
+	now := time.Now()
+	sanction := &domain.Sanction{
+		ID:        shortuuid.New(),
+		UserID:    userID,
+		Type:      req.Type,
+		Reason:    reason,
+		IssuedBy:  actorID,
+		CreatedAt: now,
+	}
+	if req.Duration != 0 {
+		sanction.ExpiresAt = now.Add(req.Duration)
+	}
+	err = s.repository.Create(ctx, sanction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: issue", sanctionErrorPrefix)
+	}
+
+	return sanction, nil
+}
+
+// List returns all sanctions of user including expired and lifted ones, newest first
+func (s *SanctionService) List(ctx context.Context, userID string) ([]*domain.Sanction, error) {
+	sanctions, err := s.repository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: list", sanctionErrorPrefix)
+	}
+	return sanctions, nil
+}
+
+// Lift ends sanction on behalf of moderator, the same rules as on issue apply
+func (s *SanctionService) Lift(ctx context.Context, principal *domain.Principal, id string) error {
+	sanction, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: sanction %s not found", sanctionErrorPrefix, id)
+		}
+		return errors.Wrapf(err, "%s: lift", sanctionErrorPrefix)
+	}
+	actorID := principal.Actor()
+	if actorID == sanction.UserID {
+		return errors.Wrapf(domain.ErrSanction, "%s: own sanction can't be lifted", sanctionErrorPrefix)
+	}
+
+	// sanction of deleted user is lifted like one of player
+	var role domain.Role
+	user, err := s.userRepository.GetById(ctx, sanction.UserID)
+	if err == nil {
+		role = user.Role
+	} else if !errors.Is(err, domain.ErrNoDocuments) {
+		return errors.Wrapf(err, "%s: lift", sanctionErrorPrefix)
+	}
+	if !principal.Outranks(role) {
+		return errors.Wrapf(domain.ErrForbidden, "%s: sanction of %s can't be lifted by %s", sanctionErrorPrefix, role, actorID)
+	}
+
+	err = s.repository.Lift(ctx, id, actorID, time.Now())
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: sanction %s not found or lifted", sanctionErrorPrefix, id)
+		}
+		return errors.Wrapf(err, "%s: lift", sanctionErrorPrefix)
+	}
+	return nil
+}
+
+// Check returns SanctionError when user is banned or suspended
+func (s *SanctionService) Check(ctx context.Context, userID string) error {
+	return checkLogin(ctx, s.repository, userID)
+}
+
+// checkLogin returns SanctionError of login blocking sanction which lasts longest
+func checkLogin(ctx context.Context, repository SanctionRepository, userID string) error {
+	sanctions, err := repository.GetActive(ctx, userID, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "%s: check", sanctionErrorPrefix)
+	}
+
+	var blocking *domain.Sanction
+	for _, sanction := range sanctions {
+		if !sanction.BlocksLogin() {
+			continue
+		}
+		if blocking == nil || sanction.ExpiresAt.IsZero() ||
+			!blocking.ExpiresAt.IsZero() && sanction.ExpiresAt.After(blocking.ExpiresAt) {
+			blocking = sanction
+		}
+	}
+	if blocking != nil {
+		return &domain.SanctionError{Sanction: blocking}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanctionService_Issue(t *testing.T) {
+	moderator := &domain.Principal{Kind: domain.PrincipalUser, ID: "moderator", Role: domain.RoleModerator}
+
+	testCases := []struct {
+		name         string
+		principal    *domain.Principal
+		input        *domain.SanctionReq
+		expectations func(context.Context, *mocks.SanctionRepository, *mocks.UserRepository)
+		err          error
+	}{
+		{
+			name:  "ban",
+			input: &domain.SanctionReq{Type: domain.SanctionBan, Reason: "cheating"},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user"}, nil)
+				sanctionRepo.On("Create", ctx, mock.MatchedBy(func(sanction *domain.Sanction) bool {
+					return sanction.UserID == "user" && sanction.IssuedBy == "moderator" && sanction.ExpiresAt.IsZero()
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "mute",
+			input: &domain.SanctionReq{Type: domain.SanctionMute, Reason: "spam", Duration: time.Hour},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user"}, nil)
+				sanctionRepo.On("Create", ctx, mock.MatchedBy(func(sanction *domain.Sanction) bool {
+					return !sanction.ExpiresAt.IsZero()
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "suspension without duration",
+			input:        &domain.SanctionReq{Type: domain.SanctionSuspension, Reason: "cheating"},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrSanction,
+		},
+		{
+			name:         "ban with duration",
+			input:        &domain.SanctionReq{Type: domain.SanctionBan, Reason: "cheating", Duration: time.Hour},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrSanction,
+		},
+		{
+			name:         "no reason",
+			input:        &domain.SanctionReq{Type: domain.SanctionBan, Reason: " "},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrSanction,
+		},
+		{
+			name:  "unknown user",
+			input: &domain.SanctionReq{Type: domain.SanctionBan, Reason: "cheating"},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(nil, domain.ErrNoDocuments)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name:  "staff of equal role",
+			input: &domain.SanctionReq{Type: domain.SanctionBan, Reason: "cheating"},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name:  "staff of higher role",
+			input: &domain.SanctionReq{Type: domain.SanctionBan, Reason: "cheating"},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleAdmin}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name:      "staff by admin",
+			principal: &domain.Principal{Kind: domain.PrincipalUser, ID: "admin", Role: domain.RoleAdmin},
+			input:     &domain.SanctionReq{Type: domain.SanctionMute, Reason: "spam", Duration: time.Hour},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+				sanctionRepo.On("Create", ctx, mock.MatchedBy(func(sanction *domain.Sanction) bool {
+					return sanction.IssuedBy == "admin"
+				})).Return(nil)
+			},
+		},
+		{
+			name:      "staff by api key",
+			principal: &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "key", Scopes: []domain.Permission{domain.PermissionSanctionsManage}},
+			input:     &domain.SanctionReq{Type: domain.SanctionBan, Reason: "cheating"},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name:      "player by api key",
+			principal: &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "key", Scopes: []domain.Permission{domain.PermissionSanctionsManage}},
+			input:     &domain.SanctionReq{Type: domain.SanctionBan, Reason: "cheating"},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RolePlayer}, nil)
+				sanctionRepo.On("Create", ctx, mock.MatchedBy(func(sanction *domain.Sanction) bool {
+					return sanction.IssuedBy == "api_key:key"
+				})).Return(nil)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		sanctionRepo := mocks.NewSanctionRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		sanctionService := NewSanctionService(sanctionRepo, userRepo)
+
+		test.expectations(ctx, sanctionRepo, userRepo)
+
+		principal := test.principal
+		if principal == nil {
+			principal = moderator
+		}
+		_, err := sanctionService.Issue(ctx, principal, "user", test.input)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestSanctionService_Check(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	sanctionRepo := mocks.NewSanctionRepository(t)
+	sanctionService := NewSanctionService(sanctionRepo, mocks.NewUserRepository(t))
+
+	sanctionRepo.On("GetActive", ctx, "muted", mock.AnythingOfType("time.Time")).
+		Return([]*domain.Sanction{{Type: domain.SanctionMute, ExpiresAt: expiresAt}}, nil)
+	sanctionRepo.On("GetActive", ctx, "suspended", mock.AnythingOfType("time.Time")).
+		Return([]*domain.Sanction{
+			{Type: domain.SanctionSuspension, ExpiresAt: expiresAt},
+			{Type: domain.SanctionSuspension, ExpiresAt: expiresAt.Add(time.Hour)},
+		}, nil)
+
+	assert.NoError(t, sanctionService.Check(ctx, "muted"))
+
+	err := sanctionService.Check(ctx, "suspended")
+	sanctionErr := &domain.SanctionError{}
+	require.ErrorAs(t, err, &sanctionErr)
+	assert.ErrorIs(t, err, domain.ErrBanned)
+	assert.Equal(t, expiresAt.Add(time.Hour), sanctionErr.Sanction.ExpiresAt)
+}
+
+func TestSanctionService_Lift(t *testing.T) {
+	moderator := &domain.Principal{Kind: domain.PrincipalUser, ID: "moderator", Role: domain.RoleModerator}
+	sanction := &domain.Sanction{ID: "sanction", UserID: "user", Type: domain.SanctionMute}
+
+	testCases := []struct {
+		name         string
+		principal    *domain.Principal
+		expectations func(context.Context, *mocks.SanctionRepository, *mocks.UserRepository)
+		err          error
+	}{
+		{
+			name: "success lift",
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(sanction, nil)
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RolePlayer}, nil)
+				sanctionRepo.On("Lift", ctx, "sanction", "moderator", mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "deleted user",
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(sanction, nil)
+				userRepo.On("GetById", ctx, "user").Return(nil, domain.ErrNoDocuments)
+				sanctionRepo.On("Lift", ctx, "sanction", "moderator", mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "unknown sanction",
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(nil, domain.ErrNoDocuments)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name: "lifted sanction",
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(sanction, nil)
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RolePlayer}, nil)
+				sanctionRepo.On("Lift", ctx, "sanction", "moderator", mock.AnythingOfType("time.Time")).Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name:      "own sanction",
+			principal: &domain.Principal{Kind: domain.PrincipalUser, ID: "user", Role: domain.RoleModerator},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(sanction, nil)
+			},
+			err: domain.ErrSanction,
+		},
+		{
+			name: "staff of equal role",
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(sanction, nil)
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name:      "staff by api key",
+			principal: &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "key", Scopes: []domain.Permission{domain.PermissionSanctionsManage}},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(sanction, nil)
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+		{
+			name:      "staff by admin",
+			principal: &domain.Principal{Kind: domain.PrincipalUser, ID: "admin", Role: domain.RoleAdmin},
+			expectations: func(ctx context.Context, sanctionRepo *mocks.SanctionRepository, userRepo *mocks.UserRepository) {
+				sanctionRepo.On("GetById", ctx, "sanction").Return(sanction, nil)
+				userRepo.On("GetById", ctx, "user").Return(&domain.User{ID: "user", Role: domain.RoleModerator}, nil)
+				sanctionRepo.On("Lift", ctx, "sanction", "admin", mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		sanctionRepo := mocks.NewSanctionRepository(t)
+		userRepo := mocks.NewUserRepository(t)
+		sanctionService := NewSanctionService(sanctionRepo, userRepo)
+
+		test.expectations(ctx, sanctionRepo, userRepo)
+
+		principal := test.principal
+		if principal == nil {
+			principal = moderator
+		}
+		err := sanctionService.Lift(ctx, principal, "sanction")
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
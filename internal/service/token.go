@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"server/internal/domain"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/pkg/errors"
+)
+
+var (
+	tokenErrorPrefix = "[service.token]"
+)
+
+const refreshTokenBytes = 32
+
+//go:generate mockery --dir . --name RefreshTokenRepository --output ./mocks
+type RefreshTokenRepository interface {
+	Create(context.Context, *domain.RefreshToken) error
+	GetByHash(context.Context, string) (*domain.RefreshToken, error)
+	GetByUser(context.Context, string) ([]*domain.RefreshToken, error)
+	MarkUsed(context.Context, string) error
+	RevokeFamily(context.Context, string) error
+	RevokeUser(context.Context, string) error
+	DeleteByUser(context.Context, string) error
+}
+
+//go:generate mockery --dir . --name RevocationRepository --output ./mocks
+type RevocationRepository interface {
+	RevokeAccessToken(context.Context, *domain.AccessToken) error
+	IsAccessTokenRevoked(context.Context, string) (bool, error)
+	RevokeUser(context.Context, string, time.Time) error
+	GetUserRevokedAt(context.Context, string) (time.Time, error)
+	DeleteByUser(context.Context, string) error
+}
+
+//go:generate mockery --dir . --name SessionRepository --output ./mocks
+type SessionRepository interface {
+	Create(context.Context, *domain.Session) error
+	Touch(context.Context, string, *domain.Device, time.Time) error
+	GetByUser(context.Context, string) ([]*domain.Session, error)
+	Exists(context.Context, string, string) (bool, error)
+	Delete(context.Context, string, string) error
+	DeleteByUser(context.Context, string) error
+}
+
+type TokenService struct {
+	repository           RefreshTokenRepository
+	revocationRepository RevocationRepository
+	sessionRepository    SessionRepository
+	refreshTTL           time.Duration
+}
+
+func NewTokenService(
+	repository RefreshTokenRepository,
+	revocationRepository RevocationRepository,
+	sessionRepository SessionRepository,
+	refreshTTL time.Duration,
+) *TokenService {
+	return &TokenService{repository, revocationRepository, sessionRepository, refreshTTL}
+}
+
+// Issue starts new session for user device, session id is refresh token family
+func (s *TokenService) Issue(ctx context.Context, user *domain.User, device *domain.Device) (*domain.Session, string, error) {
+	now := time.Now()
+	session := &domain.Session{
+		ID:         shortuuid.New(),
+		UserID:     user.ID,
+		UserAgent:  device.UserAgent,
+		IP:         device.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	token, err := s.create(ctx, session.ID, user.ID, user.Wallet)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: issue", tokenErrorPrefix)
+	}
+
+	err = s.sessionRepository.Create(ctx, session)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: issue", tokenErrorPrefix)
+	}
+
+	return session, token, nil
+}
+
+// Refresh exchanges refresh token for a new one of the same family.
+// Token can be exchanged only once, presenting it again revokes the whole family.
+func (s *TokenService) Refresh(ctx context.Context, token string, device *domain.Device) (*domain.RefreshToken, string, error) {
+	refreshToken, err := s.repository.GetByHash(ctx, hashSecret(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, "", errors.Wrapf(domain.ErrToken, "%s: refresh token not found", tokenErrorPrefix)
+		}
+		return nil, "", errors.Wrapf(err, "%s: refresh", tokenErrorPrefix)
+	}
+
+	if refreshToken.Revoked {
+		return nil, "", errors.Wrapf(domain.ErrToken, "%s: refresh token revoked", tokenErrorPrefix)
+	}
+	if !time.Now().Before(refreshToken.ExpiresAt) {
+		return nil, "", errors.Wrapf(domain.ErrToken, "%s: refresh token expired", tokenErrorPrefix)
+	}
+
+	err = s.repository.MarkUsed(ctx, refreshToken.Hash)
+	if err != nil && !errors.Is(err, domain.ErrNoDocuments) {
+		return nil, "", errors.Wrapf(err, "%s: refresh", tokenErrorPrefix)
+	}
+
+	// token was already rotated, so it's leaked or replayed
+	if refreshToken.Used || err != nil {
+		err = s.revokeSession(ctx, refreshToken.UserID, refreshToken.Family)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "%s: revoke family", tokenErrorPrefix)
+		}
+		return nil, "", errors.Wrapf(domain.ErrToken, "%s: refresh token reused", tokenErrorPrefix)
+	}
+
+	newToken, err := s.create(ctx, refreshToken.Family, refreshToken.UserID, refreshToken.Wallet)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "%s: refresh", tokenErrorPrefix)
+	}
+
+	// families issued before sessions were tracked have no session
+	err = s.sessionRepository.Touch(ctx, refreshToken.Family, device, time.Now())
+	if err != nil && !errors.Is(err, domain.ErrNoDocuments) {
+		return nil, "", errors.Wrapf(err, "%s: refresh", tokenErrorPrefix)
+	}
+
+	return refreshToken, newToken, nil
+}
+
+// Logout revokes access token and, when provided, refresh token family of the same device
+func (s *TokenService) Logout(ctx context.Context, accessToken *domain.AccessToken, refreshToken string) error {
+	err := s.revocationRepository.RevokeAccessToken(ctx, accessToken)
+	if err != nil {
+		return errors.Wrapf(err, "%s: logout", tokenErrorPrefix)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := s.repository.GetByHash(ctx, hashSecret(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return errors.Wrapf(domain.ErrToken, "%s: refresh token not found", tokenErrorPrefix)
+		}
+		return errors.Wrapf(err, "%s: logout", tokenErrorPrefix)
+	}
+	if token.UserID != accessToken.UserID {
+		return errors.Wrapf(domain.ErrToken, "%s: refresh token of another user", tokenErrorPrefix)
+	}
+
+	err = s.revokeSession(ctx, token.UserID, token.Family)
+	if err != nil {
+		return errors.Wrapf(err, "%s: logout", tokenErrorPrefix)
+	}
+
+	return nil
+}
+
+// LogoutAll revokes all access and refresh tokens issued to user so far
+func (s *TokenService) LogoutAll(ctx context.Context, userID string) error {
+	err := s.revocationRepository.RevokeUser(ctx, userID, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "%s: logout all", tokenErrorPrefix)
+	}
+
+	err = s.repository.RevokeUser(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: logout all", tokenErrorPrefix)
+	}
+
+	err = s.sessionRepository.DeleteByUser(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "%s: logout all", tokenErrorPrefix)
+	}
+
+	return nil
+}
+
+// Sessions returns devices user is logged in from
+func (s *TokenService) Sessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	sessions, err := s.sessionRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: sessions", tokenErrorPrefix)
+	}
+	return sessions, nil
+}
+
+// DeleteSession logs user device out by revoking its refresh token family.
+// Access tokens already issued to device are revoked with the session, see IsRevoked.
+func (s *TokenService) DeleteSession(ctx context.Context, userID, id string) error {
+	err := s.sessionRepository.Delete(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return errors.Wrapf(domain.ErrNotFound, "%s: session %s", tokenErrorPrefix, id)
+		}
+		return errors.Wrapf(err, "%s: delete session", tokenErrorPrefix)
+	}
+
+	err = s.repository.RevokeFamily(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "%s: delete session", tokenErrorPrefix)
+	}
+
+	return nil
+}
+
+// IsRevoked checks access token was revoked by logout
+func (s *TokenService) IsRevoked(ctx context.Context, accessToken *domain.AccessToken) (bool, error) {
+	revoked, err := s.revocationRepository.IsAccessTokenRevoked(ctx, accessToken.ID)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: is revoked", tokenErrorPrefix)
+	}
+	if revoked {
+		return true, nil
+	}
+
+	revokedAt, err := s.revocationRepository.GetUserRevokedAt(ctx, accessToken.UserID)
+	if err != nil && !errors.Is(err, domain.ErrNoDocuments) {
+		return false, errors.Wrapf(err, "%s: is revoked", tokenErrorPrefix)
+	}
+
+	// token issue time has milliseconds precision, so tokens issued in the millisecond of revocation stay valid
+	if accessToken.IssuedAt.Before(revokedAt.Truncate(time.Millisecond)) {
+		return true, nil
+	}
+
+	return s.isSessionDeleted(ctx, accessToken)
+}
+
+// isSessionDeleted checks session access token was issued to is deleted, so device logged out
+// from another one loses access at once. Tokens issued without session are left to expire.
+func (s *TokenService) isSessionDeleted(ctx context.Context, accessToken *domain.AccessToken) (bool, error) {
+	if accessToken.SessionID == "" {
+		return false, nil
+	}
+	exists, err := s.sessionRepository.Exists(ctx, accessToken.UserID, accessToken.SessionID)
+	if err != nil {
+		return false, errors.Wrapf(err, "%s: is revoked", tokenErrorPrefix)
+	}
+	return !exists, nil
+}
+
+// revokeSession revokes refresh token family and removes its session
+func (s *TokenService) revokeSession(ctx context.Context, userID, family string) error {
+	err := s.repository.RevokeFamily(ctx, family)
+	if err != nil {
+		return err
+	}
+
+	err = s.sessionRepository.Delete(ctx, userID, family)
+	if err != nil && !errors.Is(err, domain.ErrNoDocuments) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *TokenService) create(ctx context.Context, family, userID, wallet string) (string, error) {
+	value := make([]byte, refreshTokenBytes)
+	_, err := rand.Read(value)
+	if err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(value)
+
+	now := time.Now()
+	err = s.repository.Create(ctx, &domain.RefreshToken{
+		Hash:      hashSecret(token),
+		Family:    family,
+		UserID:    userID,
+		Wallet:    wallet,
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// hashSecret is how refresh tokens, API keys and guest device ids are stored, so leaked storage doesn't reveal them
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
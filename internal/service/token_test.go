@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"server/internal/domain"
+	"server/internal/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/lithammer/shortuuid/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenService_Issue(t *testing.T) {
+	ctx := context.Background()
+
+	tokenRepo := mocks.NewRefreshTokenRepository(t)
+	sessionRepo := mocks.NewSessionRepository(t)
+	tokenService := NewTokenService(tokenRepo, mocks.NewRevocationRepository(t), sessionRepo, time.Hour)
+
+	user := &domain.User{
+		ID:     shortuuid.New(),
+		Wallet: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935",
+	}
+
+	var stored *domain.RefreshToken
+	tokenRepo.On("Create", ctx, mock.AnythingOfType("*domain.RefreshToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.RefreshToken) }).
+		Return(nil)
+	sessionRepo.On("Create", ctx, mock.MatchedBy(func(session *domain.Session) bool {
+		return session.UserID == user.ID && session.UserAgent == "agent" && session.IP == "127.0.0.1"
+	})).Return(nil)
+
+	session, token, err := tokenService.Issue(ctx, user, &domain.Device{UserAgent: "agent", IP: "127.0.0.1"})
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+
+	// only hash of token is stored
+	assert.NotEqual(t, token, stored.Hash)
+	assert.Equal(t, hashSecret(token), stored.Hash)
+	assert.Equal(t, user.ID, stored.UserID)
+	assert.Equal(t, session.ID, stored.Family)
+	assert.True(t, stored.ExpiresAt.After(time.Now()))
+}
+
+func TestTokenService_Refresh(t *testing.T) {
+	token := "token"
+	device := &domain.Device{UserAgent: "agent", IP: "127.0.0.1"}
+
+	refreshToken := func(used, revoked bool, expiresAt time.Time) *domain.RefreshToken {
+		return &domain.RefreshToken{
+			Hash:      hashSecret(token),
+			Family:    "family",
+			UserID:    "user",
+			Wallet:    "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935",
+			Used:      used,
+			Revoked:   revoked,
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.RefreshTokenRepository, *mocks.SessionRepository)
+		err          error
+	}{
+		{
+			name: "success rotation",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				tokenRepo.On("GetByHash", ctx, hashSecret(token)).Return(refreshToken(false, false, future), nil)
+				tokenRepo.On("MarkUsed", ctx, hashSecret(token)).Return(nil)
+				tokenRepo.On("Create", ctx, mock.MatchedBy(func(rt *domain.RefreshToken) bool {
+					return rt.Family == "family" && rt.UserID == "user" && !rt.Used
+				})).Return(nil)
+				sessionRepo.On("Touch", ctx, "family", device, mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "success rotation without session",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				tokenRepo.On("GetByHash", ctx, hashSecret(token)).Return(refreshToken(false, false, future), nil)
+				tokenRepo.On("MarkUsed", ctx, hashSecret(token)).Return(nil)
+				tokenRepo.On("Create", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+				sessionRepo.On("Touch", ctx, "family", device, mock.AnythingOfType("time.Time")).Return(domain.ErrNoDocuments)
+			},
+		},
+		{
+			name: "unknown token",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				tokenRepo.On("GetByHash", ctx, hashSecret(token)).Return(nil, domain.ErrNoDocuments)
+			},
+			err: domain.ErrToken,
+		},
+		{
+			name: "expired token",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				tokenRepo.On("GetByHash", ctx, hashSecret(token)).Return(refreshToken(false, false, past), nil)
+			},
+			err: domain.ErrToken,
+		},
+		{
+			name: "revoked token",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				tokenRepo.On("GetByHash", ctx, hashSecret(token)).Return(refreshToken(true, true, future), nil)
+			},
+			err: domain.ErrToken,
+		},
+		{
+			name: "reused token",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				tokenRepo.On("GetByHash", ctx, hashSecret(token)).Return(refreshToken(true, false, future), nil)
+				tokenRepo.On("MarkUsed", ctx, hashSecret(token)).Return(domain.ErrNoDocuments)
+				tokenRepo.On("RevokeFamily", ctx, "family").Return(nil)
+				sessionRepo.On("Delete", ctx, "user", "family").Return(nil)
+			},
+			err: domain.ErrToken,
+		},
+		{
+			name: "concurrently rotated token",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				tokenRepo.On("GetByHash", ctx, hashSecret(token)).Return(refreshToken(false, false, future), nil)
+				tokenRepo.On("MarkUsed", ctx, hashSecret(token)).Return(domain.ErrNoDocuments)
+				tokenRepo.On("RevokeFamily", ctx, "family").Return(nil)
+				sessionRepo.On("Delete", ctx, "user", "family").Return(nil)
+			},
+			err: domain.ErrToken,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		tokenRepo := mocks.NewRefreshTokenRepository(t)
+		sessionRepo := mocks.NewSessionRepository(t)
+		tokenService := NewTokenService(tokenRepo, mocks.NewRevocationRepository(t), sessionRepo, time.Hour)
+
+		test.expectations(ctx, tokenRepo, sessionRepo)
+
+		rotated, newToken, err := tokenService.Refresh(ctx, token, device)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			require.NoError(t, err)
+			assert.Equal(t, "user", rotated.UserID)
+			assert.NotEmpty(t, newToken)
+			assert.NotEqual(t, token, newToken)
+		}
+	}
+}
+
+func TestTokenService_Logout(t *testing.T) {
+	ctx := context.Background()
+
+	accessToken := &domain.AccessToken{
+		ID:        shortuuid.New(),
+		UserID:    "user",
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tokenRepo := mocks.NewRefreshTokenRepository(t)
+	revocationRepo := mocks.NewRevocationRepository(t)
+	sessionRepo := mocks.NewSessionRepository(t)
+	tokenService := NewTokenService(tokenRepo, revocationRepo, sessionRepo, time.Hour)
+
+	revocationRepo.On("RevokeAccessToken", ctx, accessToken).Return(nil)
+	tokenRepo.On("GetByHash", ctx, hashSecret("token")).Return(&domain.RefreshToken{
+		Hash:   hashSecret("token"),
+		Family: "family",
+		UserID: "user",
+	}, nil)
+	tokenRepo.On("RevokeFamily", ctx, "family").Return(nil)
+	sessionRepo.On("Delete", ctx, "user", "family").Return(nil)
+
+	err := tokenService.Logout(ctx, accessToken, "token")
+	assert.NoError(t, err)
+
+	t.Log("testing refresh token of another user")
+
+	tokenRepo = mocks.NewRefreshTokenRepository(t)
+	revocationRepo = mocks.NewRevocationRepository(t)
+	tokenService = NewTokenService(tokenRepo, revocationRepo, mocks.NewSessionRepository(t), time.Hour)
+
+	revocationRepo.On("RevokeAccessToken", ctx, accessToken).Return(nil)
+	tokenRepo.On("GetByHash", ctx, hashSecret("token")).Return(&domain.RefreshToken{
+		Hash:   hashSecret("token"),
+		Family: "family",
+		UserID: "other",
+	}, nil)
+
+	err = tokenService.Logout(ctx, accessToken, "token")
+	assert.ErrorIs(t, err, domain.ErrToken)
+}
+
+func TestTokenService_LogoutAll(t *testing.T) {
+	ctx := context.Background()
+
+	tokenRepo := mocks.NewRefreshTokenRepository(t)
+	revocationRepo := mocks.NewRevocationRepository(t)
+	sessionRepo := mocks.NewSessionRepository(t)
+	tokenService := NewTokenService(tokenRepo, revocationRepo, sessionRepo, time.Hour)
+
+	revocationRepo.On("RevokeUser", ctx, "user", mock.AnythingOfType("time.Time")).Return(nil)
+	tokenRepo.On("RevokeUser", ctx, "user").Return(nil)
+	sessionRepo.On("DeleteByUser", ctx, "user").Return(nil)
+
+	err := tokenService.LogoutAll(ctx, "user")
+	assert.NoError(t, err)
+}
+
+func TestTokenService_DeleteSession(t *testing.T) {
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.RefreshTokenRepository, *mocks.SessionRepository)
+		err          error
+	}{
+		{
+			name: "success",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				sessionRepo.On("Delete", ctx, "user", "session").Return(nil)
+				tokenRepo.On("RevokeFamily", ctx, "session").Return(nil)
+			},
+		},
+		{
+			name: "session of another user",
+			expectations: func(ctx context.Context, tokenRepo *mocks.RefreshTokenRepository, sessionRepo *mocks.SessionRepository) {
+				sessionRepo.On("Delete", ctx, "user", "session").Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		tokenRepo := mocks.NewRefreshTokenRepository(t)
+		sessionRepo := mocks.NewSessionRepository(t)
+		tokenService := NewTokenService(tokenRepo, mocks.NewRevocationRepository(t), sessionRepo, time.Hour)
+
+		test.expectations(ctx, tokenRepo, sessionRepo)
+
+		err := tokenService.DeleteSession(ctx, "user", "session")
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestTokenService_IsRevoked(t *testing.T) {
+	// issue time of access token has milliseconds precision
+	now := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+
+	testCases := []struct {
+		name         string
+		sessionID    string
+		expectations func(context.Context, *mocks.RevocationRepository, *mocks.SessionRepository)
+		revoked      bool
+	}{
+		{
+			name: "not revoked",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(time.Time{}, domain.ErrNoDocuments)
+			},
+		},
+		{
+			name: "token revoked",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(true, nil)
+			},
+			revoked: true,
+		},
+		{
+			name: "user revoked after issue",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(now.Add(time.Minute), nil)
+			},
+			revoked: true,
+		},
+		{
+			name: "user revoked later in the second of issue",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(now.Add(300*time.Millisecond), nil)
+			},
+			revoked: true,
+		},
+		{
+			// user logged in again right after logging out everywhere
+			name: "user revoked earlier in the second of issue",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(now.Add(-300*time.Millisecond), nil)
+			},
+		},
+		{
+			name: "user revoked in the millisecond of issue",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(now.Add(500*time.Microsecond), nil)
+			},
+		},
+		{
+			name: "user revoked before issue",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(now.Add(-time.Minute), nil)
+			},
+		},
+		{
+			name:      "session exists",
+			sessionID: "sid",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(time.Time{}, domain.ErrNoDocuments)
+				sessionRepo.On("Exists", ctx, "user", "sid").Return(true, nil)
+			},
+		},
+		{
+			// device was logged out from another one
+			name:      "session deleted",
+			sessionID: "sid",
+			expectations: func(ctx context.Context, revocationRepo *mocks.RevocationRepository, sessionRepo *mocks.SessionRepository) {
+				revocationRepo.On("IsAccessTokenRevoked", ctx, "jti").Return(false, nil)
+				revocationRepo.On("GetUserRevokedAt", ctx, "user").Return(time.Time{}, domain.ErrNoDocuments)
+				sessionRepo.On("Exists", ctx, "user", "sid").Return(false, nil)
+			},
+			revoked: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		revocationRepo := mocks.NewRevocationRepository(t)
+		sessionRepo := mocks.NewSessionRepository(t)
+		tokenService := NewTokenService(mocks.NewRefreshTokenRepository(t), revocationRepo, sessionRepo, time.Hour)
+
+		test.expectations(ctx, revocationRepo, sessionRepo)
+
+		revoked, err := tokenService.IsRevoked(ctx, &domain.AccessToken{
+			ID:        "jti",
+			UserID:    "user",
+			SessionID: test.sessionID,
+			IssuedAt:  now,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, test.revoked, revoked)
+	}
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"server/internal/domain"
+	"server/pkg/nickname"
 	"server/pkg/sign"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/lithammer/shortuuid/v3"
 	"github.com/pkg/errors"
@@ -14,51 +18,596 @@ var (
 	userErrorPrefix = "[service.user]"
 )
 
+// nicknameAttempts bounds nickname generation retries when generated nickname is taken
+const nicknameAttempts = 5
+
+const (
+	// guestDeviceMinLength keeps device ids guessable by others from being accepted
+	guestDeviceMinLength = 16
+	guestDeviceMaxLength = 128
+)
+
 //go:generate mockery --dir . --name UserRepository --output ./mocks
 type UserRepository interface {
+	GetById(context.Context, string) (*domain.User, error)
 	GetByWallet(context.Context, string) (*domain.User, error)
+	GetByDevice(context.Context, string) (*domain.User, error)
 	Create(context.Context, *domain.User) error
+	Update(context.Context, *domain.User) error
+	Search(context.Context, string, string, int) ([]*domain.User, error)
+	SetRole(context.Context, string, domain.Role, domain.Role) error
+	ScheduleDeletion(context.Context, string, time.Time) error
+	GetDeletionDue(context.Context, time.Time, int) ([]*domain.User, error)
+	ClaimPurge(context.Context, string, time.Time) error
+	Delete(context.Context, string, time.Time) error
+	AddWallet(context.Context, string, string) error
+	RemoveWallet(context.Context, string, string) error
+	ReplacePrimaryWallet(context.Context, string, string, string) error
+	UpgradeGuest(context.Context, string, string) error
+}
+
+//go:generate mockery --dir . --name NonceRepository --output ./mocks
+type NonceRepository interface {
+	Create(context.Context, *domain.Nonce) error
+	Consume(context.Context, domain.Address, string) error
+}
+
+//go:generate mockery --dir . --name NicknameGenerator --output ./mocks
+type NicknameGenerator interface {
+	Generate() (string, error)
+}
+
+type UserServiceConfig struct {
+	NonceTTL time.Duration
+	// Siwe is sign-in message config of Ethereum wallets
+	Siwe sign.SiweConfig
+	// EVMAccountReference is CAIP-2 reference EVM accounts are stored under,
+	// the same for sign-in message and typed data whatever chain they are signed for
+	EVMAccountReference string
+	SignMode            sign.Mode
+	TypedData           sign.TypedDataConfig
+	// SolanaSiwe is sign-in message config of Solana wallets
+	SolanaSiwe sign.SiweConfig
+	// Nickname are rules of nicknames chosen by players
+	Nickname nickname.Rules
+	// NicknameCooldown is how long player waits before next rename
+	NicknameCooldown time.Duration
+	// BioMaxLength is max number of characters in profile bio
+	BioMaxLength int
+	// SearchPageSize is number of players in page of search results
+	SearchPageSize int
+}
+
+// chainAuth is sign-in setup of wallets of one CAIP-2 namespace
+type chainAuth struct {
+	siwe sign.SiweConfig
+	// reference is CAIP-2 chain reference wallets are stored with
+	reference string
+	verifier  sign.Verifier
 }
 
 type UserService struct {
-	repository UserRepository
+	repository         UserRepository
+	nonceRepository    NonceRepository
+	sanctionRepository SanctionRepository
+	contractVerifier   sign.ContractVerifier
+	nicknameGenerator  NicknameGenerator
+	cfg                UserServiceConfig
+	chains             map[string]*chainAuth
+}
+
+// NewUserService creates user service, contractVerifier is optional
+// and enables smart-contract wallets login when set
+func NewUserService(
+	repository UserRepository,
+	nonceRepository NonceRepository,
+	sanctionRepository SanctionRepository,
+	contractVerifier sign.ContractVerifier,
+	nicknameGenerator NicknameGenerator,
+	cfg UserServiceConfig,
+) *UserService {
+	chains := map[string]*chainAuth{
+		sign.NamespaceEIP155: {
+			siwe:      cfg.Siwe,
+			reference: cfg.EVMAccountReference,
+			verifier:  sign.NewEthereumVerifier(cfg.SignMode, contractVerifier),
+		},
+		sign.NamespaceSolana: {
+			siwe:      cfg.SolanaSiwe,
+			reference: sign.SolanaChainReference(cfg.SolanaSiwe.ChainID),
+			verifier:  sign.NewSolanaVerifier(),
+		},
+	}
+	return &UserService{repository, nonceRepository, sanctionRepository, contractVerifier, nicknameGenerator, cfg, chains}
+}
+
+// Nonce issues short-lived single-use nonce for wallet, which must be embedded in signed auth message
+func (s *UserService) Nonce(ctx context.Context, wallet domain.Address) (*domain.Nonce, error) {
+	if wallet == "" {
+		return nil, errors.Wrapf(domain.ErrNonce, "%s: wallet is empty", userErrorPrefix)
+	}
+
+	now := time.Now()
+	nonce := &domain.Nonce{
+		Value:     shortuuid.New(),
+		Wallet:    wallet,
+		ExpiresAt: now.Add(s.cfg.NonceTTL),
+		CreatedAt: now,
+	}
+	err := s.nonceRepository.Create(ctx, nonce)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: nonce save error", userErrorPrefix)
+	}
+
+	return nonce, nil
+}
+
+// Auth verifies wallet signature and returns wallet user, user is created on first login.
+// Linked wallets log in to the user they are linked to. Banned and suspended users are refused with SanctionError,
+// as are new users of wallets which deleted account was banned.
+// Login during deletion grace period cancels account deletion.
+// When request has GuestID the guest becomes user of wallet instead, which is refused with ErrWallet
+// if wallet already has user, as guest progress can't be merged into another account.
+func (s *UserService) Auth(ctx context.Context, req *domain.UserAuthReq) (*domain.User, error) {
+	account, err := s.verify(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repository.GetByWallet(ctx, account)
+	if err == nil {
+		if req.GuestID != "" {
+			return nil, errors.Wrapf(domain.ErrWallet, "%s: wallet already has account", userErrorPrefix)
+		}
+		return s.login(ctx, user)
+	}
+	if !errors.Is(err, domain.ErrNoDocuments) {
+		return nil, errors.Wrapf(err, "%s: get by wallet", userErrorPrefix)
+	}
+
+	// bans of deleted account are kept for its wallets
+	err = checkLogin(ctx, s.sanctionRepository, domain.WalletSubject(account))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: auth", userErrorPrefix)
+	}
+
+	if req.GuestID != "" {
+		return s.upgradeGuest(ctx, req.GuestID, account)
+	}
+
+	newUser := &domain.User{
+		ID:        shortuuid.New(),
+		Wallet:    account,
+		CreatedAt: time.Now(),
+		Role:      domain.RolePlayer,
+	}
+	err = s.create(ctx, newUser)
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		// concurrent login with the same wallet created user first
+		user, err = s.repository.GetByWallet(ctx, account)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get by wallet", userErrorPrefix)
+		}
+		return s.login(ctx, user)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: repo save error", userErrorPrefix)
+	}
+
+	return newUser, nil
+}
+
+// Guest returns guest bound to device, guest is created on first login from device.
+// Device id is secret of device, so it must be long enough not to be guessed.
+func (s *UserService) Guest(ctx context.Context, deviceID string) (*domain.User, error) {
+	if len(deviceID) < guestDeviceMinLength || len(deviceID) > guestDeviceMaxLength {
+		return nil, errors.Wrapf(domain.ErrGuest, "%s: device id must be %d to %d characters long",
+			userErrorPrefix, guestDeviceMinLength, guestDeviceMaxLength)
+	}
+	device := hashSecret(deviceID)
+
+	user, err := s.repository.GetByDevice(ctx, device)
+	if err == nil {
+		return s.login(ctx, user)
+	}
+	if !errors.Is(err, domain.ErrNoDocuments) {
+		return nil, errors.Wrapf(err, "%s: get by device", userErrorPrefix)
+	}
+
+	newUser := &domain.User{
+		ID:        shortuuid.New(),
+		CreatedAt: time.Now(),
+		Role:      domain.RoleGuest,
+		Device:    device,
+	}
+	err = s.create(ctx, newUser)
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		// concurrent login from the same device created guest first
+		user, err = s.repository.GetByDevice(ctx, device)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: get by device", userErrorPrefix)
+		}
+		return s.login(ctx, user)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: create guest", userErrorPrefix)
+	}
+
+	return newUser, nil
 }
 
-func NewUserService(repository UserRepository) *UserService {
-	return &UserService{repository}
+// login lets existing user in unless user is banned or suspended, pending account deletion is cancelled
+// unless account is being purged already
+func (s *UserService) login(ctx context.Context, user *domain.User) (*domain.User, error) {
+	err := checkLogin(ctx, s.sanctionRepository, user.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: auth", userErrorPrefix)
+	}
+	if !user.DeleteRequestedAt.IsZero() {
+		err = s.repository.ScheduleDeletion(ctx, user.ID, time.Time{})
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrDeleted, "%s: cancel deletion of %s", userErrorPrefix, user.ID)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: cancel deletion", userErrorPrefix)
+		}
+		user.DeleteRequestedAt = time.Time{}
+	}
+	return user, nil
+}
+
+// create saves new user, generated nickname is retried until free one is found
+func (s *UserService) create(ctx context.Context, user *domain.User) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		user.Nickname, err = s.nicknameGenerator.Generate()
+		if err != nil {
+			return errors.Wrapf(err, "%s: generate nickname", userErrorPrefix)
+		}
+		err = s.repository.Create(ctx, user)
+		if err == nil || !errors.Is(err, domain.ErrNicknameTaken) || attempt == nicknameAttempts {
+			break
+		}
+	}
+	return err
+}
+
+// upgradeGuest makes guest user of wallet keeping everything else guest has
+func (s *UserService) upgradeGuest(ctx context.Context, guestID, account string) (*domain.User, error) {
+	err := s.repository.UpgradeGuest(ctx, guestID, account)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrGuest, "%s: user %s is not guest", userErrorPrefix, guestID)
+		}
+		return nil, errors.Wrapf(err, "%s: upgrade guest", userErrorPrefix)
+	}
+
+	return s.GetById(ctx, guestID)
 }
 
-func (s *UserService) Auth(ctx context.Context, req *domain.UserAuthReq) error {
+// Update changes user fields player is allowed to change
+func (s *UserService) Update(ctx context.Context, userID string, req *domain.UserUpdateReq) (*domain.User, error) {
+	user, err := s.GetById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	// check wallet signature
-	err := sign.VerifySignature(
-		req.Wallet,
-		req.Message,
+	now := time.Now()
+	changed := false
+
+	if req.Nickname != nil {
+		newNickname := strings.TrimSpace(*req.Nickname)
+		if newNickname != user.Nickname {
+			err = s.cfg.Nickname.Validate(newNickname)
+			if err != nil {
+				return nil, errors.Wrapf(domain.ErrNickname, "%s: %s", userErrorPrefix, err)
+			}
+			// generated nickname can be changed right away
+			if !user.NicknameChangedAt.IsZero() && now.Before(user.NicknameChangedAt.Add(s.cfg.NicknameCooldown)) {
+				return nil, errors.Wrapf(domain.ErrNickname, "%s: nickname can be changed after %s",
+					userErrorPrefix, user.NicknameChangedAt.Add(s.cfg.NicknameCooldown).Format(time.RFC3339))
+			}
+			user.Nickname = newNickname
+			user.NicknameChangedAt = now
+			changed = true
+		}
+	}
+
+	if req.Avatar != nil && *req.Avatar != user.Avatar {
+		err = req.Avatar.Validate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: update", userErrorPrefix)
+		}
+		user.Avatar = *req.Avatar
+		changed = true
+	}
+
+	if req.Bio != nil {
+		bio := strings.TrimSpace(*req.Bio)
+		if utf8.RuneCountInString(bio) > s.cfg.BioMaxLength {
+			return nil, errors.Wrapf(domain.ErrProfile, "%s: bio must be at most %d characters long", userErrorPrefix, s.cfg.BioMaxLength)
+		}
+		changed = changed || bio != user.Bio
+		user.Bio = bio
+	}
+
+	if req.Country != nil {
+		country, err := domain.NormalizeCountry(strings.TrimSpace(*req.Country))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: update", userErrorPrefix)
+		}
+		changed = changed || country != user.Country
+		user.Country = country
+	}
+
+	if req.Privacy != nil && *req.Privacy != user.Privacy {
+		user.Privacy = *req.Privacy
+		changed = true
+	}
+
+	if !changed {
+		return user, nil
+	}
+
+	err = s.repository.Update(ctx, user)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: update", userErrorPrefix)
+	}
+
+	return user, nil
+}
+
+// LinkWallet links wallet to user, ownership is proven the same way as on login
+func (s *UserService) LinkWallet(ctx context.Context, userID string, req *domain.UserAuthReq) (*domain.User, error) {
+	account, err := s.verify(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.repository.GetByWallet(ctx, account)
+	if err == nil {
+		if owner.ID == userID {
+			return nil, errors.Wrapf(domain.ErrWallet, "%s: wallet already linked", userErrorPrefix)
+		}
+		return nil, errors.Wrapf(domain.ErrWallet, "%s: wallet belongs to another user", userErrorPrefix)
+	}
+	if !errors.Is(err, domain.ErrNoDocuments) {
+		return nil, errors.Wrapf(err, "%s: get by wallet", userErrorPrefix)
+	}
+
+	err = s.repository.AddWallet(ctx, userID, account)
+	if err != nil {
+		// wallet is linked to another user meanwhile
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, errors.Wrapf(domain.ErrWallet, "%s: wallet belongs to another user", userErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: link wallet", userErrorPrefix)
+	}
+
+	return s.GetById(ctx, userID)
+}
+
+// UnlinkWallet unlinks wallet from user, the last wallet can't be unlinked.
+// When primary wallet is unlinked the oldest linked wallet becomes primary.
+func (s *UserService) UnlinkWallet(ctx context.Context, userID, wallet string) (*domain.User, error) {
+	user, err := s.GetById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.HasWallet(wallet) {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: wallet not linked", userErrorPrefix)
+	}
+	if len(user.Wallets) == 0 {
+		return nil, errors.Wrapf(domain.ErrWallet, "%s: last wallet can't be unlinked", userErrorPrefix)
+	}
+
+	if user.Wallet == wallet {
+		err = s.repository.ReplacePrimaryWallet(ctx, userID, wallet, user.Wallets[0])
+	} else {
+		err = s.repository.RemoveWallet(ctx, userID, wallet)
+	}
+	if err != nil {
+		// wallets were changed concurrently
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrWallet, "%s: wallets changed, try again", userErrorPrefix)
+		}
+		return nil, errors.Wrapf(err, "%s: unlink wallet", userErrorPrefix)
+	}
+
+	return s.GetById(ctx, userID)
+}
+
+// verify checks wallet signature on chain of request, consumes nonce signed payload embeds
+// and returns CAIP-10 account of wallet
+func (s *UserService) verify(ctx context.Context, req *domain.UserAuthReq) (string, error) {
+	namespace := req.Chain
+	if namespace == "" {
+		namespace = sign.NamespaceEIP155
+	}
+	chain, ok := s.chains[namespace]
+	if !ok {
+		return "", errors.Wrapf(domain.ErrSignature, "%s: chain %q not supported", userErrorPrefix, req.Chain)
+	}
+
+	var nonce string
+	var err error
+	if len(req.TypedData) > 0 {
+		if namespace != sign.NamespaceEIP155 {
+			return "", errors.Wrapf(domain.ErrSignature, "%s: typed data not supported on chain %q", userErrorPrefix, namespace)
+		}
+		nonce, err = s.verifyTypedData(ctx, req)
+	} else {
+		nonce, err = s.verifyMessage(ctx, chain, req)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// consume nonce, so signed message can't be replayed
+	err = s.nonceRepository.Consume(ctx, req.Wallet, nonce)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return "", errors.Wrapf(domain.ErrNonce, "%s: nonce expired or already used", userErrorPrefix)
+		}
+		return "", errors.Wrapf(err, "%s: nonce consume error", userErrorPrefix)
+	}
+
+	return sign.FormatAccount(namespace, chain.reference, string(req.Wallet)), nil
+}
+
+// verifyMessage checks sign-in message (EIP-4361 or its CAIP-122 analog) signature and returns its nonce
+func (s *UserService) verifyMessage(ctx context.Context, chain *chainAuth, req *domain.UserAuthReq) (string, error) {
+	wallet := string(req.Wallet)
+
+	// message must be issued for our domain and chain and embed nonce issued by server
+	message, err := sign.ParseSiweMessage(req.Message)
+	if err != nil {
+		return "", errors.Wrapf(domain.ErrSignature, "%s: %s", userErrorPrefix, err)
+	}
+	err = message.Validate(chain.siwe, wallet, time.Now())
+	if err != nil {
+		return "", errors.Wrapf(domain.ErrSignature, "%s: %s", userErrorPrefix, err)
+	}
+
+	err = chain.verifier.Verify(ctx, wallet, req.Message, req.Sign)
+	if err != nil {
+		return "", errors.Wrapf(domain.ErrSignature, "%s: signature check fail: %s", userErrorPrefix, err)
+	}
+
+	return message.Nonce, nil
+}
+
+// verifyTypedData checks EIP-712 login payload signature and returns its nonce
+func (s *UserService) verifyTypedData(ctx context.Context, req *domain.UserAuthReq) (string, error) {
+	wallet := string(req.Wallet)
+
+	// payload must be bound to our contract and chain
+	typedData, err := sign.ParseTypedData(req.TypedData)
+	if err != nil {
+		return "", errors.Wrapf(domain.ErrSignature, "%s: %s", userErrorPrefix, err)
+	}
+	login, err := sign.ParseTypedDataLogin(typedData, s.cfg.TypedData)
+	if err != nil {
+		return "", errors.Wrapf(domain.ErrSignature, "%s: %s", userErrorPrefix, err)
+	}
+	if !strings.EqualFold(login.Wallet, wallet) {
+		return "", errors.Wrapf(domain.ErrSignature, "%s: typed data wallet mismatch", userErrorPrefix)
+	}
+
+	err = sign.VerifyTypedDataSignature(
+		wallet,
+		typedData,
 		req.Sign,
 	)
 	if err != nil {
-		return errors.Wrapf(err, "%s: signature check fail", userErrorPrefix)
+		hash, hashErr := sign.HashTypedData(typedData)
+		if hashErr != nil {
+			return "", errors.Wrapf(hashErr, "%s: signature check fail", userErrorPrefix)
+		}
+		err = s.verifyContract(ctx, wallet, hash, req.Sign, err)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	_, err = s.repository.GetByWallet(ctx, req.Wallet)
+	return login.Nonce, nil
+}
 
-	// if user not exists
-	if errors.Is(err, domain.ErrNoDocuments) {
+// verifyContract falls back to EIP-1271 check when ECDSA recovery doesn't match wallet,
+// ecdsaErr is returned when contract wallets are not supported
+func (s *UserService) verifyContract(ctx context.Context, wallet string, hash []byte, signature string, ecdsaErr error) error {
+	if s.contractVerifier == nil {
+		return errors.Wrapf(ecdsaErr, "%s: signature check fail", userErrorPrefix)
+	}
+	err := s.contractVerifier.Verify(ctx, wallet, hash, signature)
+	if err != nil {
+		return errors.Wrapf(domain.ErrSignature, "%s: signature check fail: %s; contract: %s", userErrorPrefix, ecdsaErr, err)
+	}
+	return nil
+}
 
-		// create new user and issue tokens
-		newUser := &domain.User{
-			ID:        shortuuid.New(),
-			Wallet:    req.Wallet,
-			Nickname:  "", // TODO: generate random nickname
-			CreatedAt: time.Now(),
+func (s *UserService) GetById(ctx context.Context, id string) (*domain.User, error) {
+	user, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: get by id", userErrorPrefix)
+	}
+	return user, nil
+}
+
+// Profile returns public view of user other players see
+func (s *UserService) Profile(ctx context.Context, id string) (*domain.Profile, error) {
+	user, err := s.repository.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoDocuments) {
+			return nil, errors.Wrapf(domain.ErrNotFound, "%s: profile %s", userErrorPrefix, id)
 		}
-		err = s.repository.Create(ctx, newUser)
+		return nil, errors.Wrapf(err, "%s: profile", userErrorPrefix)
+	}
+	if !user.DeleteRequestedAt.IsZero() {
+		return nil, errors.Wrapf(domain.ErrNotFound, "%s: profile %s is pending deletion", userErrorPrefix, id)
+	}
+	return user.Profile(), nil
+}
+
+// Search finds players by nickname prefix, listing all players when query is empty, or by wallet address.
+// Wallet matches only primary wallet of players who show it in profile.
+func (s *UserService) Search(ctx context.Context, req *domain.UserSearchReq) (*domain.ProfilePage, error) {
+	query := strings.TrimSpace(req.Query)
+
+	if account := s.searchAccount(query); account != "" {
+		page := &domain.ProfilePage{Profiles: []*domain.Profile{}}
+		user, err := s.repository.GetByWallet(ctx, account)
 		if err != nil {
-			return errors.Wrapf(err, "%s: repo save error", userErrorPrefix)
+			if errors.Is(err, domain.ErrNoDocuments) {
+				return page, nil
+			}
+			return nil, errors.Wrapf(err, "%s: search", userErrorPrefix)
+		}
+		// linked wallets are never shown, finding user by them would tell whose they are
+		if user.Wallet == account && user.Privacy.ShowWallet && !user.Privacy.Private && user.DeleteRequestedAt.IsZero() {
+			page.Profiles = append(page.Profiles, user.Profile())
 		}
+		return page, nil
 	}
 
-	return nil
+	after := ""
+	if req.Cursor != "" {
+		cursor, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err != nil {
+			return nil, errors.Wrapf(domain.ErrCursor, "%s: search", userErrorPrefix)
+		}
+		after = string(cursor)
+	}
+
+	// one extra user tells whether there is next page
+	users, err := s.repository.Search(ctx, query, after, s.cfg.SearchPageSize+1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: search", userErrorPrefix)
+	}
+
+	page := &domain.ProfilePage{Profiles: make([]*domain.Profile, 0, len(users))}
+	if len(users) > s.cfg.SearchPageSize {
+		users = users[:s.cfg.SearchPageSize]
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(users[len(users)-1].Nickname))
+	}
+	for _, user := range users {
+		page.Profiles = append(page.Profiles, user.Profile())
+	}
+	return page, nil
+}
+
+// searchAccount returns CAIP-10 account when search query is account or wallet address of supported chain
+func (s *UserService) searchAccount(query string) string {
+	if account, err := domain.NormalizeAccount(query); err == nil {
+		return account
+	}
+	for namespace, chain := range s.chains {
+		if address, err := domain.NewAddress(namespace, query); err == nil {
+			return sign.FormatAccount(namespace, chain.reference, string(address))
+		}
+	}
+	return ""
 }
 
 func (s *UserService) GetByWallet(ctx context.Context, wallet string) (*domain.User, error) {
@@ -3,19 +3,89 @@ package service
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"server/internal/domain"
 	"server/internal/service/mocks"
+	"server/pkg/nickname"
+	"server/pkg/sign"
+	signMocks "server/pkg/sign/mocks"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/lithammer/shortuuid/v3"
+	"github.com/mr-tron/base58"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+var testUserServiceConfig = UserServiceConfig{
+	NonceTTL: time.Minute,
+	Siwe: sign.SiweConfig{
+		Chain:   sign.ChainEthereum,
+		Domain:  "game.example.com",
+		URI:     "https://game.example.com",
+		ChainID: "1",
+	},
+	EVMAccountReference: "1",
+	SignMode:            sign.ModePersonal,
+	TypedData: sign.TypedDataConfig{
+		Name:              "Game",
+		Version:           "1",
+		ChainID:           1,
+		VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+	},
+	SolanaSiwe: sign.SiweConfig{
+		Chain:   sign.ChainSolana,
+		Domain:  "game.example.com",
+		URI:     "https://game.example.com",
+		ChainID: "mainnet",
+	},
+	Nickname: nickname.Rules{
+		MinLength: 3,
+		MaxLength: 20,
+		Profanity: nickname.Profanity,
+	},
+	NicknameCooldown: 24 * time.Hour,
+	BioMaxLength:     160,
+	SearchPageSize:   2,
+}
+
+// noSanctions is sanction repository of users without sanctions
+func noSanctions(t *testing.T) *mocks.SanctionRepository {
+	sanctionRepo := mocks.NewSanctionRepository(t)
+	sanctionRepo.On("GetActive", mock.Anything, mock.Anything, mock.Anything).Return([]*domain.Sanction{}, nil).Maybe()
+	return sanctionRepo
+}
+
+// testAccount is CAIP-10 account wallets of test config are stored with
+func testAccount(wallet string) string {
+	return sign.FormatAccount(sign.NamespaceEIP155, "1", wallet)
+}
+
+func testSiweMessage(wallet, nonce string) string {
+	message := &sign.SiweMessage{
+		Domain:   testUserServiceConfig.Siwe.Domain,
+		Chain:    testUserServiceConfig.Siwe.Chain,
+		Address:  wallet,
+		URI:      testUserServiceConfig.Siwe.URI,
+		Version:  "1",
+		ChainID:  testUserServiceConfig.Siwe.ChainID,
+		Nonce:    nonce,
+		IssuedAt: time.Now(),
+	}
+	return message.String()
+}
+
 func TestUserService_Auth(t *testing.T) {
 	privateKey, err := crypto.GenerateKey()
 	require.NoError(t, err)
@@ -25,17 +95,35 @@ func TestUserService_Auth(t *testing.T) {
 	require.True(t, ok)
 
 	address := crypto.PubkeyToAddress(*publicKeyECDSA).Hex()
-	messageString := "test"
-	messageBytes := []byte(messageString)
-	messageHash := crypto.Keccak256Hash(messageBytes)
+	nonce := shortuuid.New()
+	messageString := testSiweMessage(address, nonce)
+	messageHash, err := sign.HashMessage(sign.ModePersonal, messageString)
+	require.NoError(t, err)
 
-	signature, err := crypto.Sign(messageHash.Bytes(), privateKey)
+	signature, err := crypto.Sign(messageHash, privateKey)
 	require.NoError(t, err)
 
 	userAuthReq := &domain.UserAuthReq{
-		Wallet:  address,
+		Wallet:  domain.Address(address),
+		Message: messageString,
+		Sign:    hexutil.Encode(signature),
+	}
+
+	guestUpgradeReq := &domain.UserAuthReq{
+		Wallet:  domain.Address(address),
 		Message: messageString,
 		Sign:    hexutil.Encode(signature),
+		GuestID: "guest",
+	}
+
+	plainMessage := "test"
+	plainSignature, err := crypto.Sign(crypto.Keccak256Hash([]byte(plainMessage)).Bytes(), privateKey)
+	require.NoError(t, err)
+
+	userAuthPlainReq := &domain.UserAuthReq{
+		Wallet:  domain.Address(address),
+		Message: plainMessage,
+		Sign:    hexutil.Encode(plainSignature),
 	}
 
 	userAuth := &domain.User{
@@ -47,25 +135,167 @@ func TestUserService_Auth(t *testing.T) {
 
 	testCases := []struct {
 		name         string
-		expectations func(context.Context, *mocks.UserRepository)
+		expectations func(context.Context, *mocks.UserRepository, *mocks.NonceRepository)
 		input        *domain.UserAuthReq
-		err          error
+		sanctions    []*domain.Sanction
+		// walletSanctions are kept for wallet of deleted account
+		walletSanctions []*domain.Sanction
+		err             error
 	}{
 		{
-			name:  "success auth",
+			name:  "success auth new user",
 			input: userAuthReq,
-			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
-				userRepo.On("GetByWallet", ctx, userAuthReq.Wallet).Return(nil, nil)
-				userRepo.On("Create", ctx, userAuth).Return(nil)
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
 			},
 		},
+		{
+			name:  "success auth new user with taken nickname",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(domain.ErrNicknameTaken).Once()
+				userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(nil).Once()
+			},
+		},
+		{
+			name:  "concurrent auth of new user",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments).Once()
+				userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(domain.ErrAlreadyExists)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(userAuth, nil).Once()
+			},
+		},
+		{
+			name:  "success auth existing user",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(userAuth, nil)
+			},
+		},
+		{
+			name:  "login cancels deletion",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).
+					Return(&domain.User{ID: userAuth.ID, Wallet: address, DeleteRequestedAt: time.Now()}, nil)
+				userRepo.On("ScheduleDeletion", ctx, userAuth.ID, time.Time{}).Return(nil)
+			},
+		},
+		{
+			name:  "login during purge",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).
+					Return(&domain.User{ID: userAuth.ID, Wallet: address, DeleteRequestedAt: time.Now()}, nil)
+				userRepo.On("ScheduleDeletion", ctx, userAuth.ID, time.Time{}).Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrDeleted,
+		},
+		{
+			name:  "muted user logs in",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(userAuth, nil)
+			},
+			sanctions: []*domain.Sanction{{Type: domain.SanctionMute, ExpiresAt: time.Now().Add(time.Hour)}},
+		},
+		{
+			name:  "banned user is refused",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(userAuth, nil)
+			},
+			sanctions: []*domain.Sanction{
+				{Type: domain.SanctionSuspension, ExpiresAt: time.Now().Add(time.Hour)},
+				{Type: domain.SanctionBan},
+			},
+			err: domain.ErrBanned,
+		},
+		{
+			name:  "new user of banned wallet is refused",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+			},
+			walletSanctions: []*domain.Sanction{{Type: domain.SanctionBan}},
+			err:             domain.ErrBanned,
+		},
+		{
+			name:  "guest upgrade to banned wallet is refused",
+			input: guestUpgradeReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+			},
+			walletSanctions: []*domain.Sanction{{Type: domain.SanctionBan}},
+			err:             domain.ErrBanned,
+		},
+		{
+			name:  "guest upgrades",
+			input: guestUpgradeReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("UpgradeGuest", ctx, "guest", testAccount(address)).Return(nil)
+				userRepo.On("GetById", ctx, "guest").
+					Return(&domain.User{ID: "guest", Wallet: testAccount(address), Role: domain.RolePlayer}, nil)
+			},
+		},
+		{
+			name:  "guest upgrade to wallet with account is refused",
+			input: guestUpgradeReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(userAuth, nil)
+			},
+			err: domain.ErrWallet,
+		},
+		{
+			name:  "upgrade of user who is not guest",
+			input: guestUpgradeReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("UpgradeGuest", ctx, "guest", testAccount(address)).Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrGuest,
+		},
 		{
 			name:  "failed auth",
 			input: userAuthReq,
-			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
-				userRepo.On("GetByWallet", ctx, userAuthReq.Wallet).Return(nil, nil)
-				userRepo.On("Create", ctx, userAuth).Return(errors.New("error"))
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(errors.New("error"))
+			},
+			err: errors.New("error"),
+		},
+		{
+			name:  "failed auth with plain message",
+			input: userAuthPlainReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
 			},
+			err: domain.ErrSignature,
+		},
+		{
+			name:  "failed auth with used nonce",
+			input: userAuthReq,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrNonce,
 		},
 	}
 
@@ -75,22 +305,816 @@ func TestUserService_Auth(t *testing.T) {
 		ctx := context.Background()
 
 		userRepo := mocks.NewUserRepository(t)
-		userService := NewUserService(userRepo)
+		nonceRepo := mocks.NewNonceRepository(t)
+		sanctionRepo := mocks.NewSanctionRepository(t)
+		sanctionRepo.On("GetActive", ctx, userAuth.ID, mock.AnythingOfType("time.Time")).Return(test.sanctions, nil).Maybe()
+		sanctionRepo.On("GetActive", ctx, domain.WalletSubject(testAccount(address)), mock.AnythingOfType("time.Time")).
+			Return(test.walletSanctions, nil).Maybe()
+		userService := NewUserService(userRepo, nonceRepo, sanctionRepo, nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
 
-		test.expectations(ctx, userRepo)
+		test.expectations(ctx, userRepo, nonceRepo)
 
-		err := userService.Auth(ctx, test.input)
+		_, err := userService.Auth(ctx, test.input)
 
-		if err != nil {
-			if test.err != nil {
-				assert.Equal(t, test.err, err)
-			} else {
-				assert.NoError(t, err)
+		if test.err != nil {
+			assert.Error(t, err)
+			if errors.Is(test.err, domain.ErrNonce) || errors.Is(test.err, domain.ErrSignature) ||
+				errors.Is(test.err, domain.ErrWallet) || errors.Is(test.err, domain.ErrGuest) ||
+				errors.Is(test.err, domain.ErrDeleted) {
+				assert.ErrorIs(t, err, test.err)
+			}
+			if errors.Is(test.err, domain.ErrBanned) {
+				sanctionErr := &domain.SanctionError{}
+				require.ErrorAs(t, err, &sanctionErr)
+				assert.Equal(t, domain.SanctionBan, sanctionErr.Sanction.Type)
 			}
+		} else {
+			assert.NoError(t, err)
 		}
 
 		userRepo.AssertExpectations(t)
+		nonceRepo.AssertExpectations(t)
+	}
+}
+
+func TestUserService_AuthAccountReference(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	nonce := shortuuid.New()
+
+	// wallet signs in on Polygon, account is the same as on any other EVM chain
+	cfg := testUserServiceConfig
+	cfg.Siwe.ChainID = "137"
+
+	message := &sign.SiweMessage{
+		Domain:   cfg.Siwe.Domain,
+		Chain:    cfg.Siwe.Chain,
+		Address:  address,
+		URI:      cfg.Siwe.URI,
+		Version:  "1",
+		ChainID:  cfg.Siwe.ChainID,
+		Nonce:    nonce,
+		IssuedAt: time.Now(),
+	}
+	messageHash, err := sign.HashMessage(sign.ModePersonal, message.String())
+	require.NoError(t, err)
+	signature, err := crypto.Sign(messageHash, privateKey)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	userRepo := mocks.NewUserRepository(t)
+	nonceRepo := mocks.NewNonceRepository(t)
+	nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+	userRepo.On("GetByWallet", ctx, testAccount(address)).Return(&domain.User{ID: "user", Wallet: testAccount(address)}, nil)
+
+	userService := NewUserService(userRepo, nonceRepo, noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), cfg)
+	user, err := userService.Auth(ctx, &domain.UserAuthReq{
+		Wallet:  domain.Address(address),
+		Message: message.String(),
+		Sign:    hexutil.Encode(signature),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user", user.ID)
+}
+
+func TestUserService_Guest(t *testing.T) {
+	deviceID := "5f0c1c8e-6a3b-4d2e-9f7a-2b8c4d6e8f10"
+	device := hashSecret(deviceID)
+	guest := &domain.User{ID: "guest", Role: domain.RoleGuest, Device: device}
 
+	testCases := []struct {
+		name         string
+		deviceID     string
+		expectations func(context.Context, *mocks.UserRepository)
+		err          error
+	}{
+		{
+			name:     "new guest",
+			deviceID: deviceID,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByDevice", ctx, device).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("Create", ctx, mock.MatchedBy(func(user *domain.User) bool {
+					return user.Role == domain.RoleGuest && user.Device == device && user.Wallet == "" && user.Nickname != ""
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "concurrent new guest",
+			deviceID: deviceID,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByDevice", ctx, device).Return(nil, domain.ErrNoDocuments).Once()
+				userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(domain.ErrAlreadyExists)
+				userRepo.On("GetByDevice", ctx, device).Return(guest, nil).Once()
+			},
+		},
+		{
+			name:     "returning guest",
+			deviceID: deviceID,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByDevice", ctx, device).Return(guest, nil)
+			},
+		},
+		{
+			name:         "short device id",
+			deviceID:     "device",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrGuest,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		userService := NewUserService(userRepo, mocks.NewNonceRepository(t), noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+		test.expectations(ctx, userRepo)
+
+		user, err := userService.Guest(ctx, test.deviceID)
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			require.NoError(t, err)
+			assert.Equal(t, domain.RoleGuest, user.Role)
+		}
+	}
+}
+
+func TestUserService_AuthTypedData(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	nonce := shortuuid.New()
+
+	typedDataLogin := func(wallet string, chainID int64) []byte {
+		typedData := apitypes.TypedData{
+			Types: apitypes.Types{
+				"EIP712Domain": {
+					{Name: "name", Type: "string"},
+					{Name: "version", Type: "string"},
+					{Name: "chainId", Type: "uint256"},
+					{Name: "verifyingContract", Type: "address"},
+				},
+				"Login": {
+					{Name: "wallet", Type: "address"},
+					{Name: "nonce", Type: "string"},
+				},
+			},
+			PrimaryType: "Login",
+			Domain: apitypes.TypedDataDomain{
+				Name:              testUserServiceConfig.TypedData.Name,
+				Version:           testUserServiceConfig.TypedData.Version,
+				ChainId:           math.NewHexOrDecimal256(chainID),
+				VerifyingContract: testUserServiceConfig.TypedData.VerifyingContract,
+			},
+			Message: apitypes.TypedDataMessage{
+				"wallet": wallet,
+				"nonce":  nonce,
+			},
+		}
+		data, err := json.Marshal(typedData)
+		require.NoError(t, err)
+		return data
+	}
+
+	signTypedData := func(data []byte) string {
+		typedData, err := sign.ParseTypedData(data)
+		require.NoError(t, err)
+		hash, err := sign.HashTypedData(typedData)
+		require.NoError(t, err)
+		signature, err := crypto.Sign(hash, privateKey)
+		require.NoError(t, err)
+		return hexutil.Encode(signature)
+	}
+
+	validTypedData := typedDataLogin(address, 1)
+	otherChainTypedData := typedDataLogin(address, 5)
+	otherWalletTypedData := typedDataLogin("0xeF1c8b8c7f478c0BE246735c06aE80BEA3675D75", 1)
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository, *mocks.NonceRepository)
+		input        *domain.UserAuthReq
+		err          error
+	}{
+		{
+			name: "success auth",
+			input: &domain.UserAuthReq{
+				Wallet:    domain.Address(address),
+				Sign:      signTypedData(validTypedData),
+				TypedData: validTypedData,
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
+			},
+		},
+		{
+			name: "failed auth other chain",
+			input: &domain.UserAuthReq{
+				Wallet:    domain.Address(address),
+				Sign:      signTypedData(otherChainTypedData),
+				TypedData: otherChainTypedData,
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+			},
+			err: domain.ErrSignature,
+		},
+		{
+			name: "failed auth other wallet",
+			input: &domain.UserAuthReq{
+				Wallet:    domain.Address(address),
+				Sign:      signTypedData(otherWalletTypedData),
+				TypedData: otherWalletTypedData,
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+			},
+			err: domain.ErrSignature,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		nonceRepo := mocks.NewNonceRepository(t)
+		userService := NewUserService(userRepo, nonceRepo, noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+		test.expectations(ctx, userRepo, nonceRepo)
+
+		_, err := userService.Auth(ctx, test.input)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestUserService_AuthContractWallet(t *testing.T) {
+	// signature produced by contract wallet owner, not by wallet address itself
+	ownerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	wallet := "0x28e582BA14CD679FB08E47dC50b565c715Ce0979"
+	nonce := shortuuid.New()
+	messageString := testSiweMessage(wallet, nonce)
+	messageHash, err := sign.HashMessage(sign.ModePersonal, messageString)
+	require.NoError(t, err)
+
+	signature, err := crypto.Sign(messageHash, ownerKey)
+	require.NoError(t, err)
+
+	userAuthReq := &domain.UserAuthReq{
+		Wallet:  domain.Address(wallet),
+		Message: messageString,
+		Sign:    hexutil.Encode(signature),
+	}
+
+	ctx := context.Background()
+
+	t.Log("testing success auth")
+
+	userRepo := mocks.NewUserRepository(t)
+	nonceRepo := mocks.NewNonceRepository(t)
+	contractVerifier := signMocks.NewContractVerifier(t)
+	userService := NewUserService(userRepo, nonceRepo, noSanctions(t), contractVerifier, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+	contractVerifier.On("Verify", ctx, wallet, messageHash, userAuthReq.Sign).Return(nil)
+	nonceRepo.On("Consume", ctx, domain.Address(wallet), nonce).Return(nil)
+	userRepo.On("GetByWallet", ctx, testAccount(wallet)).Return(nil, domain.ErrNoDocuments)
+	userRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
+
+	_, err = userService.Auth(ctx, userAuthReq)
+	assert.NoError(t, err)
+
+	t.Log("testing failed auth")
+
+	contractVerifier = signMocks.NewContractVerifier(t)
+	userService = NewUserService(mocks.NewUserRepository(t), mocks.NewNonceRepository(t), noSanctions(t), contractVerifier, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+	contractVerifier.On("Verify", ctx, wallet, messageHash, userAuthReq.Sign).Return(errors.New("contract signature invalid"))
+
+	_, err = userService.Auth(ctx, userAuthReq)
+	assert.ErrorIs(t, err, domain.ErrSignature)
+
+	t.Log("testing contract wallets disabled")
+
+	userService = NewUserService(mocks.NewUserRepository(t), mocks.NewNonceRepository(t), noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+	_, err = userService.Auth(ctx, userAuthReq)
+	assert.Error(t, err)
+}
+
+func TestUserService_AuthSolana(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	wallet := base58.Encode(publicKey)
+	nonce := shortuuid.New()
+	message := &sign.SiweMessage{
+		Domain:   testUserServiceConfig.SolanaSiwe.Domain,
+		Chain:    testUserServiceConfig.SolanaSiwe.Chain,
+		Address:  wallet,
+		URI:      testUserServiceConfig.SolanaSiwe.URI,
+		Version:  "1",
+		ChainID:  testUserServiceConfig.SolanaSiwe.ChainID,
+		Nonce:    nonce,
+		IssuedAt: time.Now(),
+	}
+	messageString := message.String()
+
+	userAuthReq := &domain.UserAuthReq{
+		Chain:   sign.NamespaceSolana,
+		Wallet:  domain.Address(wallet),
+		Message: messageString,
+		Sign:    base58.Encode(ed25519.Sign(privateKey, []byte(messageString))),
+	}
+
+	// solana wallets never collide with ethereum ones
+	account := sign.FormatAccount(sign.NamespaceSolana, "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", wallet)
+
+	ctx := context.Background()
+
+	t.Log("testing success auth")
+
+	userRepo := mocks.NewUserRepository(t)
+	nonceRepo := mocks.NewNonceRepository(t)
+	userService := NewUserService(userRepo, nonceRepo, noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+	nonceRepo.On("Consume", ctx, domain.Address(wallet), nonce).Return(nil)
+	userRepo.On("GetByWallet", ctx, account).Return(nil, domain.ErrNoDocuments)
+	userRepo.On("Create", ctx, mock.MatchedBy(func(user *domain.User) bool {
+		return user.Wallet == account
+	})).Return(nil)
+
+	user, err := userService.Auth(ctx, userAuthReq)
+	require.NoError(t, err)
+	assert.Equal(t, account, user.Wallet)
+
+	t.Log("testing solana message verified as ethereum")
+
+	userService = NewUserService(mocks.NewUserRepository(t), mocks.NewNonceRepository(t), noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+	_, err = userService.Auth(ctx, &domain.UserAuthReq{
+		Wallet:  domain.Address(wallet),
+		Message: messageString,
+		Sign:    userAuthReq.Sign,
+	})
+	assert.ErrorIs(t, err, domain.ErrSignature)
+
+	t.Log("testing unknown chain")
+
+	_, err = userService.Auth(ctx, &domain.UserAuthReq{
+		Chain:   "cosmos",
+		Wallet:  domain.Address(wallet),
+		Message: messageString,
+		Sign:    userAuthReq.Sign,
+	})
+	assert.ErrorIs(t, err, domain.ErrSignature)
+}
+
+func TestUserService_Nonce(t *testing.T) {
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	nonceRepo := mocks.NewNonceRepository(t)
+	userService := NewUserService(userRepo, nonceRepo, noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+	wallet := "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"
+
+	nonceRepo.On("Create", ctx, mock.MatchedBy(func(nonce *domain.Nonce) bool {
+		return nonce.Wallet == domain.Address(wallet) && nonce.ExpiresAt.After(nonce.CreatedAt)
+	})).Return(nil)
+
+	nonce, err := userService.Nonce(ctx, domain.Address(wallet))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Address(wallet), nonce.Wallet)
+	assert.GreaterOrEqual(t, len(nonce.Value), 8)
+
+	_, err = userService.Nonce(ctx, "")
+	assert.ErrorIs(t, err, domain.ErrNonce)
+}
+
+func TestUserService_LinkWallet(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	nonce := shortuuid.New()
+	messageString := testSiweMessage(address, nonce)
+	messageHash, err := sign.HashMessage(sign.ModePersonal, messageString)
+	require.NoError(t, err)
+
+	signature, err := crypto.Sign(messageHash, privateKey)
+	require.NoError(t, err)
+
+	userLinkReq := &domain.UserAuthReq{
+		Wallet:  domain.Address(address),
+		Message: messageString,
+		Sign:    hexutil.Encode(signature),
+	}
+
+	user := &domain.User{
+		ID:     "user",
+		Wallet: "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935",
+	}
+
+	testCases := []struct {
+		name         string
+		expectations func(context.Context, *mocks.UserRepository, *mocks.NonceRepository)
+		err          error
+	}{
+		{
+			name: "success link",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("AddWallet", ctx, "user", testAccount(address)).Return(nil)
+				userRepo.On("GetById", ctx, "user").Return(user, nil)
+			},
+		},
+		{
+			name: "wallet of another user",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(&domain.User{ID: "other", Wallet: testAccount(address)}, nil)
+			},
+			err: domain.ErrWallet,
+		},
+		{
+			name: "wallet linked to another user meanwhile",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(nil, domain.ErrNoDocuments)
+				userRepo.On("AddWallet", ctx, "user", testAccount(address)).Return(domain.ErrAlreadyExists)
+			},
+			err: domain.ErrWallet,
+		},
+		{
+			name: "wallet already linked",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(nil)
+				userRepo.On("GetByWallet", ctx, testAccount(address)).Return(user, nil)
+			},
+			err: domain.ErrWallet,
+		},
+		{
+			name: "used nonce",
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository, nonceRepo *mocks.NonceRepository) {
+				nonceRepo.On("Consume", ctx, domain.Address(address), nonce).Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrNonce,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		nonceRepo := mocks.NewNonceRepository(t)
+		userService := NewUserService(userRepo, nonceRepo, noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+		test.expectations(ctx, userRepo, nonceRepo)
+
+		_, err := userService.LinkWallet(ctx, "user", userLinkReq)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestUserService_UnlinkWallet(t *testing.T) {
+	primary := "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"
+	linked := "0x28e582BA14CD679FB08E47dC50b565c715Ce0979"
+
+	testCases := []struct {
+		name         string
+		user         *domain.User
+		wallet       string
+		expectations func(context.Context, *mocks.UserRepository)
+		err          error
+	}{
+		{
+			name:   "unlink linked wallet",
+			user:   &domain.User{ID: "user", Wallet: primary, Wallets: []string{linked}},
+			wallet: linked,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("RemoveWallet", ctx, "user", linked).Return(nil)
+			},
+		},
+		{
+			name:   "unlink primary wallet",
+			user:   &domain.User{ID: "user", Wallet: primary, Wallets: []string{linked}},
+			wallet: primary,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("ReplacePrimaryWallet", ctx, "user", primary, linked).Return(nil)
+			},
+		},
+		{
+			name:         "unlink last wallet",
+			user:         &domain.User{ID: "user", Wallet: primary},
+			wallet:       primary,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrWallet,
+		},
+		{
+			name:         "unlink not linked wallet",
+			user:         &domain.User{ID: "user", Wallet: primary},
+			wallet:       linked,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrNotFound,
+		},
+		{
+			name:   "concurrent unlink",
+			user:   &domain.User{ID: "user", Wallet: primary, Wallets: []string{linked}},
+			wallet: linked,
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("RemoveWallet", ctx, "user", linked).Return(domain.ErrNoDocuments)
+			},
+			err: domain.ErrWallet,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		userService := NewUserService(userRepo, mocks.NewNonceRepository(t), noSanctions(t), nil, nickname.NewGenerator(testUserServiceConfig.Nickname), testUserServiceConfig)
+
+		userRepo.On("GetById", ctx, "user").Return(test.user, nil)
+		test.expectations(ctx, userRepo)
+
+		_, err := userService.UnlinkWallet(ctx, "user", test.wallet)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestUserService_Update(t *testing.T) {
+	name := func(nickname string) *domain.UserUpdateReq {
+		return &domain.UserUpdateReq{Nickname: &nickname}
+	}
+	bio, country, invalidCountry, longBio := " gg ", "de", "Germany", strings.Repeat("a", 161)
+
+	testCases := []struct {
+		name         string
+		user         *domain.User
+		input        *domain.UserUpdateReq
+		expectations func(context.Context, *mocks.UserRepository)
+		err          error
+	}{
+		{
+			name:  "rename generated nickname",
+			user:  &domain.User{ID: "user", Nickname: "BraveOtter42"},
+			input: name(" Player_One "),
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("Update", ctx, mock.MatchedBy(func(user *domain.User) bool {
+					return user.Nickname == "Player_One" && !user.NicknameChangedAt.IsZero()
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "rename after cooldown",
+			user:  &domain.User{ID: "user", Nickname: "Player_One", NicknameChangedAt: time.Now().Add(-48 * time.Hour)},
+			input: name("Player_Two"),
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("Update", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
+			},
+		},
+		{
+			name:         "same nickname",
+			user:         &domain.User{ID: "user", Nickname: "Player_One", NicknameChangedAt: time.Now()},
+			input:        name("Player_One"),
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+		},
+		{
+			name:         "rename during cooldown",
+			user:         &domain.User{ID: "user", Nickname: "Player_One", NicknameChangedAt: time.Now().Add(-time.Hour)},
+			input:        name("Player_Two"),
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrNickname,
+		},
+		{
+			name:         "invalid nickname",
+			user:         &domain.User{ID: "user", Nickname: "BraveOtter42"},
+			input:        name("Player One"),
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrNickname,
+		},
+		{
+			name:  "taken nickname",
+			user:  &domain.User{ID: "user", Nickname: "BraveOtter42"},
+			input: name("Player_One"),
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("Update", ctx, mock.AnythingOfType("*domain.User")).Return(domain.ErrNicknameTaken)
+			},
+			err: domain.ErrNicknameTaken,
+		},
+		{
+			name: "update profile",
+			user: &domain.User{ID: "user", Nickname: "BraveOtter42"},
+			input: &domain.UserUpdateReq{
+				Avatar:  &domain.Avatar{NFT: "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769"},
+				Bio:     &bio,
+				Country: &country,
+				Privacy: &domain.Privacy{HideCountry: true},
+			},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("Update", ctx, mock.MatchedBy(func(user *domain.User) bool {
+					return user.Bio == "gg" && user.Country == "DE" && user.Privacy.HideCountry && user.Avatar.NFT != ""
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "invalid avatar",
+			user:         &domain.User{ID: "user", Nickname: "BraveOtter42"},
+			input:        &domain.UserUpdateReq{Avatar: &domain.Avatar{URL: "http://example.com/avatar.png"}},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrProfile,
+		},
+		{
+			name:         "invalid country",
+			user:         &domain.User{ID: "user", Nickname: "BraveOtter42"},
+			input:        &domain.UserUpdateReq{Country: &invalidCountry},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrProfile,
+		},
+		{
+			name:         "long bio",
+			user:         &domain.User{ID: "user", Nickname: "BraveOtter42"},
+			input:        &domain.UserUpdateReq{Bio: &longBio},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrProfile,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		userService := NewUserService(userRepo, mocks.NewNonceRepository(t), noSanctions(t), nil, mocks.NewNicknameGenerator(t), testUserServiceConfig)
+
+		userRepo.On("GetById", ctx, "user").Return(test.user, nil)
+		test.expectations(ctx, userRepo)
+
+		_, err := userService.Update(ctx, "user", test.input)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestUserService_Profile(t *testing.T) {
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	userService := NewUserService(userRepo, mocks.NewNonceRepository(t), noSanctions(t), nil, mocks.NewNicknameGenerator(t), testUserServiceConfig)
+
+	userRepo.On("GetById", ctx, "user").Return(&domain.User{
+		ID:       "user",
+		Nickname: "BraveOtter42",
+		Wallet:   testAccount("0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"),
+		Bio:      "gg",
+		Privacy:  domain.Privacy{Private: true},
+	}, nil)
+	userRepo.On("GetById", ctx, "missing").Return(nil, domain.ErrNoDocuments)
+
+	profile, err := userService.Profile(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.Profile{ID: "user", Nickname: "BraveOtter42"}, profile)
+
+	_, err = userService.Profile(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestUserService_Search(t *testing.T) {
+	wallet := testAccount("0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935")
+	users := []*domain.User{
+		{ID: "1", Nickname: "BraveOtter1"},
+		{ID: "2", Nickname: "BraveOtter2"},
+		{ID: "3", Nickname: "BraveOtter3"},
+	}
+	cursor := base64.RawURLEncoding.EncodeToString([]byte("BraveOtter2"))
+
+	testCases := []struct {
+		name         string
+		input        *domain.UserSearchReq
+		expectations func(context.Context, *mocks.UserRepository)
+		profiles     []string
+		nextCursor   string
+		err          error
+	}{
+		{
+			name:  "first page",
+			input: &domain.UserSearchReq{Query: " Brave "},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("Search", ctx, "Brave", "", 3).Return(users, nil)
+			},
+			profiles:   []string{"1", "2"},
+			nextCursor: cursor,
+		},
+		{
+			name:  "last page",
+			input: &domain.UserSearchReq{Query: "Brave", Cursor: cursor},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("Search", ctx, "Brave", "BraveOtter2", 3).Return(users[2:], nil)
+			},
+			profiles: []string{"3"},
+		},
+		{
+			name:         "invalid cursor",
+			input:        &domain.UserSearchReq{Query: "Brave", Cursor: "!"},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {},
+			err:          domain.ErrCursor,
+		},
+		{
+			name:  "shown wallet",
+			input: &domain.UserSearchReq{Query: "0xef209bee800ef5c7d20a67f46e007a970eaf9935"},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByWallet", ctx, wallet).
+					Return(&domain.User{ID: "1", Wallet: wallet, Privacy: domain.Privacy{ShowWallet: true}}, nil)
+			},
+			profiles: []string{"1"},
+		},
+		{
+			name:  "hidden wallet",
+			input: &domain.UserSearchReq{Query: wallet},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByWallet", ctx, wallet).Return(&domain.User{ID: "1", Wallet: wallet}, nil)
+			},
+			profiles: []string{},
+		},
+		{
+			name:  "linked wallet",
+			input: &domain.UserSearchReq{Query: wallet},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByWallet", ctx, wallet).Return(&domain.User{
+					ID:      "1",
+					Wallet:  testAccount("0x28e582ba14cd679fb08e47dc50b565c715ce0979"),
+					Wallets: []string{wallet},
+					Privacy: domain.Privacy{ShowWallet: true},
+				}, nil)
+			},
+			profiles: []string{},
+		},
+		{
+			name:  "unknown wallet",
+			input: &domain.UserSearchReq{Query: wallet},
+			expectations: func(ctx context.Context, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByWallet", ctx, wallet).Return(nil, domain.ErrNoDocuments)
+			},
+			profiles: []string{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		ctx := context.Background()
+
+		userRepo := mocks.NewUserRepository(t)
+		userService := NewUserService(userRepo, mocks.NewNonceRepository(t), noSanctions(t), nil, mocks.NewNicknameGenerator(t), testUserServiceConfig)
+
+		test.expectations(ctx, userRepo)
+
+		page, err := userService.Search(ctx, test.input)
+
+		if test.err != nil {
+			assert.ErrorIs(t, err, test.err)
+			continue
+		}
+		require.NoError(t, err)
+		ids := []string{}
+		for _, profile := range page.Profiles {
+			ids = append(ids, profile.ID)
+		}
+		assert.Equal(t, test.profiles, ids)
+		assert.Equal(t, test.nextCursor, page.NextCursor)
 	}
 }
 
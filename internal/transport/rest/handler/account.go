@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/transport/rest/model"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:generate mockery --dir . --name AccountService --output ./mocks
+type AccountService interface {
+	RequestDeletion(context.Context, string) (time.Time, error)
+	Export(context.Context, string) (*domain.UserExport, error)
+}
+
+// AccountHandler gives player control over personal data
+type AccountHandler struct {
+	service      AccountService
+	tokenService TokenService
+}
+
+func NewAccountHandler(service AccountService, tokenService TokenService) *AccountHandler {
+	return &AccountHandler{service, tokenService}
+}
+
+// Delete schedules account deletion and logs user out everywhere
+func (h *AccountHandler) Delete(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	purgeAt, err := h.service.RequestDeletion(ctx.Request().Context(), claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	err = h.tokenService.LogoutAll(ctx.Request().Context(), claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusAccepted, &model.UserDeletion{PurgeAt: purgeAt})
+}
+
+// Export returns JSON archive of everything stored about user
+func (h *AccountHandler) Export(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	export, err := h.service.Export(ctx.Request().Context(), claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	restExport := &model.UserExport{
+		User:          restUser(export.User),
+		Sessions:      make([]*model.Session, 0, len(export.Sessions)),
+		RefreshTokens: make([]*model.RefreshToken, 0, len(export.RefreshTokens)),
+		RoleChanges:   make([]*model.RoleChange, 0, len(export.RoleChanges)),
+		Sanctions:     make([]*model.Sanction, 0, len(export.Sanctions)),
+		APIKeys:       make([]*model.APIKey, 0, len(export.APIKeys)),
+		ExportedAt:    export.ExportedAt,
+	}
+	for _, session := range export.Sessions {
+		restExport.Sessions = append(restExport.Sessions, &model.Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == claims.SessionID,
+		})
+	}
+	for _, token := range export.RefreshTokens {
+		restExport.RefreshTokens = append(restExport.RefreshTokens, &model.RefreshToken{
+			SessionID: token.Family,
+			Wallet:    token.Wallet,
+			Used:      token.Used,
+			Revoked:   token.Revoked,
+			CreatedAt: token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+		})
+	}
+	for _, change := range export.RoleChanges {
+		restExport.RoleChanges = append(restExport.RoleChanges, restRoleChange(change))
+	}
+	for _, sanction := range export.Sanctions {
+		restExport.Sanctions = append(restExport.Sanctions, restSanction(sanction, export.ExportedAt))
+	}
+	for _, key := range export.APIKeys {
+		restExport.APIKeys = append(restExport.APIKeys, restAPIKey(key))
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="user-%s.json"`, export.User.ID))
+	return ctx.JSON(http.StatusOK, restExport)
+}
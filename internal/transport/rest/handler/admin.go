@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockery --dir . --name AdminService --output ./mocks
+type AdminService interface {
+	GetUser(context.Context, string) (*domain.User, error)
+	GetUserByWallet(context.Context, string) (*domain.User, error)
+	SetRole(context.Context, *domain.Principal, string, domain.Role) (*domain.User, error)
+	RoleChanges(context.Context, string) ([]*domain.RoleChange, error)
+	ResetProfile(context.Context, *domain.Principal, string) (*domain.User, error)
+}
+
+// AdminHandler is user management API of staff, routes are guarded by PermissionMiddleware
+type AdminHandler struct {
+	service      AdminService
+	tokenService TokenService
+}
+
+func NewAdminHandler(service AdminService, tokenService TokenService) *AdminHandler {
+	return &AdminHandler{service, tokenService}
+}
+
+// GetUser returns user with private fields
+func (h *AdminHandler) GetUser(ctx echo.Context) error {
+	user, err := h.service.GetUser(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return adminError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+// FindUser returns user by primary or linked wallet
+func (h *AdminHandler) FindUser(ctx echo.Context) error {
+	account, err := domain.NormalizeAccount(ctx.QueryParam("wallet"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, err := h.service.GetUserByWallet(ctx.Request().Context(), account)
+	if err != nil {
+		return adminError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+func (h *AdminHandler) SetRole(ctx echo.Context) error {
+	principal, err := authPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	restAdminRoleReq := new(model.AdminRoleReq)
+	err = ctx.Bind(restAdminRoleReq)
+	if err != nil {
+		return err
+	}
+
+	role, err := domain.ParseRole(restAdminRoleReq.Role)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, err := h.service.SetRole(ctx.Request().Context(), principal, ctx.Param("id"), role)
+	if err != nil {
+		return adminError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+// RoleChanges returns who changed role of user and when
+func (h *AdminHandler) RoleChanges(ctx echo.Context) error {
+	changes, err := h.service.RoleChanges(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	restChanges := make([]*model.RoleChange, 0, len(changes))
+	for _, change := range changes {
+		restChanges = append(restChanges, restRoleChange(change))
+	}
+
+	return ctx.JSON(http.StatusOK, restChanges)
+}
+
+// Logout ends all sessions of user, staff can be logged out only by staff of higher role
+func (h *AdminHandler) Logout(ctx echo.Context) error {
+	principal, err := authPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.service.GetUser(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return adminError(err)
+	}
+	if !principal.Outranks(user.Role) {
+		return echo.NewHTTPError(http.StatusForbidden, "permission denied")
+	}
+
+	err = h.tokenService.LogoutAll(ctx.Request().Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ResetProfile replaces nickname with generated one and clears avatar and bio
+func (h *AdminHandler) ResetProfile(ctx echo.Context) error {
+	principal, err := authPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.service.ResetProfile(ctx.Request().Context(), principal, ctx.Param("id"))
+	if err != nil {
+		return adminError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+func restRoleChange(change *domain.RoleChange) *model.RoleChange {
+	return &model.RoleChange{
+		ID:           change.ID,
+		UserID:       change.UserID,
+		Role:         string(change.Role),
+		PreviousRole: string(change.PreviousRole),
+		ChangedBy:    change.ChangedBy,
+		CreatedAt:    change.CreatedAt,
+	}
+}
+
+// adminError maps errors of admin service to http ones
+func adminError(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	}
+	if errors.Is(err, domain.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "permission denied")
+	}
+	if errors.Is(err, domain.ErrRole) {
+		return echo.NewHTTPError(http.StatusConflict, errors.Cause(err).Error())
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/internal/domain"
+	"server/internal/transport/rest/handler"
+	"server/internal/transport/rest/handler/mocks"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_Logout(t *testing.T) {
+	moderator := &domain.Principal{Kind: domain.PrincipalUser, ID: "moderator", Role: domain.RoleModerator}
+	admin := &domain.Principal{Kind: domain.PrincipalUser, ID: "admin", Role: domain.RoleAdmin}
+	apiKey := &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "key", Scopes: []domain.Permission{domain.PermissionUsersManage}}
+
+	testCases := []struct {
+		name      string
+		principal *domain.Principal
+		role      domain.Role
+		status    int
+	}{
+		{name: "player by moderator", principal: moderator, role: domain.RolePlayer, status: http.StatusNoContent},
+		{name: "player by api key", principal: apiKey, role: domain.RolePlayer, status: http.StatusNoContent},
+		{name: "moderator by admin", principal: admin, role: domain.RoleModerator, status: http.StatusNoContent},
+		{name: "moderator by moderator", principal: moderator, role: domain.RoleModerator, status: http.StatusForbidden},
+		{name: "admin by moderator", principal: moderator, role: domain.RoleAdmin, status: http.StatusForbidden},
+		{name: "admin by admin", principal: admin, role: domain.RoleAdmin, status: http.StatusForbidden},
+		{name: "moderator by api key", principal: apiKey, role: domain.RoleModerator, status: http.StatusForbidden},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		adminService := mocks.NewAdminService(t)
+		tokenService := mocks.NewTokenService(t)
+		adminService.On("GetUser", mock.Anything, "user").Return(&domain.User{ID: "user", Role: test.role}, nil)
+		if test.status == http.StatusNoContent {
+			tokenService.On("LogoutAll", mock.Anything, "user").Return(nil)
+		}
+		adminHandler := handler.NewAdminHandler(adminService, tokenService)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/user/logout", nil)
+		rec := httptest.NewRecorder()
+		ctx := echo.New().NewContext(req, rec)
+		ctx.SetParamNames("id")
+		ctx.SetParamValues("user")
+		ctx.Set("principal", test.principal)
+
+		err := adminHandler.Logout(ctx)
+		if test.status == http.StatusNoContent {
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		} else {
+			httpErr, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			assert.Equal(t, test.status, httpErr.Code)
+		}
+	}
+}
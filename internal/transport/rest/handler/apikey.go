@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/transport/rest/model"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+// APIKeyHeader is request header services pass API key in
+const APIKeyHeader = "X-API-Key"
+
+//go:generate mockery --dir . --name APIKeyService --output ./mocks
+type APIKeyService interface {
+	Create(context.Context, string, *domain.APIKeyReq) (*domain.APIKey, string, error)
+	List(context.Context) ([]*domain.APIKey, error)
+	Revoke(context.Context, string) error
+	Authenticate(context.Context, string) (*domain.APIKey, error)
+}
+
+// APIKeyHandler is API key management API of admins, routes are guarded by PermissionMiddleware
+type APIKeyHandler struct {
+	service APIKeyService
+}
+
+func NewAPIKeyHandler(service APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service}
+}
+
+// Create returns new key, key can't be retrieved later
+func (h *APIKeyHandler) Create(ctx echo.Context) error {
+	principal, err := authPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	restAPIKeyReq := new(model.APIKeyReq)
+	err = ctx.Bind(restAPIKeyReq)
+	if err != nil {
+		return err
+	}
+
+	domainAPIKeyReq := &domain.APIKeyReq{
+		Name:   restAPIKeyReq.Name,
+		Scopes: make([]domain.Permission, 0, len(restAPIKeyReq.Scopes)),
+	}
+	for _, scope := range restAPIKeyReq.Scopes {
+		domainAPIKeyReq.Scopes = append(domainAPIKeyReq.Scopes, domain.Permission(scope))
+	}
+
+	key, token, err := h.service.Create(ctx.Request().Context(), principal.Actor(), domainAPIKeyReq)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKey) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, &model.APIKeyRes{
+		APIKey: *restAPIKey(key),
+		Key:    token,
+	})
+}
+
+// List returns all keys including revoked ones
+func (h *APIKeyHandler) List(ctx echo.Context) error {
+	keys, err := h.service.List(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	restAPIKeys := make([]*model.APIKey, 0, len(keys))
+	for _, key := range keys {
+		restAPIKeys = append(restAPIKeys, restAPIKey(key))
+	}
+
+	return ctx.JSON(http.StatusOK, restAPIKeys)
+}
+
+func (h *APIKeyHandler) Revoke(ctx echo.Context) error {
+	err := h.service.Revoke(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+		}
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// AuthMiddleware authenticates either service by API key header or player by access token
+// checked with jwtAuth middlewares, and puts caller to context as principal
+func AuthMiddleware(apiKeyService APIKeyService, jwtAuth ...echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := func(ctx echo.Context) error {
+			claims, err := authClaims(ctx)
+			if err != nil {
+				return err
+			}
+			ctx.Set(principalKey, claims.principal())
+			return next(ctx)
+		}
+		for i := len(jwtAuth) - 1; i >= 0; i-- {
+			withJWT = jwtAuth[i](withJWT)
+		}
+
+		return func(ctx echo.Context) error {
+			token := ctx.Request().Header.Get(APIKeyHeader)
+			if token == "" {
+				return withJWT(ctx)
+			}
+
+			key, err := apiKeyService.Authenticate(ctx.Request().Context(), token)
+			if err != nil {
+				if errors.Is(err, domain.ErrToken) {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid api key")
+				}
+				return err
+			}
+
+			ctx.Set(principalKey, &domain.Principal{
+				Kind:   domain.PrincipalAPIKey,
+				ID:     key.ID,
+				Scopes: key.Scopes,
+			})
+			return next(ctx)
+		}
+	}
+}
+
+func restAPIKey(key *domain.APIKey) *model.APIKey {
+	restAPIKey := &model.APIKey{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    make([]string, 0, len(key.Scopes)),
+		CreatedBy: key.CreatedBy,
+		CreatedAt: key.CreatedAt,
+	}
+	for _, scope := range key.Scopes {
+		restAPIKey.Scopes = append(restAPIKey.Scopes, string(scope))
+	}
+	if !key.LastUsedAt.IsZero() {
+		restAPIKey.LastUsedAt = &key.LastUsedAt
+	}
+	if !key.RevokedAt.IsZero() {
+		restAPIKey.RevokedAt = &key.RevokedAt
+	}
+	return restAPIKey
+}
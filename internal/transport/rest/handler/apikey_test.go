@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/internal/domain"
+	"server/internal/transport/rest/handler"
+	"server/internal/transport/rest/handler/mocks"
+	"server/pkg/jwk"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	privateKey, err := jwk.GenerateKey(jwk.AlgorithmEdDSA)
+	require.NoError(t, err)
+
+	keyService := mocks.NewKeyService(t)
+	keyService.On("PublicKey", "kid").Return(&domain.SigningKey{ID: "kid", Algorithm: jwk.AlgorithmEdDSA, PrivateKey: privateKey}, nil).Maybe()
+
+	apiKeyService := mocks.NewAPIKeyService(t)
+	apiKeyService.On("Authenticate", mock.Anything, "reader").
+		Return(&domain.APIKey{ID: "reader", Scopes: []domain.Permission{domain.PermissionUsersRead}}, nil).Maybe()
+	apiKeyService.On("Authenticate", mock.Anything, "revoked").
+		Return(nil, errors.Wrap(domain.ErrToken, "api key revoked")).Maybe()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"sub":  "user",
+		"role": "moderator",
+		"exp":  time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "kid"
+	accessToken, err := token.SignedString(privateKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		apiKey      string
+		accessToken string
+		permission  domain.Permission
+		status      int
+		principal   *domain.Principal
+	}{
+		{
+			name:       "api key",
+			apiKey:     "reader",
+			permission: domain.PermissionUsersRead,
+			status:     http.StatusOK,
+			principal:  &domain.Principal{Kind: domain.PrincipalAPIKey, ID: "reader", Scopes: []domain.Permission{domain.PermissionUsersRead}},
+		},
+		{name: "api key without scope", apiKey: "reader", permission: domain.PermissionUsersManage, status: http.StatusForbidden},
+		{name: "revoked api key", apiKey: "revoked", permission: domain.PermissionUsersRead, status: http.StatusUnauthorized},
+		{
+			name:        "access token",
+			accessToken: accessToken,
+			permission:  domain.PermissionUsersManage,
+			status:      http.StatusOK,
+			principal:   &domain.Principal{Kind: domain.PrincipalUser, ID: "user", Role: domain.RoleModerator},
+		},
+		{name: "no credentials", permission: domain.PermissionUsersRead, status: http.StatusUnauthorized},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/users/user", nil)
+		if test.apiKey != "" {
+			req.Header.Set(handler.APIKeyHeader, test.apiKey)
+		}
+		if test.accessToken != "" {
+			req.Header.Set(echo.HeaderAuthorization, `Bearer `+test.accessToken)
+		}
+		rec := httptest.NewRecorder()
+
+		e := echo.New()
+		ctx := e.NewContext(req, rec)
+		next := func(ctx echo.Context) error {
+			return ctx.NoContent(http.StatusOK)
+		}
+		auth := handler.AuthMiddleware(apiKeyService, handler.JWTMiddleware(keyService))
+		err = auth(handler.PermissionMiddleware(test.permission)(next))(ctx)
+
+		if test.status == http.StatusOK {
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, test.principal, ctx.Get("principal"))
+		} else {
+			httpErr, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			assert.Equal(t, test.status, httpErr.Code)
+		}
+	}
+}
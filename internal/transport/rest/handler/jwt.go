@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"server/internal/config"
+	"server/internal/domain"
+	"server/pkg/jwk"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/lithammer/shortuuid/v3"
+)
+
+var (
+	jwtErrorPrefix = "[transport.rest.handler.jwt]"
+)
+
+// principalKey is echo context key of authenticated caller
+const principalKey = "principal"
+
+type jwtCustomClaims struct {
+	Wallet    string      `json:"wallet"`
+	SessionID string      `json:"sid,omitempty"`
+	Role      domain.Role `json:"role,omitempty"`
+	// IssuedAtMs is issue time in milliseconds, iat has seconds precision
+	// and can't tell tokens issued in the second of logout everywhere before it from ones after it
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func (c *jwtCustomClaims) accessToken() *domain.AccessToken {
+	accessToken := &domain.AccessToken{
+		ID:        c.ID,
+		UserID:    c.Subject,
+		SessionID: c.SessionID,
+	}
+	switch {
+	case c.IssuedAtMs != 0:
+		accessToken.IssuedAt = time.UnixMilli(c.IssuedAtMs)
+	case c.IssuedAt != nil:
+		accessToken.IssuedAt = c.IssuedAt.Time
+	}
+	if c.ExpiresAt != nil {
+		accessToken.ExpiresAt = c.ExpiresAt.Time
+	}
+	return accessToken
+}
+
+func (c *jwtCustomClaims) principal() *domain.Principal {
+	return &domain.Principal{
+		Kind: domain.PrincipalUser,
+		ID:   c.Subject,
+		Role: c.Role,
+	}
+}
+
+//go:generate mockery --dir . --name KeyService --output ./mocks
+type KeyService interface {
+	SigningKey() (*domain.SigningKey, error)
+	PublicKey(string) (*domain.SigningKey, error)
+	PublicKeys() []*domain.SigningKey
+}
+
+// JWTMiddleware validates access token signed by one of published keys and puts it to context
+func JWTMiddleware(keyService KeyService) echo.MiddlewareFunc {
+	return echojwt.WithConfig(echojwt.Config{
+		KeyFunc: func(token *jwt.Token) (interface{}, error) {
+			kid, ok := token.Header["kid"].(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: token kid not found", jwtErrorPrefix)
+			}
+			key, err := keyService.PublicKey(kid)
+			if err != nil {
+				return nil, err
+			}
+			if token.Method.Alg() != key.Algorithm {
+				return nil, fmt.Errorf("%s: token algorithm %s doesn't match key", jwtErrorPrefix, token.Method.Alg())
+			}
+			return key.PrivateKey.Public(), nil
+		},
+		NewClaimsFunc: func(c echo.Context) jwt.Claims {
+			return new(jwtCustomClaims)
+		},
+	})
+}
+
+// RevocationMiddleware rejects access tokens revoked by logout, must follow JWTMiddleware
+func RevocationMiddleware(tokenService TokenService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, err := authClaims(ctx)
+			if err != nil {
+				return err
+			}
+
+			revoked, err := tokenService.IsRevoked(ctx.Request().Context(), claims.accessToken())
+			if err != nil {
+				return err
+			}
+			if revoked {
+				return echo.NewHTTPError(http.StatusUnauthorized, "token revoked")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// PermissionMiddleware rejects callers which role or API key scopes lack permission,
+// must follow JWTMiddleware or AuthMiddleware
+func PermissionMiddleware(permission domain.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			principal, err := authPrincipal(ctx)
+			if err != nil {
+				return err
+			}
+
+			if !principal.Can(permission) {
+				return echo.NewHTTPError(http.StatusForbidden, "permission denied")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// authClaims returns claims of access token validated by JWTMiddleware
+func authClaims(ctx echo.Context) (*jwtCustomClaims, error) {
+	auth, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return nil, fmt.Errorf("%s: auth header error", jwtErrorPrefix)
+	}
+
+	if !auth.Valid {
+		return nil, fmt.Errorf("%s: auth invalid", jwtErrorPrefix)
+	}
+
+	claims, ok := auth.Claims.(*jwtCustomClaims)
+	if !ok {
+		return nil, fmt.Errorf("%s: auth claims error", jwtErrorPrefix)
+	}
+
+	return claims, nil
+}
+
+// authPrincipal returns caller authenticated by AuthMiddleware or player of access token validated by JWTMiddleware
+func authPrincipal(ctx echo.Context) (*domain.Principal, error) {
+	if principal, ok := ctx.Get(principalKey).(*domain.Principal); ok {
+		return principal, nil
+	}
+
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return claims.principal(), nil
+}
+
+// signAuthToken issues short-lived access token signed by current key
+func signAuthToken(keyService KeyService, user *domain.User, sessionID string) (string, error) {
+	cfg := config.Get()
+
+	key, err := keyService.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	method, err := jwk.SigningMethod(key.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := &jwtCustomClaims{
+		user.Wallet,
+		sessionID,
+		user.Role,
+		now.UnixMilli(),
+		jwt.RegisteredClaims{
+			ID:        shortuuid.New(),
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.AccessTokenTTL)),
+		},
+	}
+
+	// Create token with claims
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+
+	// Generate encoded token
+	return token.SignedString(key.PrivateKey)
+}
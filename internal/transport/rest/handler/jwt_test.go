@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/internal/domain"
+	"server/internal/transport/rest/handler"
+	"server/internal/transport/rest/handler/mocks"
+	"server/pkg/jwk"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionMiddleware(t *testing.T) {
+	privateKey, err := jwk.GenerateKey(jwk.AlgorithmEdDSA)
+	require.NoError(t, err)
+
+	keyService := mocks.NewKeyService(t)
+	keyService.On("PublicKey", "kid").Return(&domain.SigningKey{ID: "kid", Algorithm: jwk.AlgorithmEdDSA, PrivateKey: privateKey}, nil)
+
+	testCases := []struct {
+		name   string
+		role   string
+		status int
+	}{
+		{name: "admin", role: "admin", status: http.StatusOK},
+		{name: "player", role: "player", status: http.StatusForbidden},
+		{name: "token without role", role: "", status: http.StatusForbidden},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"sub":  "user",
+			"role": test.role,
+			"exp":  time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = "kid"
+		signed, err := token.SignedString(privateKey)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPut, "/admin/users/user/role", nil)
+		req.Header.Set(echo.HeaderAuthorization, `Bearer `+signed)
+		rec := httptest.NewRecorder()
+
+		e := echo.New()
+		next := func(ctx echo.Context) error {
+			return ctx.NoContent(http.StatusOK)
+		}
+		err = handler.JWTMiddleware(keyService)(handler.PermissionMiddleware(domain.PermissionRolesManage)(next))(e.NewContext(req, rec))
+
+		if test.status == http.StatusOK {
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		} else {
+			httpErr, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			assert.Equal(t, test.status, httpErr.Code)
+		}
+	}
+}
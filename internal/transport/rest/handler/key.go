@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"server/pkg/jwk"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	keyErrorPrefix = "[transport.rest.handler.key]"
+)
+
+// jwksMaxAge is how long clients may cache JWKS, short enough for them to get new key
+// before it signs tokens, see key publish lead of service
+const jwksMaxAge = time.Minute
+
+type KeyHandler struct {
+	service KeyService
+}
+
+func NewKeyHandler(service KeyService) *KeyHandler {
+	return &KeyHandler{service}
+}
+
+// JWKS publishes public keys access tokens can be verified with
+func (h *KeyHandler) JWKS(ctx echo.Context) error {
+	keys := h.service.PublicKeys()
+
+	set := jwk.Set{Keys: make([]jwk.Key, 0, len(keys))}
+	for _, key := range keys {
+		publicKey, err := jwk.PublicKey(key.ID, key.Algorithm, key.PrivateKey.Public())
+		if err != nil {
+			return errors.Wrapf(err, "%s: jwks", keyErrorPrefix)
+		}
+		set.Keys = append(set.Keys, publicKey)
+	}
+
+	ctx.Response().Header().Set(echo.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+	return ctx.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,45 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/domain"
+	"server/internal/transport/rest/handler"
+	"server/internal/transport/rest/handler/mocks"
+	"server/pkg/jwk"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyHandler_JWKS(t *testing.T) {
+	privateKey, err := jwk.GenerateKey(jwk.AlgorithmEdDSA)
+	require.NoError(t, err)
+
+	keyService := mocks.NewKeyService(t)
+	keyService.On("PublicKeys").Return([]*domain.SigningKey{
+		{ID: "kid", Algorithm: jwk.AlgorithmEdDSA, PrivateKey: privateKey},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+
+	err = handler.NewKeyHandler(keyService).JWKS(c)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	set := jwk.Set{}
+	err = json.Unmarshal(rec.Body.Bytes(), &set)
+	require.NoError(t, err)
+
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "kid", set.Keys[0].KeyID)
+	assert.Equal(t, "OKP", set.Keys[0].KeyType)
+	assert.NotContains(t, rec.Body.String(), `"d"`)
+}
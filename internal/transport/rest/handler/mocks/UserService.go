@@ -15,17 +15,55 @@ type UserService struct {
 }
 
 // Auth provides a mock function with given fields: _a0, _a1
-func (_m *UserService) Auth(_a0 context.Context, _a1 *domain.UserAuthReq) error {
+func (_m *UserService) Auth(_a0 context.Context, _a1 *domain.UserAuthReq) (*domain.User, error) {
 	ret := _m.Called(_a0, _a1)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserAuthReq) error); ok {
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserAuthReq) (*domain.User, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserAuthReq) *domain.User); ok {
 		r0 = rf(_a0, _a1)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.UserAuthReq) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
 	}
 
-	return r0
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: _a0, _a1
+func (_m *UserService) GetById(_a0 context.Context, _a1 string) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.User, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // GetByWallet provides a mock function with given fields: _a0, _a1
@@ -54,6 +92,188 @@ func (_m *UserService) GetByWallet(_a0 context.Context, _a1 string) (*domain.Use
 	return r0, r1
 }
 
+// Guest provides a mock function with given fields: _a0, _a1
+func (_m *UserService) Guest(_a0 context.Context, _a1 string) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.User, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LinkWallet provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserService) LinkWallet(_a0 context.Context, _a1 string, _a2 *domain.UserAuthReq) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.UserAuthReq) (*domain.User, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.UserAuthReq) *domain.User); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.UserAuthReq) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Nonce provides a mock function with given fields: _a0, _a1
+func (_m *UserService) Nonce(_a0 context.Context, _a1 domain.Address) (*domain.Nonce, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Nonce
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Address) (*domain.Nonce, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Address) *domain.Nonce); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Nonce)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Address) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Profile provides a mock function with given fields: _a0, _a1
+func (_m *UserService) Profile(_a0 context.Context, _a1 string) (*domain.Profile, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.Profile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.Profile, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.Profile); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Profile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: _a0, _a1
+func (_m *UserService) Search(_a0 context.Context, _a1 *domain.UserSearchReq) (*domain.ProfilePage, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *domain.ProfilePage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserSearchReq) (*domain.ProfilePage, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.UserSearchReq) *domain.ProfilePage); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ProfilePage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.UserSearchReq) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnlinkWallet provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserService) UnlinkWallet(_a0 context.Context, _a1 string, _a2 string) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.User, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.User); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserService) Update(_a0 context.Context, _a1 string, _a2 *domain.UserUpdateReq) (*domain.User, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.UserUpdateReq) (*domain.User, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.UserUpdateReq) *domain.User); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.UserUpdateReq) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserService(t interface {
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/transport/rest/model"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockery --dir . --name SanctionService --output ./mocks
+type SanctionService interface {
+	Issue(context.Context, *domain.Principal, string, *domain.SanctionReq) (*domain.Sanction, error)
+	List(context.Context, string) ([]*domain.Sanction, error)
+	Lift(context.Context, *domain.Principal, string) error
+	Check(context.Context, string) error
+}
+
+// SanctionHandler is moderation API of staff, routes are guarded by PermissionMiddleware
+type SanctionHandler struct {
+	service SanctionService
+}
+
+func NewSanctionHandler(service SanctionService) *SanctionHandler {
+	return &SanctionHandler{service}
+}
+
+func (h *SanctionHandler) Issue(ctx echo.Context) error {
+	principal, err := authPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	restSanctionReq := new(model.SanctionReq)
+	err = ctx.Bind(restSanctionReq)
+	if err != nil {
+		return err
+	}
+
+	domainSanctionReq := &domain.SanctionReq{
+		Type:   domain.SanctionType(restSanctionReq.Type),
+		Reason: restSanctionReq.Reason,
+	}
+	if restSanctionReq.Duration != "" {
+		domainSanctionReq.Duration, err = time.ParseDuration(restSanctionReq.Duration)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid duration")
+		}
+	}
+
+	sanction, err := h.service.Issue(ctx.Request().Context(), principal, ctx.Param("id"), domainSanctionReq)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			return echo.NewHTTPError(http.StatusForbidden, "permission denied")
+		}
+		if errors.Is(err, domain.ErrSanction) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, restSanction(sanction, time.Now()))
+}
+
+// List returns all sanctions of user including expired and lifted ones
+func (h *SanctionHandler) List(ctx echo.Context) error {
+	sanctions, err := h.service.List(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	restSanctions := make([]*model.Sanction, 0, len(sanctions))
+	for _, sanction := range sanctions {
+		restSanctions = append(restSanctions, restSanction(sanction, now))
+	}
+
+	return ctx.JSON(http.StatusOK, restSanctions)
+}
+
+func (h *SanctionHandler) Lift(ctx echo.Context) error {
+	principal, err := authPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.service.Lift(ctx.Request().Context(), principal, ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "sanction not found")
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			return echo.NewHTTPError(http.StatusForbidden, "permission denied")
+		}
+		if errors.Is(err, domain.ErrSanction) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// SanctionMiddleware rejects access tokens of banned and suspended users, must follow JWTMiddleware
+func SanctionMiddleware(sanctionService SanctionService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, err := authClaims(ctx)
+			if err != nil {
+				return err
+			}
+
+			err = sanctionService.Check(ctx.Request().Context(), claims.Subject)
+			if err != nil {
+				return sanctionError(err)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// sanctionError maps SanctionError to response telling user why and until when access is blocked
+func sanctionError(err error) error {
+	sanctionErr := &domain.SanctionError{}
+	if !errors.As(err, &sanctionErr) {
+		return err
+	}
+
+	payload := &model.SanctionError{
+		Message: "account banned",
+		Type:    string(sanctionErr.Sanction.Type),
+		Reason:  sanctionErr.Sanction.Reason,
+	}
+	if !sanctionErr.Sanction.ExpiresAt.IsZero() {
+		payload.Message = "account suspended"
+		payload.ExpiresAt = &sanctionErr.Sanction.ExpiresAt
+	}
+	return echo.NewHTTPError(http.StatusForbidden, payload)
+}
+
+func restSanction(sanction *domain.Sanction, now time.Time) *model.Sanction {
+	restSanction := &model.Sanction{
+		ID:        sanction.ID,
+		UserID:    sanction.UserID,
+		Type:      string(sanction.Type),
+		Reason:    sanction.Reason,
+		IssuedBy:  sanction.IssuedBy,
+		CreatedAt: sanction.CreatedAt,
+		LiftedBy:  sanction.LiftedBy,
+		Active:    sanction.Active(now),
+	}
+	if !sanction.ExpiresAt.IsZero() {
+		restSanction.ExpiresAt = &sanction.ExpiresAt
+	}
+	if !sanction.LiftedAt.IsZero() {
+		restSanction.LiftedAt = &sanction.LiftedAt
+	}
+	return restSanction
+}
@@ -2,14 +2,10 @@ package handler
 
 import (
 	"context"
-	"fmt"
 	"net/http"
-	"server/internal/config"
 	"server/internal/domain"
 	"server/internal/transport/rest/model"
-	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 )
@@ -18,95 +14,529 @@ var (
 	userErrorPrefix = "[transport.rest.handler.user]"
 )
 
-type jwtCustomClaims struct {
-	Wallet string `json:"wallet"`
-	jwt.RegisteredClaims
-}
-
 //go:generate mockery --dir . --name UserService --output ./mocks
 type UserService interface {
-	Auth(context.Context, *domain.UserAuthReq) error
+	Nonce(context.Context, domain.Address) (*domain.Nonce, error)
+	Auth(context.Context, *domain.UserAuthReq) (*domain.User, error)
+	Guest(context.Context, string) (*domain.User, error)
+	LinkWallet(context.Context, string, *domain.UserAuthReq) (*domain.User, error)
+	UnlinkWallet(context.Context, string, string) (*domain.User, error)
+	Update(context.Context, string, *domain.UserUpdateReq) (*domain.User, error)
+	GetById(context.Context, string) (*domain.User, error)
+	Profile(context.Context, string) (*domain.Profile, error)
+	Search(context.Context, *domain.UserSearchReq) (*domain.ProfilePage, error)
 	GetByWallet(context.Context, string) (*domain.User, error)
 }
 
+//go:generate mockery --dir . --name TokenService --output ./mocks
+type TokenService interface {
+	Issue(context.Context, *domain.User, *domain.Device) (*domain.Session, string, error)
+	Refresh(context.Context, string, *domain.Device) (*domain.RefreshToken, string, error)
+	Logout(context.Context, *domain.AccessToken, string) error
+	LogoutAll(context.Context, string) error
+	IsRevoked(context.Context, *domain.AccessToken) (bool, error)
+	Sessions(context.Context, string) ([]*domain.Session, error)
+	DeleteSession(context.Context, string, string) error
+}
+
 type UserHandler struct {
-	service UserService
+	service         UserService
+	tokenService    TokenService
+	keyService      KeyService
+	sanctionService SanctionService
 }
 
-func NewUserHandler(service UserService) *UserHandler {
-	return &UserHandler{service}
+func NewUserHandler(service UserService, tokenService TokenService, keyService KeyService, sanctionService SanctionService) *UserHandler {
+	return &UserHandler{service, tokenService, keyService, sanctionService}
 }
 
-func (h *UserHandler) Auth(ctx echo.Context) error {
-	cfg := config.Get()
+func (h *UserHandler) Nonce(ctx echo.Context) error {
+	restUserNonceReq := new(model.UserNonceReq)
+	err := ctx.Bind(restUserNonceReq)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := requestAddress(restUserNonceReq.Chain, restUserNonceReq.Wallet)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := h.service.Nonce(ctx.Request().Context(), wallet)
+	if err != nil {
+		if errors.Is(err, domain.ErrNonce) || errors.Is(err, domain.ErrAddress) {
+			return echo.NewHTTPError(http.StatusBadRequest, errors.Cause(err).Error())
+		}
+		return err
+	}
+
+	restUserNonceRes := &model.UserNonceRes{
+		Nonce:     nonce.Value,
+		ExpiresAt: nonce.ExpiresAt,
+	}
 
+	return ctx.JSON(http.StatusOK, restUserNonceRes)
+}
+
+func (h *UserHandler) Auth(ctx echo.Context) error {
 	restUserAuthReq := new(model.UserAuthReq)
 	err := ctx.Bind(restUserAuthReq)
 	if err != nil {
 		return err
 	}
 
+	wallet, err := requestAddress(restUserAuthReq.Chain, restUserAuthReq.Wallet)
+	if err != nil {
+		return err
+	}
+
 	domainUserAuthReq := &domain.UserAuthReq{
-		Wallet:  restUserAuthReq.Wallet,
-		Sign:    restUserAuthReq.Sign,
-		Message: restUserAuthReq.Message,
+		Chain:     restUserAuthReq.Chain,
+		Wallet:    wallet,
+		Sign:      restUserAuthReq.Sign,
+		Message:   restUserAuthReq.Message,
+		TypedData: restUserAuthReq.TypedData,
 	}
 
-	err = h.service.Auth(ctx.Request().Context(), domainUserAuthReq)
+	user, err := h.service.Auth(ctx.Request().Context(), domainUserAuthReq)
+	if err != nil {
+		return authError(err)
+	}
+
+	return h.login(ctx, user)
+}
+
+// Guest logs device in as guest, guest is created on first login from device
+func (h *UserHandler) Guest(ctx echo.Context) error {
+	restUserGuestReq := new(model.UserGuestReq)
+	err := ctx.Bind(restUserGuestReq)
 	if err != nil {
 		return err
 	}
 
-	claims := &jwtCustomClaims{
-		restUserAuthReq.Wallet,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 168)),
-		},
+	user, err := h.service.Guest(ctx.Request().Context(), restUserGuestReq.DeviceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrGuest) {
+			return echo.NewHTTPError(http.StatusBadRequest, errors.Cause(err).Error())
+		}
+		return sanctionError(err)
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return h.login(ctx, user)
+}
+
+// Upgrade turns guest into user of wallet signed the same way as on login.
+// Guest keeps session, so only access token with new role is returned.
+func (h *UserHandler) Upgrade(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
 
-	// Generate encoded token
-	tokenSign, err := token.SignedString([]byte(cfg.JWTSecret))
+	restUserAuthReq := new(model.UserAuthReq)
+	err = ctx.Bind(restUserAuthReq)
 	if err != nil {
 		return err
 	}
 
-	// TODO: Implement refresh strategy
+	wallet, err := requestAddress(restUserAuthReq.Chain, restUserAuthReq.Wallet)
+	if err != nil {
+		return err
+	}
+
+	domainUserAuthReq := &domain.UserAuthReq{
+		Chain:     restUserAuthReq.Chain,
+		Wallet:    wallet,
+		Sign:      restUserAuthReq.Sign,
+		Message:   restUserAuthReq.Message,
+		TypedData: restUserAuthReq.TypedData,
+		GuestID:   claims.Subject,
+	}
+
+	user, err := h.service.Auth(ctx.Request().Context(), domainUserAuthReq)
+	if err != nil {
+		if errors.Is(err, domain.ErrWallet) || errors.Is(err, domain.ErrGuest) {
+			return echo.NewHTTPError(http.StatusConflict, errors.Cause(err).Error())
+		}
+		return authError(err)
+	}
+
+	authToken, err := signAuthToken(h.keyService, user, claims.SessionID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, &model.UserAuthRes{AuthToken: authToken})
+}
+
+// login starts new session of user device and returns its tokens
+func (h *UserHandler) login(ctx echo.Context, user *domain.User) error {
+	session, refreshToken, err := h.tokenService.Issue(ctx.Request().Context(), user, requestDevice(ctx))
+	if err != nil {
+		return err
+	}
+
+	authToken, err := signAuthToken(h.keyService, user, session.ID)
+	if err != nil {
+		return err
+	}
 
 	restUserAuthRes := &model.UserAuthRes{
-		AuthToken:    tokenSign,
-		RefreshToken: "",
+		AuthToken:    authToken,
+		RefreshToken: refreshToken,
 	}
 
 	return ctx.JSON(http.StatusOK, restUserAuthRes)
 }
 
-func (h *UserHandler) Me(ctx echo.Context) error {
-	auth, ok := ctx.Get("user").(*jwt.Token)
-	if !ok {
-		return fmt.Errorf("%s: auth header error", userErrorPrefix)
+func (h *UserHandler) Refresh(ctx echo.Context) error {
+	restUserRefreshReq := new(model.UserRefreshReq)
+	err := ctx.Bind(restUserRefreshReq)
+	if err != nil {
+		return err
+	}
+
+	token, refreshToken, err := h.tokenService.Refresh(ctx.Request().Context(), restUserRefreshReq.RefreshToken, requestDevice(ctx))
+	if err != nil {
+		if errors.Is(err, domain.ErrToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+		}
+		return err
+	}
+
+	// role and primary wallet may have changed since login
+	user, err := h.service.GetById(ctx.Request().Context(), token.UserID)
+	if err != nil {
+		return err
+	}
+	err = h.sanctionService.Check(ctx.Request().Context(), user.ID)
+	if err != nil {
+		return sanctionError(err)
+	}
+
+	authToken, err := signAuthToken(h.keyService, user, token.Family)
+	if err != nil {
+		return err
+	}
+
+	restUserAuthRes := &model.UserAuthRes{
+		AuthToken:    authToken,
+		RefreshToken: refreshToken,
+	}
+
+	return ctx.JSON(http.StatusOK, restUserAuthRes)
+}
+
+func (h *UserHandler) Logout(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
 	}
 
-	if !auth.Valid {
-		return fmt.Errorf("%s: auth invalid", userErrorPrefix)
+	restUserLogoutReq := new(model.UserLogoutReq)
+	err = ctx.Bind(restUserLogoutReq)
+	if err != nil {
+		return err
 	}
 
-	claims, ok := auth.Claims.(jwt.MapClaims)
-	if !ok {
-		return fmt.Errorf("%s: auth claims error", userErrorPrefix)
+	err = h.tokenService.Logout(ctx.Request().Context(), claims.accessToken(), restUserLogoutReq.RefreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+		}
+		return err
 	}
 
-	wallet, ok := claims["wallet"].(string)
-	if !ok {
-		return fmt.Errorf("%s: auth claim wallet error", userErrorPrefix)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *UserHandler) LogoutAll(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
 	}
 
-	user, err := h.service.GetByWallet(ctx.Request().Context(), wallet)
+	err = h.tokenService.LogoutAll(ctx.Request().Context(), claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *UserHandler) Me(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	// primary wallet in claims may be unlinked already, so user is found by id
+	user, err := h.service.GetById(ctx.Request().Context(), claims.Subject)
 	if err != nil {
 		return errors.Wrapf(err, "%s: user not found", userErrorPrefix)
 	}
 
-	return ctx.JSON(http.StatusOK, user)
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+// Profile returns public profile of any user
+func (h *UserHandler) Profile(ctx echo.Context) error {
+	profile, err := h.service.Profile(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, restPublicUser(profile))
+}
+
+// Search finds players by nickname prefix or wallet, page is continued with cursor of previous one
+func (h *UserHandler) Search(ctx echo.Context) error {
+	domainUserSearchReq := &domain.UserSearchReq{
+		Query:  ctx.QueryParam("q"),
+		Cursor: ctx.QueryParam("cursor"),
+	}
+
+	page, err := h.service.Search(ctx.Request().Context(), domainUserSearchReq)
+	if err != nil {
+		if errors.Is(err, domain.ErrCursor) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor")
+		}
+		return err
+	}
+
+	restPage := &model.PublicUserPage{
+		Users:      make([]*model.PublicUser, 0, len(page.Profiles)),
+		NextCursor: page.NextCursor,
+	}
+	for _, profile := range page.Profiles {
+		restPage.Users = append(restPage.Users, restPublicUser(profile))
+	}
+
+	return ctx.JSON(http.StatusOK, restPage)
+}
+
+func (h *UserHandler) Update(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	restUserUpdateReq := new(model.UserUpdateReq)
+	err = ctx.Bind(restUserUpdateReq)
+	if err != nil {
+		return err
+	}
+
+	domainUserUpdateReq := &domain.UserUpdateReq{
+		Nickname: restUserUpdateReq.Nickname,
+		Bio:      restUserUpdateReq.Bio,
+		Country:  restUserUpdateReq.Country,
+	}
+	if restUserUpdateReq.Avatar != nil {
+		domainUserUpdateReq.Avatar = &domain.Avatar{
+			URL: restUserUpdateReq.Avatar.URL,
+			NFT: restUserUpdateReq.Avatar.NFT,
+		}
+	}
+	if restUserUpdateReq.Privacy != nil {
+		domainUserUpdateReq.Privacy = &domain.Privacy{
+			Private:     restUserUpdateReq.Privacy.Private,
+			HideCountry: restUserUpdateReq.Privacy.HideCountry,
+			ShowWallet:  restUserUpdateReq.Privacy.ShowWallet,
+		}
+	}
+
+	user, err := h.service.Update(ctx.Request().Context(), claims.Subject, domainUserUpdateReq)
+	if err != nil {
+		if errors.Is(err, domain.ErrNicknameTaken) {
+			return echo.NewHTTPError(http.StatusConflict, "nickname taken")
+		}
+		if errors.Is(err, domain.ErrNickname) || errors.Is(err, domain.ErrProfile) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+func (h *UserHandler) LinkWallet(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	restUserLinkReq := new(model.UserAuthReq)
+	err = ctx.Bind(restUserLinkReq)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := requestAddress(restUserLinkReq.Chain, restUserLinkReq.Wallet)
+	if err != nil {
+		return err
+	}
+
+	domainUserLinkReq := &domain.UserAuthReq{
+		Chain:     restUserLinkReq.Chain,
+		Wallet:    wallet,
+		Sign:      restUserLinkReq.Sign,
+		Message:   restUserLinkReq.Message,
+		TypedData: restUserLinkReq.TypedData,
+	}
+
+	user, err := h.service.LinkWallet(ctx.Request().Context(), claims.Subject, domainUserLinkReq)
+	if err != nil {
+		if errors.Is(err, domain.ErrWallet) {
+			return echo.NewHTTPError(http.StatusConflict, errors.Cause(err).Error())
+		}
+		return authError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+func (h *UserHandler) UnlinkWallet(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	account, err := domain.NormalizeAccount(ctx.Param("wallet"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, err := h.service.UnlinkWallet(ctx.Request().Context(), claims.Subject, account)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "wallet not linked")
+		}
+		if errors.Is(err, domain.ErrWallet) {
+			return echo.NewHTTPError(http.StatusConflict, errors.Cause(err).Error())
+		}
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, restUser(user))
+}
+
+func (h *UserHandler) Sessions(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := h.tokenService.Sessions(ctx.Request().Context(), claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	restSessions := make([]*model.Session, 0, len(sessions))
+	for _, session := range sessions {
+		restSessions = append(restSessions, &model.Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == claims.SessionID,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, restSessions)
+}
+
+func (h *UserHandler) DeleteSession(ctx echo.Context) error {
+	claims, err := authClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = h.tokenService.DeleteSession(ctx.Request().Context(), claims.Subject, ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "session not found")
+		}
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func restUser(user *domain.User) *model.User {
+	restUser := &model.User{
+		ID:        user.ID,
+		Nickname:  user.Nickname,
+		Wallet:    user.Wallet,
+		Wallets:   user.Wallets,
+		CreatedAt: user.CreatedAt,
+		Role:      string(user.Role),
+		Avatar:    model.Avatar{URL: user.Avatar.URL, NFT: user.Avatar.NFT},
+		Bio:       user.Bio,
+		Country:   user.Country,
+		Privacy: model.Privacy{
+			Private:     user.Privacy.Private,
+			HideCountry: user.Privacy.HideCountry,
+			ShowWallet:  user.Privacy.ShowWallet,
+		},
+	}
+	if !user.DeleteRequestedAt.IsZero() {
+		restUser.DeleteRequestedAt = &user.DeleteRequestedAt
+	}
+	return restUser
+}
+
+// restPublicUser builds public profile response, it has no fields besides profile ones
+// so private user data can't leak into it
+func restPublicUser(profile *domain.Profile) *model.PublicUser {
+	restProfile := &model.PublicUser{
+		ID:       profile.ID,
+		Nickname: profile.Nickname,
+		Avatar:   model.Avatar{URL: profile.Avatar.URL, NFT: profile.Avatar.NFT},
+		Bio:      profile.Bio,
+		Country:  profile.Country,
+		Wallet:   profile.Wallet,
+	}
+	if !profile.CreatedAt.IsZero() {
+		restProfile.CreatedAt = &profile.CreatedAt
+	}
+	return restProfile
+}
+
+// authError maps failed proof of wallet ownership and sanctions to response, other errors pass
+func authError(err error) error {
+	if errors.Is(err, domain.ErrNonce) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired nonce")
+	}
+	if errors.Is(err, domain.ErrSignature) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid signature")
+	}
+	if errors.Is(err, domain.ErrAddress) {
+		return echo.NewHTTPError(http.StatusBadRequest, errors.Cause(err).Error())
+	}
+	if errors.Is(err, domain.ErrDeleted) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "account deleted")
+	}
+	return sanctionError(err)
+}
+
+// requestAddress normalizes wallet address sent by client, so it's stored and looked up in canonical form
+func requestAddress(chain, wallet string) (domain.Address, error) {
+	address, err := domain.NewAddress(chain, wallet)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return address, nil
+}
+
+// requestDevice describes client of request
+func requestDevice(ctx echo.Context) *domain.Device {
+	return &domain.Device{
+		UserAgent: ctx.Request().UserAgent(),
+		IP:        ctx.RealIP(),
+	}
 }
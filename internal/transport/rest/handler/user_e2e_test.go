@@ -7,15 +7,23 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"server/internal/config"
-	"server/internal/repository/db/mongodb"
+	"os"
+	"server/internal/domain"
+	"server/internal/repository"
+	"server/internal/repository/db"
 	"server/internal/service"
 	"server/internal/transport/rest/handler"
+	"server/internal/transport/rest/model"
+	"server/pkg/jwk"
+	"server/pkg/nickname"
+	"server/pkg/sign"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 
 	"github.com/stretchr/testify/assert"
@@ -25,9 +33,24 @@ import (
 
 type UserTestSuite struct {
 	suite.Suite
-	userService *service.UserService
-	userHandler *handler.UserHandler
-	authToken   string
+	userService     *service.UserService
+	tokenService    *service.TokenService
+	sanctionService *service.SanctionService
+	keyService      *service.KeyService
+	adminService    *service.AdminService
+	userHandler     *handler.UserHandler
+	adminHandler    *handler.AdminHandler
+	accountHandler  *handler.AccountHandler
+	authToken       string
+	refreshToken    string
+}
+
+type UserNonceReq struct {
+	Wallet string `json:"wallet"`
+}
+
+type UserNonceRes struct {
+	Nonce string `json:"nonce"`
 }
 
 type UserAuthReq struct {
@@ -36,25 +59,111 @@ type UserAuthReq struct {
 	Sign    string `json:"sign"`
 }
 
+type UserRefreshReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type UserAuthRes struct {
 	AuthToken    string `json:"auth_token"`
 	RefreshToken string `json:"refresh_token"`
 }
 
+type User struct {
+	ID       string   `json:"id"`
+	Nickname string   `json:"nickname"`
+	Wallet   string   `json:"wallet"`
+	Wallets  []string `json:"wallets"`
+}
+
+type Session struct {
+	ID      string `json:"id"`
+	Current bool   `json:"current"`
+}
+
+var testSiweConfig = sign.SiweConfig{
+	Chain:   sign.ChainEthereum,
+	Domain:  "game.example.com",
+	URI:     "https://game.example.com",
+	ChainID: "1",
+}
+
+func testSiweMessage(wallet, nonce string) string {
+	message := &sign.SiweMessage{
+		Domain:   testSiweConfig.Domain,
+		Chain:    testSiweConfig.Chain,
+		Address:  wallet,
+		URI:      testSiweConfig.URI,
+		Version:  "1",
+		ChainID:  testSiweConfig.ChainID,
+		Nonce:    nonce,
+		IssuedAt: time.Now(),
+	}
+	return message.String()
+}
+
 func NewUserTestSuite() (*UserTestSuite, error) {
 	suite := &UserTestSuite{}
 
 	ctx := context.Background()
 
-	db, err := mongodb.Connect(ctx)
+	// storage is selected in config, suite runs without database unless STORAGE picks one
+	if os.Getenv("STORAGE") == "" {
+		err := os.Setenv("STORAGE", repository.StorageMemory)
+		if err != nil {
+			return nil, err
+		}
+	}
+	repos, err := db.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userRepo := repos.User
+	nonceRepo := repos.Nonce
+	refreshTokenRepo := repos.RefreshToken
+	revocationRepo := repos.Revocation
+	signingKeyRepo := repos.SigningKey
+	sessionRepo := repos.Session
+	sanctionRepo := repos.Sanction
+	roleChangeRepo := repos.RoleChange
+	apiKeyRepo := repos.APIKey
+
+	nicknameRules := nickname.Rules{
+		MinLength: 3,
+		MaxLength: 20,
+		Profanity: nickname.Profanity,
+	}
+	suite.userService = service.NewUserService(userRepo, nonceRepo, sanctionRepo, nil, nickname.NewGenerator(nicknameRules), service.UserServiceConfig{
+		NonceTTL:            time.Minute,
+		Siwe:                testSiweConfig,
+		EVMAccountReference: testSiweConfig.ChainID,
+		SignMode:            sign.ModePersonal,
+		Nickname:            nicknameRules,
+		NicknameCooldown:    time.Hour,
+		BioMaxLength:        160,
+		SearchPageSize:      20,
+	})
+	suite.sanctionService = service.NewSanctionService(sanctionRepo, userRepo)
+	suite.tokenService = service.NewTokenService(refreshTokenRepo, revocationRepo, sessionRepo, time.Hour)
+	suite.keyService = service.NewKeyService(signingKeyRepo, service.KeyServiceConfig{
+		Algorithm:      jwk.AlgorithmEdDSA,
+		RotationPeriod: time.Hour,
+		AccessTokenTTL: time.Hour,
+	})
+	err = suite.keyService.Rotate(ctx)
 	if err != nil {
 		return nil, err
 	}
+	suite.userHandler = handler.NewUserHandler(suite.userService, suite.tokenService, suite.keyService, suite.sanctionService)
 
-	userRepo := mongodb.NewUserRepo(db)
+	suite.adminService = service.NewAdminService(userRepo, roleChangeRepo, suite.tokenService, nickname.NewGenerator(nicknameRules))
+	suite.adminHandler = handler.NewAdminHandler(suite.adminService, suite.tokenService)
 
-	suite.userService = service.NewUserService(userRepo)
-	suite.userHandler = handler.NewUserHandler(suite.userService)
+	accountService := service.NewAccountService(userRepo, sessionRepo, refreshTokenRepo, revocationRepo, roleChangeRepo, sanctionRepo, apiKeyRepo, service.AccountServiceConfig{
+		DeletionGracePeriod: time.Hour,
+		PurgeInterval:       time.Hour,
+	})
+	suite.accountHandler = handler.NewAccountHandler(accountService, suite.tokenService)
 
 	return suite, nil
 }
@@ -74,11 +183,12 @@ func (suite *UserTestSuite) TestAuth_Success() {
 	require.True(suite.T(), ok)
 
 	address := crypto.PubkeyToAddress(*publicKeyECDSA).Hex()
-	messageString := "test"
-	messageBytes := []byte(messageString)
-	messageHash := crypto.Keccak256Hash(messageBytes)
+	nonce := suite.requestNonce(address)
+	messageString := testSiweMessage(address, nonce)
+	messageHash, err := sign.HashMessage(sign.ModePersonal, messageString)
+	require.NoError(suite.T(), err)
 
-	signature, err := crypto.Sign(messageHash.Bytes(), privateKey)
+	signature, err := crypto.Sign(messageHash, privateKey)
 	require.NoError(suite.T(), err)
 
 	userAuthReq := UserAuthReq{
@@ -106,11 +216,49 @@ func (suite *UserTestSuite) TestAuth_Success() {
 		json.Unmarshal(rec.Body.Bytes(), &authRes)
 
 		assert.NotEmpty(suite.T(), authRes.AuthToken)
+		assert.NotEmpty(suite.T(), authRes.RefreshToken)
 
 		suite.authToken = authRes.AuthToken
+		suite.refreshToken = authRes.RefreshToken
 	}
 }
 
+func (suite *UserTestSuite) TestAuth_Replay() {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	nonce := suite.requestNonce(address)
+	messageString := testSiweMessage(address, nonce)
+	messageHash, err := sign.HashMessage(sign.ModePersonal, messageString)
+	require.NoError(suite.T(), err)
+
+	signature, err := crypto.Sign(messageHash, privateKey)
+	require.NoError(suite.T(), err)
+
+	reqBody, err := json.Marshal(UserAuthReq{
+		Wallet:  address,
+		Message: messageString,
+		Sign:    hexutil.Encode(signature),
+	})
+	require.NoError(suite.T(), err)
+
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodPost, "/auth", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	err = suite.userHandler.Auth(e.NewContext(req, httptest.NewRecorder()))
+	require.NoError(suite.T(), err)
+
+	// same signed message must not be accepted twice
+	req = httptest.NewRequest(http.MethodPost, "/auth", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	err = suite.userHandler.Auth(e.NewContext(req, httptest.NewRecorder()))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+}
+
 func (suite *UserTestSuite) TestAuth_Fail() {
 	userAuthReq := UserAuthReq{
 		Wallet:  "0x543A7060C8bB455294319b23D825478B2b798c0E",
@@ -130,7 +278,91 @@ func (suite *UserTestSuite) TestAuth_Fail() {
 
 	err = suite.userHandler.Auth(c)
 
-	assert.Error(suite.T(), err)
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+}
+
+func (suite *UserTestSuite) TestAuth_WrongKey() {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+
+	// message of one wallet signed by key of another
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	messageString := testSiweMessage(address, suite.requestNonce(address))
+	messageHash, err := sign.HashMessage(sign.ModePersonal, messageString)
+	require.NoError(suite.T(), err)
+	signature, err := crypto.Sign(messageHash, otherKey)
+	require.NoError(suite.T(), err)
+
+	reqBody, err := json.Marshal(UserAuthReq{Wallet: address, Message: messageString, Sign: hexutil.Encode(signature)})
+	require.NoError(suite.T(), err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	err = suite.userHandler.Auth(echo.New().NewContext(req, httptest.NewRecorder()))
+
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+}
+
+func (suite *UserTestSuite) TestAuth_InvalidWallet() {
+	for _, handle := range []echo.HandlerFunc{suite.userHandler.Nonce, suite.userHandler.Auth} {
+		req := httptest.NewRequest(http.MethodPost, "/auth", strings.NewReader(`{"wallet":"0x123"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		err := handle(echo.New().NewContext(req, httptest.NewRecorder()))
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(suite.T(), err, &httpErr)
+		assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	}
+}
+
+func (suite *UserTestSuite) TestRefresh_Rotation() {
+	e := echo.New()
+
+	refresh := func(refreshToken string) (*UserAuthRes, error) {
+		reqBody, err := json.Marshal(UserRefreshReq{RefreshToken: refreshToken})
+		require.NoError(suite.T(), err)
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		err = suite.userHandler.Refresh(e.NewContext(req, rec))
+		if err != nil {
+			return nil, err
+		}
+
+		authRes := &UserAuthRes{}
+		err = json.Unmarshal(rec.Body.Bytes(), authRes)
+		require.NoError(suite.T(), err)
+		return authRes, nil
+	}
+
+	require.NotEmpty(suite.T(), suite.refreshToken)
+
+	rotated, err := refresh(suite.refreshToken)
+	require.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), rotated.AuthToken)
+	assert.NotEqual(suite.T(), suite.refreshToken, rotated.RefreshToken)
+
+	// reuse of rotated token revokes the whole family
+	_, err = refresh(suite.refreshToken)
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+
+	_, err = refresh(rotated.RefreshToken)
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+
+	_, err = refresh("unknown")
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
 }
 
 func (suite *UserTestSuite) TestMe_Success() {
@@ -142,10 +374,523 @@ func (suite *UserTestSuite) TestMe_Success() {
 
 	c := e.NewContext(req, rec)
 
-	cfg := config.Get()
-	err := echojwt.WithConfig(echojwt.Config{
-		SigningKey: []byte(cfg.JWTSecret),
-	})(suite.userHandler.Me)(c)
+	err := handler.JWTMiddleware(suite.keyService)(suite.userHandler.Me)(c)
 
 	assert.NoError(suite.T(), err)
 }
+
+func (suite *UserTestSuite) TestLogout_Success() {
+	e := echo.New()
+
+	protected := func(authToken string, h echo.HandlerFunc) error {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, `Bearer `+authToken)
+		rec := httptest.NewRecorder()
+
+		return handler.JWTMiddleware(suite.keyService)(
+			handler.RevocationMiddleware(suite.tokenService)(h),
+		)(e.NewContext(req, rec))
+	}
+
+	authRes := suite.authenticate()
+
+	err := protected(authRes.AuthToken, suite.userHandler.Me)
+	require.NoError(suite.T(), err)
+
+	err = protected(authRes.AuthToken, suite.userHandler.Logout)
+	require.NoError(suite.T(), err)
+
+	// token can't be used after logout
+	err = protected(authRes.AuthToken, suite.userHandler.Me)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *UserTestSuite) TestLogoutAll_Relogin() {
+	e := echo.New()
+
+	protected := func(authToken string, h echo.HandlerFunc) error {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, `Bearer `+authToken)
+
+		return handler.JWTMiddleware(suite.keyService)(
+			handler.RevocationMiddleware(suite.tokenService)(h),
+		)(e.NewContext(req, httptest.NewRecorder()))
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+	authRes := suite.login(privateKey)
+
+	// issue time of token has milliseconds precision
+	time.Sleep(2 * time.Millisecond)
+	err = protected(authRes.AuthToken, suite.userHandler.LogoutAll)
+	require.NoError(suite.T(), err)
+
+	// login right after logging out everywhere, likely in the same second, isn't revoked
+	relogin := suite.login(privateKey)
+	err = protected(relogin.AuthToken, suite.userHandler.Me)
+	assert.NoError(suite.T(), err)
+
+	var httpErr *echo.HTTPError
+	err = protected(authRes.AuthToken, suite.userHandler.Me)
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+}
+
+func (suite *UserTestSuite) TestLogout_ForeignRefreshToken() {
+	authRes := suite.authenticate()
+	other := suite.authenticate()
+
+	reqBody, err := json.Marshal(UserRefreshReq{RefreshToken: other.RefreshToken})
+	require.NoError(suite.T(), err)
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.Logout)(echo.New().NewContext(req, httptest.NewRecorder()))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+}
+
+func (suite *UserTestSuite) TestSessions_Delete() {
+	e := echo.New()
+
+	authRes := suite.authenticate()
+
+	req := httptest.NewRequest(http.MethodGet, "/user/sessions", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec := httptest.NewRecorder()
+
+	err := handler.JWTMiddleware(suite.keyService)(suite.userHandler.Sessions)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	sessions := []Session{}
+	err = json.Unmarshal(rec.Body.Bytes(), &sessions)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), sessions, 1)
+	assert.True(suite.T(), sessions[0].Current)
+
+	req = httptest.NewRequest(http.MethodDelete, "/user/sessions/"+sessions[0].ID, nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("id")
+	c.SetParamValues(sessions[0].ID)
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.DeleteSession)(c)
+	require.NoError(suite.T(), err)
+
+	// refresh token of deleted session is revoked
+	reqBody, err := json.Marshal(UserRefreshReq{RefreshToken: authRes.RefreshToken})
+	require.NoError(suite.T(), err)
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	err = suite.userHandler.Refresh(e.NewContext(req, httptest.NewRecorder()))
+	assert.Error(suite.T(), err)
+
+	// access token of deleted session is revoked too
+	req = httptest.NewRequest(http.MethodGet, "/user/me", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+
+	err = handler.JWTMiddleware(suite.keyService)(
+		handler.RevocationMiddleware(suite.tokenService)(suite.userHandler.Me),
+	)(e.NewContext(req, httptest.NewRecorder()))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+}
+
+func (suite *UserTestSuite) TestLinkWallet_Success() {
+	e := echo.New()
+
+	authRes := suite.authenticate()
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+	wallet := sign.FormatAccount(sign.NamespaceEIP155, testSiweConfig.ChainID, crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+
+	reqBody, err := json.Marshal(suite.signLogin(privateKey))
+	require.NoError(suite.T(), err)
+
+	req := httptest.NewRequest(http.MethodPost, "/user/wallets", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec := httptest.NewRecorder()
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.LinkWallet)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	user := User{}
+	err = json.Unmarshal(rec.Body.Bytes(), &user)
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), user.Wallets, wallet)
+
+	// linked wallet logs in to the same user
+	linkedUser, err := suite.userService.GetByWallet(context.Background(), wallet)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), user.ID, linkedUser.ID)
+
+	// primary wallet can be unlinked while linked one is left
+	req = httptest.NewRequest(http.MethodDelete, "/user/wallets/"+user.Wallet, nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("wallet")
+	c.SetParamValues(user.Wallet)
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.UnlinkWallet)(c)
+	require.NoError(suite.T(), err)
+
+	err = json.Unmarshal(rec.Body.Bytes(), &user)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), wallet, user.Wallet)
+
+	// last wallet can't be unlinked
+	req = httptest.NewRequest(http.MethodDelete, "/user/wallets/"+wallet, nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	c = e.NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("wallet")
+	c.SetParamValues(wallet)
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.UnlinkWallet)(c)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *UserTestSuite) TestUpdate_Nickname() {
+	e := echo.New()
+
+	authRes := suite.authenticate()
+
+	newNickname := "Player" + strconv.FormatInt(time.Now().UnixNano()%1000000, 10)
+
+	req := httptest.NewRequest(http.MethodPatch, "/user", strings.NewReader(`{"nickname":"`+newNickname+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec := httptest.NewRecorder()
+
+	err := handler.JWTMiddleware(suite.keyService)(suite.userHandler.Update)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	user := User{}
+	err = json.Unmarshal(rec.Body.Bytes(), &user)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), newNickname, user.Nickname)
+
+	// second rename is refused until cooldown passes
+	req = httptest.NewRequest(http.MethodPatch, "/user", strings.NewReader(`{"nickname":"Other`+newNickname+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.Update)(e.NewContext(req, httptest.NewRecorder()))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+}
+
+func (suite *UserTestSuite) TestProfile_Public() {
+	e := echo.New()
+
+	authRes := suite.authenticate()
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec := httptest.NewRecorder()
+
+	err := handler.JWTMiddleware(suite.keyService)(suite.userHandler.Me)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	user := User{}
+	err = json.Unmarshal(rec.Body.Bytes(), &user)
+	require.NoError(suite.T(), err)
+
+	req = httptest.NewRequest(http.MethodGet, "/users/"+user.ID, nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(user.ID)
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.Profile)(c)
+	require.NoError(suite.T(), err)
+
+	profile := map[string]any{}
+	err = json.Unmarshal(rec.Body.Bytes(), &profile)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), user.Nickname, profile["nickname"])
+	assert.NotContains(suite.T(), profile, "wallet")
+	assert.NotContains(suite.T(), profile, "wallets")
+}
+
+func (suite *UserTestSuite) TestSearch_Nickname() {
+	e := echo.New()
+
+	authRes := suite.authenticate()
+
+	newNickname := "Seeker" + strconv.FormatInt(time.Now().UnixNano()%1000000, 10)
+	req := httptest.NewRequest(http.MethodPatch, "/user", strings.NewReader(`{"nickname":"`+newNickname+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+
+	err := handler.JWTMiddleware(suite.keyService)(suite.userHandler.Update)(e.NewContext(req, httptest.NewRecorder()))
+	require.NoError(suite.T(), err)
+
+	// prefix search ignores case
+	req = httptest.NewRequest(http.MethodGet, "/users?q="+strings.ToLower(newNickname), nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec := httptest.NewRecorder()
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.Search)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	page := struct {
+		Users []User `json:"users"`
+	}{}
+	err = json.Unmarshal(rec.Body.Bytes(), &page)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), page.Users, 1)
+	assert.Equal(suite.T(), newNickname, page.Users[0].Nickname)
+}
+
+func (suite *UserTestSuite) TestSanction_Suspension() {
+	e := echo.New()
+
+	authRes := suite.authenticate()
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec := httptest.NewRecorder()
+
+	err := handler.JWTMiddleware(suite.keyService)(suite.userHandler.Me)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	user := User{}
+	err = json.Unmarshal(rec.Body.Bytes(), &user)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.sanctionService.Issue(context.Background(), &domain.Principal{Kind: domain.PrincipalUser, ID: "moderator", Role: domain.RoleModerator}, user.ID, &domain.SanctionReq{
+		Type:     domain.SanctionSuspension,
+		Reason:   "cheating",
+		Duration: time.Hour,
+	})
+	require.NoError(suite.T(), err)
+
+	// already issued token is rejected with expiry of suspension
+	req = httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+
+	err = handler.JWTMiddleware(suite.keyService)(handler.SanctionMiddleware(suite.sanctionService)(suite.userHandler.Me))(e.NewContext(req, httptest.NewRecorder()))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusForbidden, httpErr.Code)
+	payload, ok := httpErr.Message.(*model.SanctionError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), "cheating", payload.Reason)
+	assert.NotNil(suite.T(), payload.ExpiresAt)
+}
+
+func (suite *UserTestSuite) TestAccount_ExportDelete() {
+	e := echo.New()
+
+	authRes := suite.authenticate()
+
+	req := httptest.NewRequest(http.MethodGet, "/user/export", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec := httptest.NewRecorder()
+
+	err := handler.JWTMiddleware(suite.keyService)(suite.accountHandler.Export)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	export := struct {
+		User     User      `json:"user"`
+		Sessions []Session `json:"sessions"`
+	}{}
+	err = json.Unmarshal(rec.Body.Bytes(), &export)
+	require.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), export.User.ID)
+	require.Len(suite.T(), export.Sessions, 1)
+	assert.True(suite.T(), export.Sessions[0].Current)
+
+	req = httptest.NewRequest(http.MethodDelete, "/user", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authRes.AuthToken)
+	rec = httptest.NewRecorder()
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.accountHandler.Delete)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusAccepted, rec.Code)
+
+	// account pending deletion is hidden from other players
+	_, err = suite.userService.Profile(context.Background(), export.User.ID)
+	assert.ErrorIs(suite.T(), err, domain.ErrNotFound)
+}
+
+func (suite *UserTestSuite) TestGuest_Upgrade() {
+	e := echo.New()
+
+	deviceID := "device-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	reqBody, err := json.Marshal(model.UserGuestReq{DeviceID: deviceID})
+	require.NoError(suite.T(), err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/guest", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	err = suite.userHandler.Guest(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+	guestRes := UserAuthRes{}
+	err = json.Unmarshal(rec.Body.Bytes(), &guestRes)
+	require.NoError(suite.T(), err)
+
+	guest := suite.me(guestRes.AuthToken)
+	assert.Empty(suite.T(), guest.Wallet)
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+	reqBody, err = json.Marshal(suite.signLogin(privateKey))
+	require.NoError(suite.T(), err)
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/upgrade", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+guestRes.AuthToken)
+	rec = httptest.NewRecorder()
+
+	err = handler.JWTMiddleware(suite.keyService)(suite.userHandler.Upgrade)(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+	upgradeRes := UserAuthRes{}
+	err = json.Unmarshal(rec.Body.Bytes(), &upgradeRes)
+	require.NoError(suite.T(), err)
+
+	// guest keeps id and nickname
+	user := suite.me(upgradeRes.AuthToken)
+	assert.Equal(suite.T(), guest.ID, user.ID)
+	assert.Equal(suite.T(), guest.Nickname, user.Nickname)
+	wallet := sign.FormatAccount(sign.NamespaceEIP155, testSiweConfig.ChainID, crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	assert.Equal(suite.T(), wallet, user.Wallet)
+}
+
+// me returns user of access token
+func (suite *UserTestSuite) TestSetRole_Demoted() {
+	ctx := context.Background()
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+	user := suite.me(suite.login(privateKey).AuthToken)
+
+	_, err = suite.adminService.SetRole(ctx, domain.SystemPrincipal(), user.ID, domain.RoleAdmin)
+	require.NoError(suite.T(), err)
+
+	getUser := func(authToken string) error {
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/users/"+user.ID, nil)
+		req.Header.Set(echo.HeaderAuthorization, `Bearer `+authToken)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues(user.ID)
+
+		return handler.JWTMiddleware(suite.keyService)(
+			handler.RevocationMiddleware(suite.tokenService)(
+				handler.PermissionMiddleware(domain.PermissionUsersRead)(suite.adminHandler.GetUser),
+			),
+		)(c)
+	}
+
+	adminToken := suite.login(privateKey).AuthToken
+	require.NoError(suite.T(), getUser(adminToken))
+
+	// issue time of token has milliseconds precision
+	time.Sleep(2 * time.Millisecond)
+	_, err = suite.adminService.SetRole(ctx, domain.SystemPrincipal(), user.ID, domain.RolePlayer)
+	require.NoError(suite.T(), err)
+
+	// token issued to admin is revoked at once
+	var httpErr *echo.HTTPError
+	err = getUser(adminToken)
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code)
+
+	// token issued after demotion has new role
+	err = getUser(suite.login(privateKey).AuthToken)
+	require.ErrorAs(suite.T(), err, &httpErr)
+	assert.Equal(suite.T(), http.StatusForbidden, httpErr.Code)
+}
+
+func (suite *UserTestSuite) me(authToken string) User {
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	req.Header.Set(echo.HeaderAuthorization, `Bearer `+authToken)
+	rec := httptest.NewRecorder()
+
+	err := handler.JWTMiddleware(suite.keyService)(suite.userHandler.Me)(echo.New().NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	user := User{}
+	err = json.Unmarshal(rec.Body.Bytes(), &user)
+	require.NoError(suite.T(), err)
+	return user
+}
+
+func (suite *UserTestSuite) requestNonce(wallet string) string {
+	reqBody, err := json.Marshal(UserNonceReq{Wallet: wallet})
+	require.NoError(suite.T(), err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/nonce", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	c := e.NewContext(req, rec)
+
+	err = suite.userHandler.Nonce(c)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	nonceRes := UserNonceRes{}
+	err = json.Unmarshal(rec.Body.Bytes(), &nonceRes)
+	require.NoError(suite.T(), err)
+
+	return nonceRes.Nonce
+}
+
+// signLogin signs login message with wallet key for fresh nonce
+func (suite *UserTestSuite) signLogin(privateKey *ecdsa.PrivateKey) UserAuthReq {
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	nonce := suite.requestNonce(address)
+	messageString := testSiweMessage(address, nonce)
+	messageHash, err := sign.HashMessage(sign.ModePersonal, messageString)
+	require.NoError(suite.T(), err)
+
+	signature, err := crypto.Sign(messageHash, privateKey)
+	require.NoError(suite.T(), err)
+
+	return UserAuthReq{
+		Wallet:  address,
+		Message: messageString,
+		Sign:    hexutil.Encode(signature),
+	}
+}
+
+func (suite *UserTestSuite) authenticate() UserAuthRes {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(suite.T(), err)
+
+	return suite.login(privateKey)
+}
+
+// login signs in with wallet key
+func (suite *UserTestSuite) login(privateKey *ecdsa.PrivateKey) UserAuthRes {
+	reqBody, err := json.Marshal(suite.signLogin(privateKey))
+	require.NoError(suite.T(), err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth", bytes.NewBuffer(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	err = suite.userHandler.Auth(e.NewContext(req, rec))
+	require.NoError(suite.T(), err)
+
+	authRes := UserAuthRes{}
+	err = json.Unmarshal(rec.Body.Bytes(), &authRes)
+	require.NoError(suite.T(), err)
+
+	return authRes
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type UserDeletion struct {
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// UserExport is personal data archive of user
+type UserExport struct {
+	User          *User           `json:"user"`
+	Sessions      []*Session      `json:"sessions"`
+	RefreshTokens []*RefreshToken `json:"refresh_tokens"`
+	RoleChanges   []*RoleChange   `json:"role_changes"`
+	Sanctions     []*Sanction     `json:"sanctions"`
+	APIKeys       []*APIKey       `json:"api_keys"`
+	ExportedAt    time.Time       `json:"exported_at"`
+}
+
+// RefreshToken is record of issued refresh token, token hash is left out
+type RefreshToken struct {
+	SessionID string    `json:"session_id"`
+	Wallet    string    `json:"wallet"`
+	Used      bool      `json:"used"`
+	Revoked   bool      `json:"revoked"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
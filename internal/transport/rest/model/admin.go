@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type AdminRoleReq struct {
+	Role string `json:"role"`
+}
+
+type RoleChange struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	Role         string    `json:"role"`
+	PreviousRole string    `json:"previous_role"`
+	ChangedBy    string    `json:"changed_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
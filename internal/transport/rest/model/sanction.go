@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type SanctionReq struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+	// Duration is Go duration like "72h", omitted for ban
+	Duration string `json:"duration,omitempty"`
+}
+
+type Sanction struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason"`
+	IssuedBy  string     `json:"issued_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	LiftedBy  string     `json:"lifted_by,omitempty"`
+	LiftedAt  *time.Time `json:"lifted_at,omitempty"`
+	Active    bool       `json:"active"`
+}
+
+// SanctionError is payload of response to banned or suspended user, ExpiresAt is omitted for permanent ban
+type SanctionError struct {
+	Message   string     `json:"message"`
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
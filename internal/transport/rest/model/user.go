@@ -1,25 +1,102 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type User struct {
 	ID        string    `json:"id"`
 	Nickname  string    `json:"nickname"`
 	Wallet    string    `json:"wallet"`
+	Wallets   []string  `json:"wallets"`
 	CreatedAt time.Time `json:"createdAt"`
+	Role      string    `json:"role"`
+	Avatar    Avatar    `json:"avatar"`
+	Bio       string    `json:"bio"`
+	Country   string    `json:"country"`
+	Privacy   Privacy   `json:"privacy"`
+	// DeleteRequestedAt is set while account deletion is pending
+	DeleteRequestedAt *time.Time `json:"delete_requested_at,omitempty"`
+}
+
+// PublicUser is profile of user other players see, fields hidden by privacy settings are omitted
+type PublicUser struct {
+	ID        string     `json:"id"`
+	Nickname  string     `json:"nickname"`
+	Avatar    Avatar     `json:"avatar"`
+	Bio       string     `json:"bio,omitempty"`
+	Country   string     `json:"country,omitempty"`
+	Wallet    string     `json:"wallet,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+type PublicUserPage struct {
+	Users      []*PublicUser `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type Avatar struct {
+	URL string `json:"url,omitempty"`
+	NFT string `json:"nft,omitempty"`
+}
+
+type Privacy struct {
+	Private     bool `json:"private"`
+	HideCountry bool `json:"hide_country"`
+	ShowWallet  bool `json:"show_wallet"`
+}
+
+type UserUpdateReq struct {
+	Nickname *string  `json:"nickname,omitempty"`
+	Avatar   *Avatar  `json:"avatar,omitempty"`
+	Bio      *string  `json:"bio,omitempty"`
+	Country  *string  `json:"country,omitempty"`
+	Privacy  *Privacy `json:"privacy,omitempty"`
+}
+
+type UserNonceReq struct {
+	Chain  string `json:"chain,omitempty"`
+	Wallet string `json:"wallet"`
+}
+
+type UserNonceRes struct {
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type UserAuthReq struct {
-	Wallet  string `json:"wallet"`
-	Sign    string `json:"sign"`
-	Message string `json:"message"`
+	Chain     string          `json:"chain,omitempty"`
+	Wallet    string          `json:"wallet"`
+	Sign      string          `json:"sign"`
+	Message   string          `json:"message"`
+	TypedData json.RawMessage `json:"typed_data,omitempty"`
 }
 
 type UserRefreshReq struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
-type UserAuthRes struct {
-	AuthToken    string `json:"auth_token"`
+type UserLogoutReq struct {
 	RefreshToken string `json:"refresh_token"`
 }
+
+// UserGuestReq is guest login, DeviceID is secret id app generates once per install
+type UserGuestReq struct {
+	DeviceID string `json:"device_id"`
+}
+
+type UserAuthRes struct {
+	AuthToken string `json:"auth_token"`
+	// RefreshToken is omitted when session keeps refresh token it has
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
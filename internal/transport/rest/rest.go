@@ -4,12 +4,15 @@ import (
 	"context"
 	"net/http"
 	"server/internal/config"
-	"server/internal/repository/db/mongodb"
+	"server/internal/domain"
+	"server/internal/repository/db"
 	"server/internal/service"
 	"server/internal/transport/rest/handler"
+	"server/pkg/nickname"
+	"server/pkg/sign"
 	"time"
 
-	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoLog "github.com/labstack/gommon/log"
@@ -20,21 +23,101 @@ func RunRest() error {
 	ctx := context.Background()
 
 	cfg := config.Get()
-
-	// connect db
-	db, err := mongodb.Connect(ctx)
+	err := cfg.Validate()
 	if err != nil {
 		return err
 	}
 
-	// init repositories
-	userRepo := mongodb.NewUserRepo(db)
+	// init repositories of storage selected in config
+	repos, err := db.Open(ctx)
+	if err != nil {
+		return err
+	}
+	userRepo := repos.User
+	nonceRepo := repos.Nonce
+	refreshTokenRepo := repos.RefreshToken
+	revocationRepo := repos.Revocation
+	signingKeyRepo := repos.SigningKey
+	sessionRepo := repos.Session
+	roleChangeRepo := repos.RoleChange
+	sanctionRepo := repos.Sanction
+	apiKeyRepo := repos.APIKey
+
+	// init smart-contract wallets signature verifier
+	var contractVerifier sign.ContractVerifier
+	if cfg.EthRPCURL != "" {
+		ethClient, err := ethclient.DialContext(ctx, cfg.EthRPCURL)
+		if err != nil {
+			return err
+		}
+		contractVerifier, err = sign.NewEIP1271Verifier(ethClient)
+		if err != nil {
+			return err
+		}
+	}
 
 	// init services
-	userService := service.NewUserService(userRepo)
+	// generated nicknames follow the same rules as chosen ones
+	nicknameRules := nickname.Rules{
+		MinLength: cfg.NicknameMinLength,
+		MaxLength: cfg.NicknameMaxLength,
+		Profanity: append(append([]string{}, nickname.Profanity...), cfg.NicknameProfanity...),
+		Allowed:   nickname.Allowed,
+	}
+	nicknameGenerator := nickname.NewGenerator(nicknameRules)
+	userService := service.NewUserService(userRepo, nonceRepo, sanctionRepo, contractVerifier, nicknameGenerator, service.UserServiceConfig{
+		NonceTTL: cfg.NonceTTL,
+		Siwe: sign.SiweConfig{
+			Chain:   sign.ChainEthereum,
+			Domain:  cfg.SiweDomain,
+			URI:     cfg.SiweURI,
+			ChainID: cfg.SiweChainID,
+		},
+		EVMAccountReference: cfg.EVMAccountReference,
+		SignMode:            sign.Mode(cfg.SignMode),
+		TypedData: sign.TypedDataConfig{
+			Name:              cfg.EIP712Name,
+			Version:           cfg.EIP712Version,
+			ChainID:           cfg.EIP712ChainID,
+			VerifyingContract: cfg.EIP712Contract,
+		},
+		SolanaSiwe: sign.SiweConfig{
+			Chain:   sign.ChainSolana,
+			Domain:  cfg.SiweDomain,
+			URI:     cfg.SiweURI,
+			ChainID: cfg.SolanaChainID,
+		},
+		Nickname:         nicknameRules,
+		NicknameCooldown: cfg.NicknameCooldown,
+		BioMaxLength:     cfg.BioMaxLength,
+		SearchPageSize:   cfg.SearchPageSize,
+	})
+
+	tokenService := service.NewTokenService(refreshTokenRepo, revocationRepo, sessionRepo, cfg.RefreshTokenTTL)
+	adminService := service.NewAdminService(userRepo, roleChangeRepo, tokenService, nicknameGenerator)
+	sanctionService := service.NewSanctionService(sanctionRepo, userRepo)
+	accountService := service.NewAccountService(userRepo, sessionRepo, refreshTokenRepo, revocationRepo, roleChangeRepo, sanctionRepo, apiKeyRepo, service.AccountServiceConfig{
+		DeletionGracePeriod: cfg.DeletionGracePeriod,
+		PurgeInterval:       cfg.PurgeInterval,
+	})
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	keyService := service.NewKeyService(signingKeyRepo, service.KeyServiceConfig{
+		Algorithm:      cfg.JWTAlgorithm,
+		RotationPeriod: cfg.JWTKeyRotation,
+		AccessTokenTTL: cfg.AccessTokenTTL,
+	})
+	err = keyService.Rotate(ctx)
+	if err != nil {
+		return err
+	}
 
 	// init handlers
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, tokenService, keyService, sanctionService)
+	keyHandler := handler.NewKeyHandler(keyService)
+	adminHandler := handler.NewAdminHandler(adminService, tokenService)
+	sanctionHandler := handler.NewSanctionHandler(sanctionService)
+	accountHandler := handler.NewAccountHandler(accountService, tokenService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 
 	// init echo
 	e := echo.New()
@@ -49,16 +132,78 @@ func RunRest() error {
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
+	// Rotate signing keys
+	go keyService.Run(ctx, func(err error) {
+		e.Logger.Error(err)
+	})
+
+	// Purge accounts which deletion grace period is over
+	go accountService.Run(ctx, func(err error) {
+		e.Logger.Error(err)
+	})
+
+	// Public keys for access token verification
+	e.GET("/.well-known/jwks.json", keyHandler.JWKS)
+
 	// API V1
 	v1 := e.Group("/v1")
 
 	// Auth jwt request
+	v1.POST("/auth/nonce", userHandler.Nonce)
 	v1.POST("/auth", userHandler.Auth)
+	v1.POST("/auth/refresh", userHandler.Refresh)
+	v1.POST("/auth/guest", userHandler.Guest)
+
+	// Access token check
+	jwtAuth := []echo.MiddlewareFunc{
+		handler.JWTMiddleware(keyService),
+		handler.RevocationMiddleware(tokenService),
+		handler.SanctionMiddleware(sanctionService),
+	}
+
+	// Access token or API key check, API key is accepted by routes services call
+	auth := handler.AuthMiddleware(apiKeyService, jwtAuth...)
+
+	// Logout
+	v1.POST("/auth/logout", userHandler.Logout, jwtAuth...)
+	v1.POST("/auth/logout-all", userHandler.LogoutAll, jwtAuth...)
+
+	// Guest sign in with wallet
+	v1.POST("/auth/upgrade", userHandler.Upgrade, jwtAuth...)
 
 	// User
 	r := v1.Group("/user")
-	r.Use(echojwt.JWT([]byte(cfg.JWTSecret)))
+	r.Use(jwtAuth...)
 	r.GET("", userHandler.Me)
+	r.PATCH("", userHandler.Update, handler.PermissionMiddleware(domain.PermissionProfileWrite))
+	r.DELETE("", accountHandler.Delete)
+	r.GET("/export", accountHandler.Export)
+	r.GET("/sessions", userHandler.Sessions)
+	r.DELETE("/sessions/:id", userHandler.DeleteSession)
+	r.POST("/wallets", userHandler.LinkWallet, handler.PermissionMiddleware(domain.PermissionProfileWrite))
+	r.DELETE("/wallets/:wallet", userHandler.UnlinkWallet)
+
+	// Other users
+	u := v1.Group("/users")
+	u.Use(auth)
+	u.GET("", userHandler.Search)
+	u.GET("/:id", userHandler.Profile)
+
+	// Staff
+	a := v1.Group("/admin")
+	a.Use(auth)
+	a.GET("/users", adminHandler.FindUser, handler.PermissionMiddleware(domain.PermissionUsersRead))
+	a.GET("/users/:id", adminHandler.GetUser, handler.PermissionMiddleware(domain.PermissionUsersRead))
+	a.GET("/users/:id/roles", adminHandler.RoleChanges, handler.PermissionMiddleware(domain.PermissionUsersRead))
+	a.PUT("/users/:id/role", adminHandler.SetRole, handler.PermissionMiddleware(domain.PermissionRolesManage))
+	a.POST("/users/:id/logout", adminHandler.Logout, handler.PermissionMiddleware(domain.PermissionUsersManage))
+	a.POST("/users/:id/reset-profile", adminHandler.ResetProfile, handler.PermissionMiddleware(domain.PermissionUsersManage))
+	a.GET("/users/:id/sanctions", sanctionHandler.List, handler.PermissionMiddleware(domain.PermissionUsersRead))
+	a.POST("/users/:id/sanctions", sanctionHandler.Issue, handler.PermissionMiddleware(domain.PermissionSanctionsManage))
+	a.DELETE("/sanctions/:id", sanctionHandler.Lift, handler.PermissionMiddleware(domain.PermissionSanctionsManage))
+	a.GET("/api-keys", apiKeyHandler.List, handler.PermissionMiddleware(domain.PermissionAPIKeysManage))
+	a.POST("/api-keys", apiKeyHandler.Create, handler.PermissionMiddleware(domain.PermissionAPIKeysManage))
+	a.DELETE("/api-keys/:id", apiKeyHandler.Revoke, handler.PermissionMiddleware(domain.PermissionAPIKeysManage))
 
 	// Start server
 	s := &http.Server{
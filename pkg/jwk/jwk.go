@@ -0,0 +1,96 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JSON Web Key (RFC 7517) helpers for asymmetric JWT signing keys
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+// Key is public JSON Web Key
+type Key struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA public key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 public key
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// Set is JSON Web Key Set served at /.well-known/jwks.json
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// GenerateKey creates private key for algorithm
+func GenerateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("jwk algorithm %q not supported", algorithm)
+	}
+}
+
+// SigningMethod returns jwt signing method of algorithm
+func SigningMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("jwk algorithm %q not supported", algorithm)
+	}
+}
+
+// PublicKey encodes public part of key
+func PublicKey(keyID, algorithm string, key crypto.PublicKey) (Key, error) {
+	res := Key{
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: algorithm,
+	}
+
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		if algorithm != AlgorithmRS256 {
+			return Key{}, fmt.Errorf("jwk algorithm %q doesn't match rsa key", algorithm)
+		}
+		res.KeyType = "RSA"
+		res.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		res.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		if algorithm != AlgorithmEdDSA {
+			return Key{}, fmt.Errorf("jwk algorithm %q doesn't match ed25519 key", algorithm)
+		}
+		res.KeyType = "OKP"
+		res.Curve = "Ed25519"
+		res.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	default:
+		return Key{}, fmt.Errorf("jwk key type %T not supported", key)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package jwk_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"server/pkg/jwk"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKey(t *testing.T) {
+	for _, algorithm := range []string{jwk.AlgorithmRS256, jwk.AlgorithmEdDSA} {
+		t.Logf("testing %s", algorithm)
+
+		privateKey, err := jwk.GenerateKey(algorithm)
+		require.NoError(t, err)
+
+		method, err := jwk.SigningMethod(algorithm)
+		require.NoError(t, err)
+		assert.Equal(t, algorithm, method.Alg())
+
+		// key must be usable with jwt signing method
+		claims := jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(privateKey)
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+			return privateKey.Public(), nil
+		})
+		assert.NoError(t, err)
+
+		publicKey, err := jwk.PublicKey("kid", algorithm, privateKey.Public())
+		require.NoError(t, err)
+		assert.Equal(t, "kid", publicKey.KeyID)
+		assert.Equal(t, algorithm, publicKey.Algorithm)
+		assert.Equal(t, "sig", publicKey.Use)
+	}
+
+	_, err := jwk.GenerateKey("HS256")
+	assert.Error(t, err)
+
+	_, err = jwk.SigningMethod("HS256")
+	assert.Error(t, err)
+}
+
+func TestPublicKey(t *testing.T) {
+	rsaKey, err := jwk.GenerateKey(jwk.AlgorithmRS256)
+	require.NoError(t, err)
+
+	publicKey, err := jwk.PublicKey("rsa", jwk.AlgorithmRS256, rsaKey.Public())
+	require.NoError(t, err)
+	assert.Equal(t, "RSA", publicKey.KeyType)
+	assert.Equal(t, "AQAB", publicKey.E)
+	n, err := base64.RawURLEncoding.DecodeString(publicKey.N)
+	require.NoError(t, err)
+	assert.Equal(t, rsaKey.Public().(*rsa.PublicKey).N.Bytes(), n)
+
+	edKey, err := jwk.GenerateKey(jwk.AlgorithmEdDSA)
+	require.NoError(t, err)
+
+	publicKey, err = jwk.PublicKey("ed", jwk.AlgorithmEdDSA, edKey.Public())
+	require.NoError(t, err)
+	assert.Equal(t, "OKP", publicKey.KeyType)
+	assert.Equal(t, "Ed25519", publicKey.Curve)
+	x, err := base64.RawURLEncoding.DecodeString(publicKey.X)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(edKey.Public().(ed25519.PublicKey)), x)
+
+	// algorithm must match key type
+	_, err = jwk.PublicKey("ed", jwk.AlgorithmRS256, edKey.Public())
+	assert.Error(t, err)
+	_, err = jwk.PublicKey("rsa", jwk.AlgorithmEdDSA, rsaKey.Public())
+	assert.Error(t, err)
+}
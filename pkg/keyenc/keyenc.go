@@ -0,0 +1,86 @@
+// Package keyenc encrypts private keys kept at rest with key-encryption key, AES-256-GCM
+package keyenc
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is size of key-encryption key, AES-256
+const KeySize = 32
+
+// ErrDecrypt is returned for private key encrypted with another key-encryption key or tampered with
+var ErrDecrypt = errors.New("keyenc: private key can't be decrypted")
+
+// ParseKey decodes base64 key-encryption key
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("keyenc: key-encryption key must be base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("keyenc: key-encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// Cipher encrypts private keys with key-encryption key, key id is authenticated with private key,
+// so encrypted key can't be stored under another id
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func New(key []byte) (*Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("keyenc: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("keyenc: %w", err)
+	}
+	return &Cipher{aead}, nil
+}
+
+// Encrypt returns PKCS #8 private key encrypted with random nonce prepended
+func (c *Cipher) Encrypt(id string, privateKey crypto.Signer) ([]byte, error) {
+	plain, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("keyenc: private key to pkcs8: %w", err)
+	}
+
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plain)+c.aead.Overhead())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("keyenc: nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, plain, []byte(id)), nil
+}
+
+// Decrypt returns private key encrypted by Encrypt under the same id
+func (c *Cipher) Decrypt(id string, encrypted []byte) (crypto.Signer, error) {
+	if len(encrypted) < c.aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := encrypted[:c.aead.NonceSize()], encrypted[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, sealed, []byte(id))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(plain)
+	if err != nil {
+		return nil, fmt.Errorf("keyenc: private key from pkcs8: %w", err)
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("keyenc: private key is not signer")
+	}
+	return signer, nil
+}
@@ -0,0 +1,68 @@
+package keyenc_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"server/pkg/keyenc"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCipher(t *testing.T) *keyenc.Cipher {
+	key := make([]byte, keyenc.KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	c, err := keyenc.New(key)
+	require.NoError(t, err)
+	return c
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := keyenc.ParseKey(base64.StdEncoding.EncodeToString(make([]byte, keyenc.KeySize)))
+	require.NoError(t, err)
+	assert.Len(t, key, keyenc.KeySize)
+
+	_, err = keyenc.ParseKey(base64.StdEncoding.EncodeToString(make([]byte, 16)))
+	assert.Error(t, err)
+	_, err = keyenc.ParseKey("not base64!")
+	assert.Error(t, err)
+	_, err = keyenc.ParseKey("")
+	assert.Error(t, err)
+}
+
+func TestCipher(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	c := newCipher(t)
+
+	encrypted, err := c.Encrypt("kid", privateKey)
+	require.NoError(t, err)
+	plain, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(encrypted, plain), "private key is stored in plain")
+
+	decrypted, err := c.Decrypt("kid", encrypted)
+	require.NoError(t, err)
+	assert.True(t, privateKey.Equal(decrypted))
+
+	// key moved under another id
+	_, err = c.Decrypt("other", encrypted)
+	assert.ErrorIs(t, err, keyenc.ErrDecrypt)
+
+	// another key-encryption key
+	_, err = newCipher(t).Decrypt("kid", encrypted)
+	assert.ErrorIs(t, err, keyenc.ErrDecrypt)
+
+	// tampered
+	encrypted[len(encrypted)-1] ^= 1
+	_, err = c.Decrypt("kid", encrypted)
+	assert.ErrorIs(t, err, keyenc.ErrDecrypt)
+
+	_, err = c.Decrypt("kid", nil)
+	assert.ErrorIs(t, err, keyenc.ErrDecrypt)
+}
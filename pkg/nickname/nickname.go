@@ -0,0 +1,52 @@
+package nickname
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// maxNumber bounds number suffix, so generated nicknames are short yet rarely collide
+const maxNumber = 10000
+
+var adjectives = []string{
+	"Agile", "Bold", "Brave", "Bright", "Calm", "Clever", "Cosmic", "Crafty", "Daring", "Eager",
+	"Fancy", "Fearless", "Fierce", "Gentle", "Golden", "Grand", "Happy", "Hidden", "Jolly", "Keen",
+	"Lucky", "Mighty", "Nimble", "Noble", "Quick", "Quiet", "Rapid", "Sly", "Swift", "Wild",
+}
+
+var nouns = []string{
+	"Badger", "Bear", "Comet", "Dragon", "Eagle", "Falcon", "Fox", "Griffin", "Hawk", "Knight",
+	"Lion", "Lynx", "Mage", "Nomad", "Otter", "Owl", "Panda", "Phoenix", "Pirate", "Ranger",
+	"Raven", "Rogue", "Shark", "Tiger", "Titan", "Viking", "Wizard", "Wolf", "Yeti", "Zebra",
+}
+
+// generateAttempts bounds candidates tried, so rules rejecting every candidate don't hang generation
+const generateAttempts = 100
+
+// Generator creates random adjective-noun-number nicknames like BraveOtter4821
+type Generator struct {
+	rules Rules
+}
+
+// NewGenerator returns generator of nicknames satisfying rules, the same ones player chosen nicknames follow
+func NewGenerator(rules Rules) *Generator {
+	return &Generator{rules}
+}
+
+// Generate returns random nickname, candidates rules reject, e.g. with words from configured profanity list, are skipped.
+// It fails when rules reject every candidate tried, so nickname breaking rules is never returned.
+func (g *Generator) Generate() (string, error) {
+	var err error
+	for attempt := 0; attempt < generateAttempts; attempt++ {
+		nickname := fmt.Sprintf("%s%s%d",
+			adjectives[rand.Intn(len(adjectives))],
+			nouns[rand.Intn(len(nouns))],
+			rand.Intn(maxNumber),
+		)
+		err = g.rules.Validate(nickname)
+		if err == nil {
+			return nickname, nil
+		}
+	}
+	return "", fmt.Errorf("no valid nickname generated in %d attempts: %w", generateAttempts, err)
+}
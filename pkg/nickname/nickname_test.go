@@ -0,0 +1,96 @@
+package nickname_test
+
+import (
+	"server/pkg/nickname"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rules = nickname.Rules{
+	MinLength: 3,
+	MaxLength: 20,
+	Profanity: nickname.Profanity,
+	Allowed:   nickname.Allowed,
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	generator := nickname.NewGenerator(rules)
+
+	for i := 0; i < 100; i++ {
+		generated, err := generator.Generate()
+		require.NoError(t, err)
+		assert.NoError(t, rules.Validate(generated))
+	}
+}
+
+func TestGenerator_GenerateSkipsProfanity(t *testing.T) {
+	// words configured by operator are skipped even when they are generator's own words
+	rules := nickname.Rules{
+		MinLength: 3,
+		MaxLength: 20,
+		Profanity: append([]string{"otter", "wolf"}, nickname.Profanity...),
+	}
+	generator := nickname.NewGenerator(rules)
+
+	for i := 0; i < 1000; i++ {
+		generated, err := generator.Generate()
+		require.NoError(t, err)
+		assert.NotContains(t, generated, "Otter")
+		assert.NotContains(t, generated, "Wolf")
+	}
+}
+
+func TestGenerator_GenerateFailsWhenRulesRejectAll(t *testing.T) {
+	// generated nicknames are longer than rules allow
+	rules := nickname.Rules{MinLength: 3, MaxLength: 5}
+	generator := nickname.NewGenerator(rules)
+
+	generated, err := generator.Generate()
+	assert.Error(t, err)
+	assert.Empty(t, generated)
+}
+
+func TestRules_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		nickname string
+		fail     bool
+	}{
+		{name: "valid", nickname: "Player_One-1"},
+		{name: "too short", nickname: "ab", fail: true},
+		{name: "too long", nickname: "abcdefghijklmnopqrstu", fail: true},
+		{name: "space", nickname: "Player One", fail: true},
+		{name: "non latin", nickname: "Игрок", fail: true},
+		{name: "profanity", nickname: "BigShit42", fail: true},
+		{name: "leet profanity", nickname: "sh1t_happens", fail: true},
+		{name: "separated profanity", nickname: "sh_it_happens", fail: true},
+		{name: "camel case profanity", nickname: "XXShitXX", fail: true},
+		{name: "profanity with leet digit at start", nickname: "5hit_happens", fail: true},
+		{name: "plural profanity", nickname: "bitches", fail: true},
+		{name: "plural slur", nickname: "niggers", fail: true},
+		{name: "derived profanity", nickname: "fucker", fail: true},
+		{name: "compound profanity", nickname: "shithead", fail: true},
+		{name: "compound profanity with word", nickname: "fuckyou", fail: true},
+		{name: "compound profanity after word", nickname: "Big_shithead", fail: true},
+		{name: "separated compound profanity", nickname: "sh_it_head", fail: true},
+		{name: "allowed word with profanity stem", nickname: "CockpitHero"},
+		{name: "words joined into profanity stem", nickname: "Ana_Lopez"},
+		{name: "profanity inside word", nickname: "Scunthorpe"},
+		{name: "short word inside word", nickname: "Analyst42"},
+		{name: "short word inside camel case word", nickname: "TheDickens"},
+		{name: "leet digits in harmless words", nickname: "Cla55ic_Bass"},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		err := rules.Validate(test.nickname)
+		if test.fail {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
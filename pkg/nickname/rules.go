@@ -0,0 +1,129 @@
+package nickname
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed profanity.txt
+var profanityList string
+
+//go:embed allowed.txt
+var allowedList string
+
+// Profanity is default list of words nicknames must not contain
+var Profanity = strings.Fields(profanityList)
+
+// Allowed is default list of harmless words starting with profanity, like analyst or cockpit
+var Allowed = strings.Fields(allowedList)
+
+// leetReplacer undoes common letter substitutions used to get around profanity filters
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b",
+)
+
+// Rules are constraints player chosen nickname must satisfy
+type Rules struct {
+	MinLength int
+	MaxLength int
+	// Profanity are words nickname must not contain, case and leet speak insensitive.
+	// They are matched as stems at start of words of nickname, so plurals and compounds
+	// like bitches or fuckyou are found, while words merely containing them like Scunthorpe are not.
+	Profanity []string
+	// Allowed are harmless words starting with profanity, which are not matched
+	Allowed []string
+}
+
+// Validate checks nickname length, charset and profanity
+func (r Rules) Validate(nickname string) error {
+	length := utf8.RuneCountInString(nickname)
+	if length < r.MinLength || length > r.MaxLength {
+		return fmt.Errorf("nickname must be %d to %d characters long", r.MinLength, r.MaxLength)
+	}
+
+	for _, c := range nickname {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return fmt.Errorf("nickname may contain only latin letters, digits, '_' and '-'")
+		}
+	}
+
+	words := words(nickname)
+	for i := range words {
+		if r.allowed(strings.Join(words[i:], "")) {
+			continue
+		}
+		for _, profanity := range r.Profanity {
+			if startsWith(words[i:], strings.ToLower(profanity)) {
+				return fmt.Errorf("nickname contains inappropriate word")
+			}
+		}
+	}
+
+	return nil
+}
+
+// startsWith checks words start with profanity, which is stem of the first word like in shithead
+// or is split by separators like sh_it. Split profanity must end with word, so Ana_Lopez is not anal.
+func startsWith(words []string, profanity string) bool {
+	if strings.HasPrefix(words[0], profanity) {
+		return true
+	}
+	joined := ""
+	for _, word := range words {
+		joined += word
+		if len(joined) >= len(profanity) {
+			return joined == profanity
+		}
+	}
+	return false
+}
+
+// allowed checks nickname from some word on starts with allowed word
+func (r Rules) allowed(rest string) bool {
+	for _, allowed := range r.Allowed {
+		if strings.HasPrefix(rest, strings.ToLower(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// words splits nickname into lower case words at '_', '-', case changes like in BraveOtter
+// and between letters and digits, digits are read as letters they replace, e.g. Sh1t is sh, i, t
+func words(nickname string) []string {
+	res := []string{}
+	word := []rune{}
+
+	runes := []rune(nickname)
+	for i, c := range runes {
+		if c == '_' || c == '-' {
+			if len(word) > 0 {
+				res = append(res, string(word))
+			}
+			word = word[:0]
+			continue
+		}
+		if len(word) > 0 {
+			previous := runes[i-1]
+			// word starts at capital letter after lower case one or at last capital of acronym, like in XMLParser
+			capital := unicode.IsUpper(c) && (unicode.IsLower(previous) ||
+				unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))
+			if capital || unicode.IsDigit(c) != unicode.IsDigit(previous) {
+				res = append(res, string(word))
+				word = word[:0]
+			}
+		}
+		word = append(word, c)
+	}
+	if len(word) > 0 {
+		res = append(res, string(word))
+	}
+
+	for i, word := range res {
+		res[i] = leetReplacer.Replace(strings.ToLower(word))
+	}
+	return res
+}
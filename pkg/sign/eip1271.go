@@ -0,0 +1,88 @@
+package sign
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Standard signature validation for contracts (EIP-1271), see https://eips.ethereum.org/EIPS/eip-1271
+
+const eip1271ABI = `[{
+	"name": "isValidSignature",
+	"type": "function",
+	"stateMutability": "view",
+	"inputs": [
+		{"name": "hash", "type": "bytes32"},
+		{"name": "signature", "type": "bytes"}
+	],
+	"outputs": [
+		{"name": "magicValue", "type": "bytes4"}
+	]
+}]`
+
+// eip1271MagicValue is bytes4(keccak256("isValidSignature(bytes32,bytes)"))
+var eip1271MagicValue = []byte{0x16, 0x26, 0xba, 0x7e}
+
+// ContractVerifier checks signature of hash on behalf of smart-contract wallet
+//
+//go:generate mockery --dir . --name ContractVerifier --output ./mocks
+type ContractVerifier interface {
+	Verify(ctx context.Context, wallet string, hash []byte, sign string) error
+}
+
+// EIP1271Verifier calls isValidSignature of wallet contract through Ethereum RPC client
+type EIP1271Verifier struct {
+	caller bind.ContractCaller
+	abi    abi.ABI
+}
+
+func NewEIP1271Verifier(caller bind.ContractCaller) (*EIP1271Verifier, error) {
+	parsed, err := abi.JSON(strings.NewReader(eip1271ABI))
+	if err != nil {
+		return nil, err
+	}
+	return &EIP1271Verifier{caller, parsed}, nil
+}
+
+func (v *EIP1271Verifier) Verify(ctx context.Context, wallet string, hash []byte, sign string) error {
+	if !common.IsHexAddress(wallet) {
+		return fmt.Errorf("wallet address invalid")
+	}
+	if len(hash) != common.HashLength {
+		return fmt.Errorf("hash length invalid")
+	}
+
+	signature, err := hexutil.Decode(sign)
+	if err != nil {
+		return err
+	}
+
+	data, err := v.abi.Pack("isValidSignature", common.BytesToHash(hash), signature)
+	if err != nil {
+		return err
+	}
+
+	contract := common.HexToAddress(wallet)
+	result, err := v.caller.CallContract(ctx, ethereum.CallMsg{
+		To:   &contract,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("contract call failed: %w", err)
+	}
+
+	// wallet without code returns empty result
+	if len(result) < len(eip1271MagicValue) || !bytes.Equal(result[:len(eip1271MagicValue)], eip1271MagicValue) {
+		return fmt.Errorf("contract signature invalid")
+	}
+
+	return nil
+}
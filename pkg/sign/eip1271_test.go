@@ -0,0 +1,80 @@
+package sign_test
+
+import (
+	"context"
+	"server/pkg/sign"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// approvedHashWalletCode is runtime code of contract wallet, which isValidSignature
+// returns magic value only for hash placed at offset 1 (PUSH32 argument)
+func approvedHashWalletCode(hash []byte) []byte {
+	code := []byte{0x7f} // PUSH32 hash
+	code = append(code, hash...)
+	code = append(code,
+		0x60, 0x04, // PUSH1 4
+		0x35,       // CALLDATALOAD
+		0x14,       // EQ
+		0x60, 0x2d, // PUSH1 valid
+		0x57,                         // JUMPI
+		0x60, 0x20, 0x60, 0x00, 0xf3, // RETURN 32 zero bytes
+		0x5b,                         // JUMPDEST valid
+		0x63, 0x16, 0x26, 0xba, 0x7e, // PUSH4 magic value
+		0x60, 0xe0, // PUSH1 224
+		0x1b,       // SHL
+		0x60, 0x00, // PUSH1 0
+		0x52,                         // MSTORE
+		0x60, 0x20, 0x60, 0x00, 0xf3, // RETURN 32 bytes
+	)
+	return code
+}
+
+func TestEIP1271Verifier_Verify(t *testing.T) {
+	hash, err := sign.HashMessage(sign.ModePersonal, "test")
+	require.NoError(t, err)
+
+	otherHash, err := sign.HashMessage(sign.ModePersonal, "test1")
+	require.NoError(t, err)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	eoa := crypto.PubkeyToAddress(key.PublicKey)
+
+	wallet := common.HexToAddress("0x28e582BA14CD679FB08E47dC50b565c715Ce0979")
+
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		wallet: {Code: approvedHashWalletCode(hash), Balance: common.Big0},
+	}, 10_000_000)
+	defer backend.Close()
+
+	verifier, err := sign.NewEIP1271Verifier(backend)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	err = verifier.Verify(ctx, wallet.Hex(), hash, "0x")
+	assert.NoError(t, err)
+
+	err = verifier.Verify(ctx, wallet.Hex(), otherHash, "0x")
+	assert.Error(t, err)
+
+	// account without code can't approve anything
+	err = verifier.Verify(ctx, eoa.Hex(), hash, "0x")
+	assert.Error(t, err)
+
+	err = verifier.Verify(ctx, "wallet", hash, "0x")
+	assert.Error(t, err)
+
+	err = verifier.Verify(ctx, wallet.Hex(), hash[:8], "0x")
+	assert.Error(t, err)
+
+	err = verifier.Verify(ctx, wallet.Hex(), hash, "dasdasd")
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package sign
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// EIP-712 typed structured data, see https://eips.ethereum.org/EIPS/eip-712
+
+const (
+	typedDataDomainType = "EIP712Domain"
+
+	// TypedDataLoginType is primary type of login payload:
+	// Login(address wallet,string nonce)
+	TypedDataLoginType = "Login"
+)
+
+// TypedDataConfig holds EIP-712 domain login payload must be signed for
+type TypedDataConfig struct {
+	Name              string
+	Version           string
+	ChainID           int64
+	VerifyingContract string
+}
+
+// TypedDataLogin holds fields of verified login payload
+type TypedDataLogin struct {
+	Wallet string
+	Nonce  string
+}
+
+// ParseTypedData decodes eth_signTypedData_v4 JSON payload
+func ParseTypedData(data []byte) (*apitypes.TypedData, error) {
+	typedData := &apitypes.TypedData{}
+	err := json.Unmarshal(data, typedData)
+	if err != nil {
+		return nil, fmt.Errorf("typed data invalid: %w", err)
+	}
+	return typedData, nil
+}
+
+// DomainSeparator returns hashStruct of typed data domain
+func DomainSeparator(typedData *apitypes.TypedData) ([]byte, error) {
+	if _, ok := typedData.Types[typedDataDomainType]; !ok {
+		return nil, fmt.Errorf("typed data domain type not found")
+	}
+	return typedData.HashStruct(typedDataDomainType, typedData.Domain.Map())
+}
+
+// HashTypedData returns keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message)), the hash wallets sign
+func HashTypedData(typedData *apitypes.TypedData) ([]byte, error) {
+	domainSeparator, err := DomainSeparator(typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := typedData.Types[typedData.PrimaryType]; !ok {
+		return nil, fmt.Errorf("typed data primary type %q not found", typedData.PrimaryType)
+	}
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
+
+// VerifyTypedDataSignature checks typed data is signed by wallet
+func VerifyTypedDataSignature(wallet string, typedData *apitypes.TypedData, sign string) error {
+
+	hash, err := HashTypedData(typedData)
+	if err != nil {
+		return err
+	}
+
+	signature, err := hexutil.Decode(sign)
+	if err != nil {
+		return err
+	}
+
+	return verifyHashSignature(wallet, hash, signature)
+}
+
+// ParseTypedDataLogin checks typed data is login payload issued for configured domain and returns its fields
+func ParseTypedDataLogin(typedData *apitypes.TypedData, cfg TypedDataConfig) (*TypedDataLogin, error) {
+	// without configured domain any payload for empty name and zero contract would pass
+	if cfg.Name == "" || !common.IsHexAddress(cfg.VerifyingContract) || common.HexToAddress(cfg.VerifyingContract) == (common.Address{}) {
+		return nil, fmt.Errorf("typed data login not configured")
+	}
+
+	domain := typedData.Domain
+	if domain.Name != cfg.Name || domain.Version != cfg.Version {
+		return nil, fmt.Errorf("typed data domain %q version %q not allowed", domain.Name, domain.Version)
+	}
+	if domain.ChainId == nil || (*big.Int)(domain.ChainId).Cmp(big.NewInt(cfg.ChainID)) != 0 {
+		return nil, fmt.Errorf("typed data chain id not allowed")
+	}
+	if !common.IsHexAddress(domain.VerifyingContract) ||
+		common.HexToAddress(domain.VerifyingContract) != common.HexToAddress(cfg.VerifyingContract) {
+		return nil, fmt.Errorf("typed data verifying contract %q not allowed", domain.VerifyingContract)
+	}
+
+	if typedData.PrimaryType != TypedDataLoginType {
+		return nil, fmt.Errorf("typed data primary type %q not allowed", typedData.PrimaryType)
+	}
+	fields := map[string]string{}
+	for _, field := range typedData.Types[TypedDataLoginType] {
+		fields[field.Name] = field.Type
+	}
+	if fields["wallet"] != "address" || fields["nonce"] != "string" {
+		return nil, fmt.Errorf("typed data login type invalid")
+	}
+
+	wallet, ok := typedData.Message["wallet"].(string)
+	if !ok {
+		return nil, fmt.Errorf("typed data login wallet invalid")
+	}
+	nonce, ok := typedData.Message["nonce"].(string)
+	if !ok {
+		return nil, fmt.Errorf("typed data login nonce invalid")
+	}
+
+	return &TypedDataLogin{
+		Wallet: wallet,
+		Nonce:  nonce,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package sign_test
+
+import (
+	"server/pkg/sign"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var typedDataConfig = sign.TypedDataConfig{
+	Name:              "Game",
+	Version:           "1",
+	ChainID:           1,
+	VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+}
+
+func typedDataLogin(wallet, nonce string) []byte {
+	return []byte(`{
+		"types": {
+			"EIP712Domain": [
+				{"name": "name", "type": "string"},
+				{"name": "version", "type": "string"},
+				{"name": "chainId", "type": "uint256"},
+				{"name": "verifyingContract", "type": "address"}
+			],
+			"Login": [
+				{"name": "wallet", "type": "address"},
+				{"name": "nonce", "type": "string"}
+			]
+		},
+		"primaryType": "Login",
+		"domain": {
+			"name": "Game",
+			"version": "1",
+			"chainId": 1,
+			"verifyingContract": "0xcccccccccccccccccccccccccccccccccccccccc"
+		},
+		"message": {
+			"wallet": "` + wallet + `",
+			"nonce": "` + nonce + `"
+		}
+	}`)
+}
+
+// Example from EIP-712 specification
+const typedDataMail = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestHashTypedData_Specification(t *testing.T) {
+	typedData, err := sign.ParseTypedData([]byte(typedDataMail))
+	require.NoError(t, err)
+
+	domainSeparator, err := sign.DomainSeparator(typedData)
+	require.NoError(t, err)
+	assert.Equal(t, "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hexutil.Encode(domainSeparator))
+
+	hash, err := sign.HashTypedData(typedData)
+	require.NoError(t, err)
+	assert.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hexutil.Encode(hash))
+}
+
+func TestVerifyTypedDataSignature(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+
+	typedData, err := sign.ParseTypedData(typedDataLogin(address, "32891756abcdef"))
+	require.NoError(t, err)
+
+	hash, err := sign.HashTypedData(typedData)
+	require.NoError(t, err)
+
+	signature, err := crypto.Sign(hash, privateKey)
+	require.NoError(t, err)
+	signature[crypto.RecoveryIDOffset] += 27
+
+	err = sign.VerifyTypedDataSignature(address, typedData, hexutil.Encode(signature))
+	assert.NoError(t, err)
+
+	err = sign.VerifyTypedDataSignature("0xeF1c8b8c7f478c0BE246735c06aE80BEA3675D75", typedData, hexutil.Encode(signature))
+	assert.Error(t, err)
+
+	// signature must not be valid for changed payload
+	typedData.Message["nonce"] = "aaaaaaaaaaaaaa"
+	err = sign.VerifyTypedDataSignature(address, typedData, hexutil.Encode(signature))
+	assert.Error(t, err)
+}
+
+func TestParseTypedDataLogin(t *testing.T) {
+	typedData, err := sign.ParseTypedData(typedDataLogin(siweWallet, "32891756abcdef"))
+	require.NoError(t, err)
+
+	login, err := sign.ParseTypedDataLogin(typedData, typedDataConfig)
+	require.NoError(t, err)
+	assert.Equal(t, siweWallet, login.Wallet)
+	assert.Equal(t, "32891756abcdef", login.Nonce)
+
+	testCases := []struct {
+		name   string
+		modify func(*apitypes.TypedData)
+	}{
+		{name: "wrong name", modify: func(td *apitypes.TypedData) { td.Domain.Name = "Other" }},
+		{name: "wrong contract", modify: func(td *apitypes.TypedData) { td.Domain.VerifyingContract = siweWallet }},
+		{name: "no chain", modify: func(td *apitypes.TypedData) { td.Domain.ChainId = nil }},
+		{name: "wrong primary type", modify: func(td *apitypes.TypedData) { td.PrimaryType = "Mail" }},
+		{name: "wrong wallet type", modify: func(td *apitypes.TypedData) { td.Types["Login"][0].Type = "string" }},
+		{name: "no nonce", modify: func(td *apitypes.TypedData) { delete(td.Message, "nonce") }},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		typedData, err := sign.ParseTypedData(typedDataLogin(siweWallet, "32891756abcdef"))
+		require.NoError(t, err)
+
+		test.modify(typedData)
+
+		_, err = sign.ParseTypedDataLogin(typedData, typedDataConfig)
+		assert.Error(t, err)
+	}
+
+	wrongChain := typedDataConfig
+	wrongChain.ChainID = 5
+	_, err = sign.ParseTypedDataLogin(typedData, wrongChain)
+	assert.Error(t, err)
+
+	_, err = sign.ParseTypedData([]byte("test"))
+	assert.Error(t, err)
+
+	// payload for empty domain isn't accepted when typed data login isn't configured
+	typedData, err = sign.ParseTypedData(typedDataLogin(siweWallet, "32891756abcdef"))
+	require.NoError(t, err)
+	typedData.Domain.Name = ""
+	typedData.Domain.VerifyingContract = "0x0000000000000000000000000000000000000000"
+	_, err = sign.ParseTypedDataLogin(typedData, sign.TypedDataConfig{Version: typedDataConfig.Version, ChainID: typedDataConfig.ChainID})
+	assert.Error(t, err)
+}
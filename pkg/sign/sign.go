@@ -7,16 +7,65 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// Mode selects how message is hashed before signing
+type Mode string
+
+const (
+	// ModeRaw signs Keccak256(message)
+	ModeRaw Mode = "raw"
+	// ModePersonal signs EIP-191 prefixed message, used by personal_sign in MetaMask and most wallets
+	ModePersonal Mode = "personal"
+)
+
+const personalMessagePrefix = "\x19Ethereum Signed Message:\n"
+
+// HashMessage hashes message according to mode
+func HashMessage(mode Mode, message string) ([]byte, error) {
+	switch mode {
+	case ModeRaw:
+		return crypto.Keccak256([]byte(message)), nil
+	case ModePersonal:
+		return crypto.Keccak256([]byte(fmt.Sprintf("%s%d%s", personalMessagePrefix, len(message), message))), nil
+	default:
+		return nil, fmt.Errorf("sign mode %q not supported", mode)
+	}
+}
+
+// VerifySignature checks signature of raw Keccak256 message hash
 func VerifySignature(wallet, message, sign string) error {
+	return VerifySignatureMode(ModeRaw, wallet, message, sign)
+}
+
+// VerifySignatureMode checks message signature hashed according to mode.
+// Recovery id is accepted both as 0/1 and 27/28.
+func VerifySignatureMode(mode Mode, wallet, message, sign string) error {
 
-	hash := crypto.Keccak256Hash([]byte(message))
+	hash, err := HashMessage(mode, message)
+	if err != nil {
+		return err
+	}
 
 	signature, err := hexutil.Decode(sign)
 	if err != nil {
 		return err
 	}
 
-	sigPublicKeyECDSA, err := crypto.SigToPub(hash.Bytes(), signature)
+	return verifyHashSignature(wallet, hash, signature)
+}
+
+// verifyHashSignature recovers signer of hash and compares it with wallet
+func verifyHashSignature(wallet string, hash, signature []byte) error {
+	if len(signature) != crypto.SignatureLength {
+		return fmt.Errorf("signature length invalid")
+	}
+
+	// wallets send recovery id as 27/28, crypto expects 0/1
+	signature = append([]byte{}, signature...)
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	sigPublicKeyECDSA, err := crypto.SigToPub(hash, signature)
 	if err != nil {
 		return err
 	}
@@ -24,7 +73,7 @@ func VerifySignature(wallet, message, sign string) error {
 	sigPublicKeyBytes := crypto.FromECDSAPub(sigPublicKeyECDSA)
 
 	signatureNoRecoverID := signature[:len(signature)-1]
-	verified := crypto.VerifySignature(sigPublicKeyBytes, hash.Bytes(), signatureNoRecoverID)
+	verified := crypto.VerifySignature(sigPublicKeyBytes, hash, signatureNoRecoverID)
 	if !verified {
 		return fmt.Errorf("signature invalid")
 	}
@@ -4,7 +4,11 @@ import (
 	"server/pkg/sign"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVerifySignature_Success(t *testing.T) {
@@ -51,3 +55,33 @@ func TestVerifySignature_Fail(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestVerifySignatureMode_Personal(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	message := "test"
+
+	hash, err := sign.HashMessage(sign.ModePersonal, message)
+	require.NoError(t, err)
+	assert.Equal(t, accounts.TextHash([]byte(message)), hash)
+
+	signature, err := crypto.Sign(hash, privateKey)
+	require.NoError(t, err)
+
+	err = sign.VerifySignatureMode(sign.ModePersonal, address, message, hexutil.Encode(signature))
+	assert.NoError(t, err)
+
+	// wallets send recovery id as 27/28
+	signature[crypto.RecoveryIDOffset] += 27
+	err = sign.VerifySignatureMode(sign.ModePersonal, address, message, hexutil.Encode(signature))
+	assert.NoError(t, err)
+
+	// personal signature must not pass as raw and vice versa
+	err = sign.VerifySignatureMode(sign.ModeRaw, address, message, hexutil.Encode(signature))
+	assert.Error(t, err)
+
+	err = sign.VerifySignatureMode(sign.Mode("unknown"), address, message, hexutil.Encode(signature))
+	assert.Error(t, err)
+}
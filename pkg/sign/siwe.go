@@ -0,0 +1,264 @@
+package sign
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sign-In with Ethereum (EIP-4361) message, see https://eips.ethereum.org/EIPS/eip-4361.
+// The same format is used by other chains (CAIP-122), e.g. Sign-In with Solana.
+
+const (
+	ChainEthereum = "Ethereum"
+	ChainSolana   = "Solana"
+)
+
+const (
+	siweHeaderInfix  = " wants you to sign in with your "
+	siweHeaderSuffix = " account:"
+	siweVersion      = "1"
+
+	siweURITag            = "URI: "
+	siweVersionTag        = "Version: "
+	siweChainIDTag        = "Chain ID: "
+	siweNonceTag          = "Nonce: "
+	siweIssuedAtTag       = "Issued At: "
+	siweExpirationTimeTag = "Expiration Time: "
+	siweNotBeforeTag      = "Not Before: "
+	siweRequestIDTag      = "Request ID: "
+	siweResourcesTag      = "Resources:"
+	siweResourceTag       = "- "
+
+	// siweClockSkew tolerates small difference between wallet and server clocks
+	siweClockSkew = time.Minute
+)
+
+type SiweMessage struct {
+	Domain string
+	// Chain is blockchain name in message header, e.g. Ethereum
+	Chain          string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        string
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// SiweConfig holds values message must be issued for
+type SiweConfig struct {
+	Chain   string
+	Domain  string
+	URI     string
+	ChainID string
+}
+
+// ParseSiweMessage parses EIP-4361 message text
+func ParseSiweMessage(message string) (*SiweMessage, error) {
+	lines := strings.Split(message, "\n")
+	if len(lines) < 9 {
+		return nil, fmt.Errorf("siwe message too short")
+	}
+
+	res := &SiweMessage{}
+
+	header, ok := strings.CutSuffix(lines[0], siweHeaderSuffix)
+	if !ok {
+		return nil, fmt.Errorf("siwe message header invalid")
+	}
+	res.Domain, res.Chain, ok = strings.Cut(header, siweHeaderInfix)
+	if !ok || res.Domain == "" || res.Chain == "" {
+		return nil, fmt.Errorf("siwe message header invalid")
+	}
+
+	res.Address = lines[1]
+	if res.Address == "" {
+		return nil, fmt.Errorf("siwe message address empty")
+	}
+
+	if lines[2] != "" {
+		return nil, fmt.Errorf("siwe message address must be followed by empty line")
+	}
+
+	// statement is optional, but the empty line after its place is not
+	i := 3
+	if lines[i] != "" {
+		res.Statement = lines[i]
+		i++
+	}
+	if lines[i] != "" {
+		return nil, fmt.Errorf("siwe message statement must be followed by empty line")
+	}
+	i++
+
+	var err error
+
+	next := func(tag string, required bool) (string, bool, error) {
+		if i < len(lines) && strings.HasPrefix(lines[i], tag) {
+			value := strings.TrimPrefix(lines[i], tag)
+			i++
+			return value, true, nil
+		}
+		if required {
+			return "", false, fmt.Errorf("siwe message field %q not found", strings.TrimSuffix(tag, ": "))
+		}
+		return "", false, nil
+	}
+
+	if res.URI, _, err = next(siweURITag, true); err != nil {
+		return nil, err
+	}
+
+	if res.Version, _, err = next(siweVersionTag, true); err != nil {
+		return nil, err
+	}
+	if res.Version != siweVersion {
+		return nil, fmt.Errorf("siwe message version %q not supported", res.Version)
+	}
+
+	if res.ChainID, _, err = next(siweChainIDTag, true); err != nil {
+		return nil, err
+	}
+	// EIP-4361 chain id is EIP-155 number, other chains use their own references
+	if res.Chain == ChainEthereum {
+		if _, err := strconv.ParseUint(res.ChainID, 10, 64); err != nil {
+			return nil, fmt.Errorf("siwe message chain id invalid")
+		}
+	}
+
+	if res.Nonce, _, err = next(siweNonceTag, true); err != nil {
+		return nil, err
+	}
+	if len(res.Nonce) < 8 {
+		return nil, fmt.Errorf("siwe message nonce too short")
+	}
+
+	issuedAt, _, err := next(siweIssuedAtTag, true)
+	if err != nil {
+		return nil, err
+	}
+	res.IssuedAt, err = time.Parse(time.RFC3339, issuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("siwe message issued at invalid")
+	}
+
+	if value, ok, _ := next(siweExpirationTimeTag, false); ok {
+		expirationTime, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("siwe message expiration time invalid")
+		}
+		res.ExpirationTime = &expirationTime
+	}
+
+	if value, ok, _ := next(siweNotBeforeTag, false); ok {
+		notBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("siwe message not before invalid")
+		}
+		res.NotBefore = &notBefore
+	}
+
+	res.RequestID, _, _ = next(siweRequestIDTag, false)
+
+	if i < len(lines) && lines[i] == siweResourcesTag {
+		i++
+		for i < len(lines) && strings.HasPrefix(lines[i], siweResourceTag) {
+			res.Resources = append(res.Resources, strings.TrimPrefix(lines[i], siweResourceTag))
+			i++
+		}
+	}
+
+	if i != len(lines) {
+		return nil, fmt.Errorf("siwe message has unexpected line %q", lines[i])
+	}
+
+	return res, nil
+}
+
+// String formats message the way wallets sign it
+func (m *SiweMessage) String() string {
+	var b strings.Builder
+
+	b.WriteString(m.Domain + siweHeaderInfix + m.Chain + siweHeaderSuffix + "\n")
+	b.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(siweURITag + m.URI + "\n")
+	b.WriteString(siweVersionTag + m.Version + "\n")
+	b.WriteString(siweChainIDTag + m.ChainID + "\n")
+	b.WriteString(siweNonceTag + m.Nonce + "\n")
+	b.WriteString(siweIssuedAtTag + m.IssuedAt.Format(time.RFC3339))
+	if m.ExpirationTime != nil {
+		b.WriteString("\n" + siweExpirationTimeTag + m.ExpirationTime.Format(time.RFC3339))
+	}
+	if m.NotBefore != nil {
+		b.WriteString("\n" + siweNotBeforeTag + m.NotBefore.Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		b.WriteString("\n" + siweRequestIDTag + m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\n" + siweResourcesTag)
+		for _, resource := range m.Resources {
+			b.WriteString("\n" + siweResourceTag + resource)
+		}
+	}
+
+	return b.String()
+}
+
+// Validate checks message is issued for configured domain, uri and chain,
+// signed by wallet and valid at the given time
+func (m *SiweMessage) Validate(cfg SiweConfig, wallet string, now time.Time) error {
+	if m.Chain != cfg.Chain {
+		return fmt.Errorf("siwe message chain %q not allowed", m.Chain)
+	}
+
+	if m.Domain != cfg.Domain {
+		return fmt.Errorf("siwe message domain %q not allowed", m.Domain)
+	}
+
+	uri, err := url.Parse(m.URI)
+	if err != nil {
+		return fmt.Errorf("siwe message uri invalid")
+	}
+	cfgURI, err := url.Parse(cfg.URI)
+	if err != nil {
+		return fmt.Errorf("siwe config uri invalid")
+	}
+	if uri.Scheme != cfgURI.Scheme || uri.Host != cfgURI.Host {
+		return fmt.Errorf("siwe message uri %q not allowed", m.URI)
+	}
+
+	if m.ChainID != cfg.ChainID {
+		return fmt.Errorf("siwe message chain id %q not allowed", m.ChainID)
+	}
+
+	if m.Address != wallet {
+		return fmt.Errorf("siwe message address mismatch")
+	}
+
+	if m.IssuedAt.After(now.Add(siweClockSkew)) {
+		return fmt.Errorf("siwe message issued in future")
+	}
+
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return fmt.Errorf("siwe message expired")
+	}
+
+	if m.NotBefore != nil && now.Add(siweClockSkew).Before(*m.NotBefore) {
+		return fmt.Errorf("siwe message not valid yet")
+	}
+
+	return nil
+}
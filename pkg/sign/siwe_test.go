@@ -0,0 +1,164 @@
+package sign_test
+
+import (
+	"server/pkg/sign"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var siweConfig = sign.SiweConfig{
+	Chain:   sign.ChainEthereum,
+	Domain:  "game.example.com",
+	URI:     "https://game.example.com",
+	ChainID: "1",
+}
+
+const siweWallet = "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"
+
+func TestParseSiweMessage_Success(t *testing.T) {
+	message := "game.example.com wants you to sign in with your Ethereum account:\n" +
+		"0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935\n" +
+		"\n" +
+		"Sign in to play\n" +
+		"\n" +
+		"URI: https://game.example.com/login\n" +
+		"Version: 1\n" +
+		"Chain ID: 1\n" +
+		"Nonce: 32891756abcdef\n" +
+		"Issued At: 2023-09-01T16:25:24Z\n" +
+		"Expiration Time: 2023-09-01T16:30:24Z\n" +
+		"Request ID: req-1\n" +
+		"Resources:\n" +
+		"- https://game.example.com/terms"
+
+	siwe, err := sign.ParseSiweMessage(message)
+	require.NoError(t, err)
+
+	assert.Equal(t, "game.example.com", siwe.Domain)
+	assert.Equal(t, sign.ChainEthereum, siwe.Chain)
+	assert.Equal(t, siweWallet, siwe.Address)
+	assert.Equal(t, "Sign in to play", siwe.Statement)
+	assert.Equal(t, "https://game.example.com/login", siwe.URI)
+	assert.Equal(t, "1", siwe.ChainID)
+	assert.Equal(t, "32891756abcdef", siwe.Nonce)
+	assert.Equal(t, time.Date(2023, 9, 1, 16, 25, 24, 0, time.UTC), siwe.IssuedAt)
+	require.NotNil(t, siwe.ExpirationTime)
+	assert.Nil(t, siwe.NotBefore)
+	assert.Equal(t, "req-1", siwe.RequestID)
+	assert.Equal(t, []string{"https://game.example.com/terms"}, siwe.Resources)
+
+	assert.Equal(t, message, siwe.String())
+}
+
+func TestParseSiweMessage_NoStatement(t *testing.T) {
+	siwe := &sign.SiweMessage{
+		Domain:   "game.example.com",
+		Chain:    sign.ChainEthereum,
+		Address:  siweWallet,
+		URI:      "https://game.example.com",
+		Version:  "1",
+		ChainID:  "1",
+		Nonce:    "32891756abcdef",
+		IssuedAt: time.Date(2023, 9, 1, 16, 25, 24, 0, time.UTC),
+	}
+
+	parsed, err := sign.ParseSiweMessage(siwe.String())
+	require.NoError(t, err)
+	assert.Equal(t, siwe, parsed)
+}
+
+func TestParseSiweMessage_Solana(t *testing.T) {
+	message := "game.example.com wants you to sign in with your Solana account:\n" +
+		"5e6wK3rAqTgYg2TSBbXJTTvpvgjb6ZD3aTuDiuktW1eq\n" +
+		"\n" +
+		"\n" +
+		"URI: https://game.example.com\n" +
+		"Version: 1\n" +
+		"Chain ID: mainnet\n" +
+		"Nonce: 32891756abcdef\n" +
+		"Issued At: 2023-09-01T16:25:24Z"
+
+	siwe, err := sign.ParseSiweMessage(message)
+	require.NoError(t, err)
+
+	assert.Equal(t, sign.ChainSolana, siwe.Chain)
+	assert.Equal(t, "5e6wK3rAqTgYg2TSBbXJTTvpvgjb6ZD3aTuDiuktW1eq", siwe.Address)
+	assert.Equal(t, "mainnet", siwe.ChainID)
+	assert.Equal(t, message, siwe.String())
+}
+
+func TestParseSiweMessage_Fail(t *testing.T) {
+	testCases := []string{
+		"test",
+		" wants you to sign in with your Ethereum account:\n" +
+			siweWallet + "\n\n\nURI: https://game.example.com\nVersion: 1\nChain ID: 1\nNonce: 32891756abcdef\nIssued At: 2023-09-01T16:25:24Z",
+		"game.example.com wants you to sign in with your Ethereum account:\n" +
+			siweWallet + "\n\n\nURI: https://game.example.com\nVersion: 2\nChain ID: 1\nNonce: 32891756abcdef\nIssued At: 2023-09-01T16:25:24Z",
+		"game.example.com wants you to sign in with your Ethereum account:\n" +
+			siweWallet + "\n\n\nURI: https://game.example.com\nVersion: 1\nChain ID: one\nNonce: 32891756abcdef\nIssued At: 2023-09-01T16:25:24Z",
+		"game.example.com wants you to sign in with your Ethereum account:\n" +
+			siweWallet + "\n\n\nURI: https://game.example.com\nVersion: 1\nChain ID: 1\nNonce: short\nIssued At: 2023-09-01T16:25:24Z",
+		"game.example.com wants you to sign in with your Ethereum account:\n" +
+			siweWallet + "\n\n\nURI: https://game.example.com\nVersion: 1\nChain ID: 1\nIssued At: 2023-09-01T16:25:24Z\nExtra",
+		"game.example.com wants you to sign in with your Ethereum account:\n" +
+			siweWallet + "\n\n\nURI: https://game.example.com\nVersion: 1\nChain ID: 1\nNonce: 32891756abcdef\nIssued At: yesterday",
+	}
+
+	for _, testCase := range testCases {
+		_, err := sign.ParseSiweMessage(testCase)
+		assert.Error(t, err)
+	}
+}
+
+func TestSiweMessage_Validate(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	valid := func() *sign.SiweMessage {
+		return &sign.SiweMessage{
+			Domain:   "game.example.com",
+			Chain:    sign.ChainEthereum,
+			Address:  siweWallet,
+			URI:      "https://game.example.com/login",
+			Version:  "1",
+			ChainID:  "1",
+			Nonce:    "32891756abcdef",
+			IssuedAt: now,
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(*sign.SiweMessage)
+		fail   bool
+	}{
+		{name: "valid", modify: func(m *sign.SiweMessage) {}},
+		{name: "valid time window", modify: func(m *sign.SiweMessage) { m.NotBefore = &past; m.ExpirationTime = &future }},
+		{name: "wrong domain", modify: func(m *sign.SiweMessage) { m.Domain = "evil.example.com" }, fail: true},
+		{name: "wrong uri", modify: func(m *sign.SiweMessage) { m.URI = "https://game.example.com.evil.com" }, fail: true},
+		{name: "wrong chain", modify: func(m *sign.SiweMessage) { m.Chain = sign.ChainSolana }, fail: true},
+		{name: "wrong chain id", modify: func(m *sign.SiweMessage) { m.ChainID = "5" }, fail: true},
+		{name: "wrong address", modify: func(m *sign.SiweMessage) { m.Address = "0xeF1c8b8c7f478c0BE246735c06aE80BEA3675D75" }, fail: true},
+		{name: "issued in future", modify: func(m *sign.SiweMessage) { m.IssuedAt = future }, fail: true},
+		{name: "expired", modify: func(m *sign.SiweMessage) { m.ExpirationTime = &past }, fail: true},
+		{name: "not before", modify: func(m *sign.SiweMessage) { m.NotBefore = &future }, fail: true},
+	}
+
+	for _, test := range testCases {
+		t.Logf("testing %s", test.name)
+
+		siwe := valid()
+		test.modify(siwe)
+
+		err := siwe.Validate(siweConfig, siweWallet, now)
+		if test.fail {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
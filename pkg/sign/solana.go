@@ -0,0 +1,56 @@
+package sign
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+)
+
+// solanaChainReferences maps Sign-In with Solana chain ids to CAIP-2 references (genesis hash prefixes)
+var solanaChainReferences = map[string]string{
+	"mainnet": "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+	"devnet":  "EtWTRABZaYq6iMfeYKouRu166VU2xqa1",
+	"testnet": "4uhcVJyU9pJkvQyS88uRDiswHXSCkY3z",
+}
+
+// SolanaChainReference returns CAIP-2 reference of Solana cluster,
+// unknown chain ids are considered references already
+func SolanaChainReference(chainID string) string {
+	if reference, ok := solanaChainReferences[chainID]; ok {
+		return reference
+	}
+	return chainID
+}
+
+// SolanaVerifier checks ed25519 signature of message bytes, wallet and signature are base58 encoded
+type SolanaVerifier struct{}
+
+func NewSolanaVerifier() *SolanaVerifier {
+	return &SolanaVerifier{}
+}
+
+func (v *SolanaVerifier) Verify(ctx context.Context, wallet, message, sign string) error {
+	publicKey, err := base58.Decode(wallet)
+	if err != nil {
+		return fmt.Errorf("solana wallet invalid: %w", err)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("solana wallet length invalid")
+	}
+
+	signature, err := base58.Decode(sign)
+	if err != nil {
+		return fmt.Errorf("solana signature invalid: %w", err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("signature length invalid")
+	}
+
+	if !ed25519.Verify(publicKey, []byte(message), signature) {
+		return fmt.Errorf("signature invalid")
+	}
+
+	return nil
+}
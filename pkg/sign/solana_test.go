@@ -0,0 +1,50 @@
+package sign_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"server/pkg/sign"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolanaVerifier_Verify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	wallet := base58.Encode(publicKey)
+	signature := base58.Encode(ed25519.Sign(privateKey, []byte("test")))
+
+	verifier := sign.NewSolanaVerifier()
+	ctx := context.Background()
+
+	err = verifier.Verify(ctx, wallet, "test", signature)
+	assert.NoError(t, err)
+
+	err = verifier.Verify(ctx, wallet, "test1", signature)
+	assert.Error(t, err)
+
+	err = verifier.Verify(ctx, base58.Encode(otherPublicKey), "test", signature)
+	assert.Error(t, err)
+
+	err = verifier.Verify(ctx, "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935", "test", signature)
+	assert.Error(t, err)
+
+	err = verifier.Verify(ctx, wallet, "test", signature[:20])
+	assert.Error(t, err)
+
+	err = verifier.Verify(ctx, wallet, "test", "0x00")
+	assert.Error(t, err)
+}
+
+func TestSolanaChainReference(t *testing.T) {
+	assert.Equal(t, "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", sign.SolanaChainReference("mainnet"))
+	assert.Equal(t, "EtWTRABZaYq6iMfeYKouRu166VU2xqa1", sign.SolanaChainReference("EtWTRABZaYq6iMfeYKouRu166VU2xqa1"))
+}
@@ -0,0 +1,51 @@
+package sign
+
+import (
+	"context"
+	"fmt"
+)
+
+// CAIP-2 chain namespaces, see https://github.com/ChainAgnostic/CAIPs/blob/main/CAIPs/caip-2.md
+const (
+	NamespaceEIP155 = "eip155"
+	NamespaceSolana = "solana"
+)
+
+// Verifier checks wallet signature of message, implemented for every supported chain
+type Verifier interface {
+	Verify(ctx context.Context, wallet, message, sign string) error
+}
+
+// FormatAccount returns CAIP-10 account id, which is unique across chains
+func FormatAccount(namespace, reference, address string) string {
+	return fmt.Sprintf("%s:%s:%s", namespace, reference, address)
+}
+
+// EthereumVerifier checks ECDSA signature of message hashed according to mode,
+// falls back to EIP-1271 check when contractVerifier is set
+type EthereumVerifier struct {
+	mode             Mode
+	contractVerifier ContractVerifier
+}
+
+func NewEthereumVerifier(mode Mode, contractVerifier ContractVerifier) *EthereumVerifier {
+	return &EthereumVerifier{mode, contractVerifier}
+}
+
+func (v *EthereumVerifier) Verify(ctx context.Context, wallet, message, sign string) error {
+	err := VerifySignatureMode(v.mode, wallet, message, sign)
+	if err == nil || v.contractVerifier == nil {
+		return err
+	}
+
+	hash, hashErr := HashMessage(v.mode, message)
+	if hashErr != nil {
+		return hashErr
+	}
+	contractErr := v.contractVerifier.Verify(ctx, wallet, hash, sign)
+	if contractErr != nil {
+		return fmt.Errorf("%s; contract: %s", err, contractErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package sign_test
+
+import (
+	"context"
+	"errors"
+	"server/pkg/sign"
+	"server/pkg/sign/mocks"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEthereumVerifier_Verify(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	wallet := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+
+	hash, err := sign.HashMessage(sign.ModePersonal, "test")
+	require.NoError(t, err)
+	signature, err := crypto.Sign(hash, privateKey)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	contractWallet := "0x28e582BA14CD679FB08E47dC50b565c715Ce0979"
+
+	verifier := sign.NewEthereumVerifier(sign.ModePersonal, nil)
+
+	err = verifier.Verify(ctx, wallet, "test", hexutil.Encode(signature))
+	assert.NoError(t, err)
+
+	err = verifier.Verify(ctx, contractWallet, "test", hexutil.Encode(signature))
+	assert.Error(t, err)
+
+	t.Log("testing contract wallet")
+
+	contractVerifier := mocks.NewContractVerifier(t)
+	verifier = sign.NewEthereumVerifier(sign.ModePersonal, contractVerifier)
+
+	contractVerifier.On("Verify", ctx, contractWallet, hash, hexutil.Encode(signature)).Return(nil).Once()
+	err = verifier.Verify(ctx, contractWallet, "test", hexutil.Encode(signature))
+	assert.NoError(t, err)
+
+	contractVerifier.On("Verify", ctx, contractWallet, hash, hexutil.Encode(signature)).Return(errors.New("invalid")).Once()
+	err = verifier.Verify(ctx, contractWallet, "test", hexutil.Encode(signature))
+	assert.Error(t, err)
+}
+
+func TestFormatAccount(t *testing.T) {
+	assert.Equal(t,
+		"eip155:1:0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935",
+		sign.FormatAccount(sign.NamespaceEIP155, "1", "0xeF209Bee800Ef5c7d20A67F46E007a970EAf9935"),
+	)
+}